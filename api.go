@@ -0,0 +1,414 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page sizes of the list endpoints
+const (
+	API_DEFAULT_PAGE_SIZE = 50
+	API_MAX_PAGE_SIZE     = 200
+)
+
+// Alert types reported by /api/v1/alerts
+const (
+	API_ALERT_THRESHOLD   = "threshold"
+	API_ALERT_ESCALATION  = "escalation"
+	API_ALERT_UNESTIMATED = "unestimated"
+)
+
+// apiPagination describes the page of a list response
+type apiPagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+// apiList is the envelope of all list responses
+type apiList struct {
+	Data       interface{}   `json:"data"`
+	Pagination apiPagination `json:"pagination"`
+}
+
+// apiEstimate is a task's estimate as parsed from its name
+type apiEstimate struct {
+	Text             string  `json:"text"`
+	OptimisticHours  float64 `json:"optimistic_hours"`
+	PessimisticHours float64 `json:"pessimistic_hours"`
+}
+
+// apiTask is a task with the time tracked on it
+type apiTask struct {
+	ID            int          `json:"id"`
+	ParentID      int          `json:"parent_id"`
+	Name          string       `json:"name"`
+	Project       string       `json:"project,omitempty"`
+	Estimate      *apiEstimate `json:"estimate"`
+	EstimateError string       `json:"estimate_error,omitempty"`
+	PeriodSeconds *int         `json:"period_seconds,omitempty"`
+	TotalSeconds  int          `json:"total_seconds"`
+	UsagePercent  *float64     `json:"usage_percent"`
+}
+
+// apiTaskHistoryEntry is a recorded change of a task
+type apiTaskHistoryEntry struct {
+	ChangeType    string    `json:"change_type"`
+	PreviousValue *string   `json:"previous_value"`
+	CurrentValue  *string   `json:"current_value"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+// apiTaskDetail is a task with its change history
+type apiTaskDetail struct {
+	apiTask
+	Archived bool                  `json:"archived"`
+	History  []apiTaskHistoryEntry `json:"history"`
+}
+
+// apiAlert is a threshold, escalation or missing estimate alert that was sent for a task.
+// Threshold is the percentage crossed, for missing estimates the hours step; Value is the
+// usage percentage or, for missing estimates, the hours tracked.
+type apiAlert struct {
+	Type       string    `json:"type"`
+	TaskID     int       `json:"task_id"`
+	TaskName   string    `json:"task_name"`
+	Threshold  int       `json:"threshold"`
+	Value      float64   `json:"value"`
+	NotifiedAt time.Time `json:"notified_at"`
+}
+
+// registerAPIRoutes adds the REST API, protected by API tokens, to a mux.
+// The OpenAPI document is public so clients can be generated without a token.
+func registerAPIRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/openapi.json", handleAPIOpenAPI)
+	mux.Handle("GET /api/v1/projects", apiTokenMiddleware(http.HandlerFunc(handleAPIProjects)))
+	mux.Handle("GET /api/v1/projects/{id}/tasks", apiTokenMiddleware(http.HandlerFunc(handleAPIProjectTasks)))
+	mux.Handle("GET /api/v1/tasks/{id}", apiTokenMiddleware(http.HandlerFunc(handleAPITask)))
+	mux.Handle("GET /api/v1/alerts", apiTokenMiddleware(http.HandlerFunc(handleAPIAlerts)))
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		GetGlobalLogger().Warnf("Failed to write API response: %v", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIJSON(w, status, map[string]string{"error": message})
+}
+
+// parseAPIPagination reads the limit and offset query parameters
+func parseAPIPagination(r *http.Request) (apiPagination, error) {
+	page := apiPagination{Limit: API_DEFAULT_PAGE_SIZE}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > API_MAX_PAGE_SIZE {
+			return page, fmt.Errorf("limit must be between 1 and %d", API_MAX_PAGE_SIZE)
+		}
+		page.Limit = limit
+	}
+	if value := r.URL.Query().Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return page, fmt.Errorf("offset must be a non-negative number")
+		}
+		page.Offset = offset
+	}
+	return page, nil
+}
+
+// pageBounds returns the slice bounds of a page over total items
+func pageBounds(page apiPagination, total int) (int, int) {
+	start := min(page.Offset, total)
+	return start, min(start+page.Limit, total)
+}
+
+// parseAPIPeriod reads the from and to query parameters (YYYY-MM-DD), defaulting to the current month up to today
+func parseAPIPeriod(r *http.Request) (time.Time, time.Time, error) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var err error
+	if value := r.URL.Query().Get("from"); value != "" {
+		if from, err = time.ParseInLocation("2006-01-02", value, now.Location()); err != nil {
+			return from, to, fmt.Errorf("from must be a date formatted as YYYY-MM-DD")
+		}
+	}
+	if value := r.URL.Query().Get("to"); value != "" {
+		if to, err = time.ParseInLocation("2006-01-02", value, now.Location()); err != nil {
+			return from, to, fmt.Errorf("to must be a date formatted as YYYY-MM-DD")
+		}
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("to must not be before from")
+	}
+	return from, to, nil
+}
+
+// pathID reads a numeric path parameter
+func pathID(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("%s must be a positive number", name)
+	}
+	return id, nil
+}
+
+// newAPITask converts a task with its tracked time into its API representation
+func newAPITask(id, parentID int, name, project string, totalSeconds int) apiTask {
+	task := apiTask{ID: id, ParentID: parentID, Name: name, Project: project, TotalSeconds: totalSeconds}
+	estimation := ParseTaskEstimation(name)
+	if estimation.ErrorMessage != "" {
+		task.EstimateError = estimation.ErrorMessage
+		return task
+	}
+	task.Estimate = &apiEstimate{
+		Text:             estimation.Text,
+		OptimisticHours:  estimation.Optimistic,
+		PessimisticHours: estimation.Pessimistic,
+	}
+	if usage, err := CalcUsagePercent(formatDuration(totalSeconds), "0h 0m", estimation); err == nil {
+		task.UsagePercent = &usage
+	}
+	return task
+}
+
+// handleAPIProjects lists the projects
+func handleAPIProjects(w http.ResponseWriter, r *http.Request) {
+	page, err := parseAPIPagination(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, "database unavailable")
+		return
+	}
+	projects, err := GetAllProjects(db)
+	if err != nil {
+		GetGlobalLogger().Errorf("API failed to list projects: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to list projects")
+		return
+	}
+
+	page.Total = len(projects)
+	start, end := pageBounds(page, page.Total)
+	data := projects[start:end]
+	if data == nil {
+		data = []Project{}
+	}
+	writeAPIJSON(w, http.StatusOK, apiList{Data: data, Pagination: page})
+}
+
+// handleAPIProjectTasks lists a project's tasks with time tracked in a period
+func handleAPIProjectTasks(w http.ResponseWriter, r *http.Request) {
+	projectID, err := pathID(r, "id")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := parseAPIPagination(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := parseAPIPeriod(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, "database unavailable")
+		return
+	}
+	project, err := GetProjectByID(db, projectID)
+	if err != nil {
+		GetGlobalLogger().Errorf("API failed to load project: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to load project")
+		return
+	}
+	if project == nil {
+		writeAPIError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	tasks := getFilteredTasksWithTimeout(from, to, []string{project.Name}, "")
+	page.Total = len(tasks)
+	start, end := pageBounds(page, page.Total)
+	data := make([]apiTask, 0, end-start)
+	for _, task := range tasks[start:end] {
+		apiTask := newAPITask(task.TaskID, task.ParentID, task.Name, project.Name, task.TotalSeconds)
+		periodSeconds := task.CurrentSeconds
+		apiTask.PeriodSeconds = &periodSeconds
+		data = append(data, apiTask)
+	}
+	writeAPIJSON(w, http.StatusOK, apiList{Data: data, Pagination: page})
+}
+
+// handleAPITask returns a task with its estimate, usage and change history
+func handleAPITask(w http.ResponseWriter, r *http.Request) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, "database unavailable")
+		return
+	}
+	task, err := getAPITaskDetail(db, taskID)
+	if err != nil {
+		GetGlobalLogger().Errorf("API failed to load task %d: %v", taskID, err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to load task")
+		return
+	}
+	if task == nil {
+		writeAPIError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, task)
+}
+
+// getAPITaskDetail loads a task, nil if there is none with that ID
+func getAPITaskDetail(db *sql.DB, taskID int) (*apiTaskDetail, error) {
+	var parentID, archived, totalSeconds int
+	var name string
+	err := db.QueryRow(`
+		SELECT t.parent_id, t.name, COALESCE(t.archived, 0), COALESCE(SUM(te.duration), 0)
+		FROM tasks t
+		LEFT JOIN time_entries te ON te.task_id = t.task_id
+		WHERE t.task_id = $1
+		GROUP BY t.task_id, t.parent_id, t.name, t.archived`, taskID).Scan(&parentID, &name, &archived, &totalSeconds)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task: %w", err)
+	}
+
+	allTasks, err := getAllTasks(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load task hierarchy: %w", err)
+	}
+	detail := &apiTaskDetail{
+		apiTask:  newAPITask(taskID, parentID, name, getProjectNameForTask(taskID, allTasks), totalSeconds),
+		Archived: archived != 0,
+		History:  []apiTaskHistoryEntry{},
+	}
+
+	rows, err := db.Query(`
+		SELECT change_type, previous_value, current_value, timestamp
+		FROM task_history
+		WHERE task_id = $1
+		ORDER BY timestamp DESC, id DESC`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task history: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry apiTaskHistoryEntry
+		if err := rows.Scan(&entry.ChangeType, &entry.PreviousValue, &entry.CurrentValue, &entry.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan task history: %w", err)
+		}
+		detail.History = append(detail.History, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task history: %w", err)
+	}
+	return detail, nil
+}
+
+// apiAlertsQuery unions the three alert tables into one list
+const apiAlertsQuery = `
+	SELECT 'threshold' AS type, n.task_id, t.name, n.threshold_percentage AS threshold, n.current_percentage AS value, n.notified_at
+	FROM threshold_notifications n JOIN tasks t ON t.task_id = n.task_id
+	UNION ALL
+	SELECT 'escalation', n.task_id, t.name, n.threshold_percentage, n.current_percentage, n.notified_at
+	FROM escalation_notifications n JOIN tasks t ON t.task_id = n.task_id
+	UNION ALL
+	SELECT 'unestimated', n.task_id, t.name, n.hours_step, n.total_hours, n.notified_at
+	FROM unestimated_task_alerts n JOIN tasks t ON t.task_id = n.task_id`
+
+// handleAPIAlerts lists sent alerts, newest first, optionally filtered by type and a since date
+func handleAPIAlerts(w http.ResponseWriter, r *http.Request) {
+	page, err := parseAPIPagination(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	alertType := strings.ToLower(r.URL.Query().Get("type"))
+	switch alertType {
+	case "", API_ALERT_THRESHOLD, API_ALERT_ESCALATION, API_ALERT_UNESTIMATED:
+	default:
+		writeAPIError(w, http.StatusBadRequest, "type must be threshold, escalation or unestimated")
+		return
+	}
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		if since, err = time.ParseInLocation("2006-01-02", value, time.Local); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "since must be a date formatted as YYYY-MM-DD")
+			return
+		}
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, "database unavailable")
+		return
+	}
+	alerts, total, err := getAPIAlerts(db, alertType, since, page)
+	if err != nil {
+		GetGlobalLogger().Errorf("API failed to list alerts: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to list alerts")
+		return
+	}
+	page.Total = total
+	writeAPIJSON(w, http.StatusOK, apiList{Data: alerts, Pagination: page})
+}
+
+// getAPIAlerts returns a page of alerts and the number of alerts matching the filters
+func getAPIAlerts(db *sql.DB, alertType string, since time.Time, page apiPagination) ([]apiAlert, int, error) {
+	filter := `WHERE ($1 = '' OR type = $1) AND notified_at >= $2`
+	sinceArg := since.Format("2006-01-02 15:04:05")
+
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM (`+apiAlertsQuery+`) alerts `+filter, alertType, sinceArg).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count alerts: %w", err)
+	}
+
+	rows, err := db.Query(`SELECT type, task_id, name, threshold, value, notified_at FROM (`+apiAlertsQuery+`) alerts `+
+		filter+` ORDER BY notified_at DESC, task_id LIMIT $3 OFFSET $4`, alertType, sinceArg, page.Limit, page.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []apiAlert{}
+	for rows.Next() {
+		var alert apiAlert
+		if err := rows.Scan(&alert.Type, &alert.TaskID, &alert.TaskName, &alert.Threshold, &alert.Value, &alert.NotifiedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan alert: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating alerts: %w", err)
+	}
+	return alerts, total, nil
+}
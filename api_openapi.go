@@ -0,0 +1,231 @@
+package main
+
+import "net/http"
+
+// handleAPIOpenAPI serves the OpenAPI document describing the REST API
+func handleAPIOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(apiOpenAPIDocument))
+}
+
+// apiOpenAPIDocument describes /api/v1, keep it in step with api.go
+const apiOpenAPIDocument = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Observe Your Estimates API",
+    "version": "1.0.0",
+    "description": "Read-only access to projects, tasks, estimate usage and sent alerts."
+  },
+  "servers": [{"url": "/api/v1"}],
+  "security": [{"bearerAuth": []}],
+  "paths": {
+    "/projects": {
+      "get": {
+        "summary": "List projects",
+        "operationId": "listProjects",
+        "parameters": [
+          {"$ref": "#/components/parameters/limit"},
+          {"$ref": "#/components/parameters/offset"}
+        ],
+        "responses": {
+          "200": {
+            "description": "A page of projects",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "required": ["data", "pagination"],
+              "properties": {
+                "data": {"type": "array", "items": {"$ref": "#/components/schemas/Project"}},
+                "pagination": {"$ref": "#/components/schemas/Pagination"}
+              }
+            }}}
+          },
+          "400": {"$ref": "#/components/responses/BadRequest"},
+          "401": {"$ref": "#/components/responses/Unauthorized"}
+        }
+      }
+    },
+    "/projects/{id}/tasks": {
+      "get": {
+        "summary": "List a project's tasks with time tracked in a period",
+        "operationId": "listProjectTasks",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}},
+          {"name": "from", "in": "query", "description": "First day of the period, defaults to the start of the current month", "schema": {"type": "string", "format": "date"}},
+          {"name": "to", "in": "query", "description": "Last day of the period, defaults to today", "schema": {"type": "string", "format": "date"}},
+          {"$ref": "#/components/parameters/limit"},
+          {"$ref": "#/components/parameters/offset"}
+        ],
+        "responses": {
+          "200": {
+            "description": "A page of tasks",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "required": ["data", "pagination"],
+              "properties": {
+                "data": {"type": "array", "items": {"$ref": "#/components/schemas/Task"}},
+                "pagination": {"$ref": "#/components/schemas/Pagination"}
+              }
+            }}}
+          },
+          "400": {"$ref": "#/components/responses/BadRequest"},
+          "401": {"$ref": "#/components/responses/Unauthorized"},
+          "404": {"$ref": "#/components/responses/NotFound"}
+        }
+      }
+    },
+    "/tasks/{id}": {
+      "get": {
+        "summary": "Get a task with its estimate, usage and change history",
+        "operationId": "getTask",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "description": "TimeCamp task ID", "schema": {"type": "integer"}}
+        ],
+        "responses": {
+          "200": {
+            "description": "The task",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/TaskDetail"}}}
+          },
+          "400": {"$ref": "#/components/responses/BadRequest"},
+          "401": {"$ref": "#/components/responses/Unauthorized"},
+          "404": {"$ref": "#/components/responses/NotFound"}
+        }
+      }
+    },
+    "/alerts": {
+      "get": {
+        "summary": "List sent alerts, newest first",
+        "operationId": "listAlerts",
+        "parameters": [
+          {"name": "type", "in": "query", "schema": {"type": "string", "enum": ["threshold", "escalation", "unestimated"]}},
+          {"name": "since", "in": "query", "description": "Only alerts sent on or after this day", "schema": {"type": "string", "format": "date"}},
+          {"$ref": "#/components/parameters/limit"},
+          {"$ref": "#/components/parameters/offset"}
+        ],
+        "responses": {
+          "200": {
+            "description": "A page of alerts",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "required": ["data", "pagination"],
+              "properties": {
+                "data": {"type": "array", "items": {"$ref": "#/components/schemas/Alert"}},
+                "pagination": {"$ref": "#/components/schemas/Pagination"}
+              }
+            }}}
+          },
+          "400": {"$ref": "#/components/responses/BadRequest"},
+          "401": {"$ref": "#/components/responses/Unauthorized"}
+        }
+      }
+    },
+    "/openapi.json": {
+      "get": {
+        "summary": "This document",
+        "operationId": "getOpenAPI",
+        "security": [],
+        "responses": {"200": {"description": "The OpenAPI document", "content": {"application/json": {}}}}
+      }
+    }
+  },
+  "components": {
+    "securitySchemes": {
+      "bearerAuth": {"type": "http", "scheme": "bearer", "description": "Token created with the api-tokens add command"}
+    },
+    "parameters": {
+      "limit": {"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 200, "default": 50}},
+      "offset": {"name": "offset", "in": "query", "schema": {"type": "integer", "minimum": 0, "default": 0}}
+    },
+    "responses": {
+      "BadRequest": {"description": "Invalid parameters", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+      "Unauthorized": {"description": "Missing, invalid or revoked token", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+      "NotFound": {"description": "No such resource", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+    },
+    "schemas": {
+      "Error": {
+        "type": "object",
+        "required": ["error"],
+        "properties": {"error": {"type": "string"}}
+      },
+      "Pagination": {
+        "type": "object",
+        "required": ["limit", "offset", "total"],
+        "properties": {
+          "limit": {"type": "integer"},
+          "offset": {"type": "integer"},
+          "total": {"type": "integer", "description": "Number of items across all pages"}
+        }
+      },
+      "Project": {
+        "type": "object",
+        "required": ["id", "name", "timecamp_task_id", "created_at", "updated_at"],
+        "properties": {
+          "id": {"type": "integer"},
+          "name": {"type": "string"},
+          "timecamp_task_id": {"type": "integer"},
+          "created_at": {"type": "string", "format": "date-time"},
+          "updated_at": {"type": "string", "format": "date-time"}
+        }
+      },
+      "Estimate": {
+        "type": "object",
+        "required": ["text", "optimistic_hours", "pessimistic_hours"],
+        "properties": {
+          "text": {"type": "string"},
+          "optimistic_hours": {"type": "number"},
+          "pessimistic_hours": {"type": "number"}
+        }
+      },
+      "Task": {
+        "type": "object",
+        "required": ["id", "parent_id", "name", "estimate", "total_seconds", "usage_percent"],
+        "properties": {
+          "id": {"type": "integer", "description": "TimeCamp task ID"},
+          "parent_id": {"type": "integer"},
+          "name": {"type": "string"},
+          "project": {"type": "string"},
+          "estimate": {"allOf": [{"$ref": "#/components/schemas/Estimate"}], "nullable": true},
+          "estimate_error": {"type": "string", "description": "Why the task's name holds no valid estimate"},
+          "period_seconds": {"type": "integer", "description": "Time tracked in the requested period"},
+          "total_seconds": {"type": "integer", "description": "Time tracked overall"},
+          "usage_percent": {"type": "number", "nullable": true, "description": "Total time against the pessimistic estimate"}
+        }
+      },
+      "TaskHistoryEntry": {
+        "type": "object",
+        "required": ["change_type", "previous_value", "current_value", "changed_at"],
+        "properties": {
+          "change_type": {"type": "string"},
+          "previous_value": {"type": "string", "nullable": true},
+          "current_value": {"type": "string", "nullable": true},
+          "changed_at": {"type": "string", "format": "date-time"}
+        }
+      },
+      "TaskDetail": {
+        "allOf": [
+          {"$ref": "#/components/schemas/Task"},
+          {
+            "type": "object",
+            "required": ["archived", "history"],
+            "properties": {
+              "archived": {"type": "boolean"},
+              "history": {"type": "array", "items": {"$ref": "#/components/schemas/TaskHistoryEntry"}}
+            }
+          }
+        ]
+      },
+      "Alert": {
+        "type": "object",
+        "required": ["type", "task_id", "task_name", "threshold", "value", "notified_at"],
+        "properties": {
+          "type": {"type": "string", "enum": ["threshold", "escalation", "unestimated"]},
+          "task_id": {"type": "integer"},
+          "task_name": {"type": "string"},
+          "threshold": {"type": "integer", "description": "Usage percentage crossed, or the hours step for unestimated tasks"},
+          "value": {"type": "number", "description": "Usage percentage, or the hours tracked for unestimated tasks"},
+          "notified_at": {"type": "string", "format": "date-time"}
+        }
+      }
+    }
+  }
+}
+`
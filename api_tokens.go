@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIToken is a credential for the REST API. Only a hash of the token is stored,
+// the token itself is shown once when it is created.
+type APIToken struct {
+	ID         int
+	Name       string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// hashAPIToken returns the hex encoded SHA-256 of a token as stored in api_tokens
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIToken stores a new token under a name and returns it together with the plain token
+func CreateAPIToken(db *sql.DB, name string) (*APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("API token name is required")
+	}
+
+	plain := "oye_" + newWebhookID(32)
+	token := APIToken{Name: name}
+	err := db.QueryRow(`INSERT INTO api_tokens (name, token_hash) VALUES ($1, $2) RETURNING id, created_at`,
+		name, hashAPIToken(plain)).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create API token: %w", err)
+	}
+	return &token, plain, nil
+}
+
+// GetAPITokens returns all tokens, revoked ones included
+func GetAPITokens(db *sql.DB) ([]APIToken, error) {
+	rows, err := db.Query(`SELECT id, name, created_at, last_used_at, revoked_at FROM api_tokens ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []APIToken
+	for rows.Next() {
+		var token APIToken
+		if err := rows.Scan(&token.ID, &token.Name, &token.CreatedAt, &token.LastUsedAt, &token.RevokedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokeAPIToken stops a token from being accepted
+func RevokeAPIToken(db *sql.DB, id int) error {
+	result, err := db.Exec(`UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token %d: %w", id, err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("no active API token with id %d", id)
+	}
+	return nil
+}
+
+// authenticateAPIToken returns the active token matching a plain token, nil if there is none
+func authenticateAPIToken(db *sql.DB, plain string) (*APIToken, error) {
+	var token APIToken
+	err := db.QueryRow(`
+		UPDATE api_tokens SET last_used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1 AND revoked_at IS NULL
+		RETURNING id, name, created_at`,
+		hashAPIToken(plain)).Scan(&token.ID, &token.Name, &token.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API token: %w", err)
+	}
+	return &token, nil
+}
+
+// apiTokenMiddleware only lets requests through that carry an active token
+// as "Authorization: Bearer <token>"
+func apiTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plain, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(plain) == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="oye"`)
+			writeAPIError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		db, err := GetDB()
+		if err != nil {
+			GetGlobalLogger().Errorf("Failed to get database connection for API authentication: %v", err)
+			writeAPIError(w, http.StatusServiceUnavailable, "database unavailable")
+			return
+		}
+
+		token, err := authenticateAPIToken(db, strings.TrimSpace(plain))
+		if err != nil {
+			GetGlobalLogger().Errorf("API authentication failed: %v", err)
+			writeAPIError(w, http.StatusInternalServerError, "authentication failed")
+			return
+		}
+		if token == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="oye", error="invalid_token"`)
+			writeAPIError(w, http.StatusUnauthorized, "invalid or revoked token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleAPITokensCliCommand manages API tokens from the command line
+func handleAPITokensCliCommand(args []string, logger *Logger) error {
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+
+	subcommand := "list"
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+
+	switch subcommand {
+	case "list":
+		tokens, err := GetAPITokens(db)
+		if err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			fmt.Println("No API tokens")
+			return nil
+		}
+		for _, token := range tokens {
+			lastUsed, status := "never", "active"
+			if token.LastUsedAt != nil {
+				lastUsed = token.LastUsedAt.Format("2006-01-02 15:04")
+			}
+			if token.RevokedAt != nil {
+				status = "revoked"
+			}
+			fmt.Printf("%d\t%s\tcreated %s\tlast used %s\t%s\n", token.ID, token.Name, token.CreatedAt.Format("2006-01-02"), lastUsed, status)
+		}
+	case "add":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: api-tokens add <name>")
+		}
+		token, plain, err := CreateAPIToken(db, strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created API token %d for %s\nToken (shown only once): %s\n", token.ID, token.Name, plain)
+	case "revoke":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: api-tokens revoke <id>")
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid token id: %s", args[1])
+		}
+		if err := RevokeAPIToken(db, id); err != nil {
+			return err
+		}
+		logger.Infof("Revoked API token %d", id)
+	default:
+		return fmt.Errorf("unknown api-tokens subcommand: %s", subcommand)
+	}
+	return nil
+}
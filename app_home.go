@@ -2,40 +2,13 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 )
 
-// AppHomeView represents the App Home tab view
-type AppHomeView struct {
-	Type   string  `json:"type"`
-	Blocks []Block `json:"blocks"`
-}
-
-// Extended structures for App Home (separate from existing simple ones)
-type AppHomeBlock struct {
-	Type      string            `json:"type"`
-	Text      *Text             `json:"text,omitempty"`
-	Elements  []AppHomeElement  `json:"elements,omitempty"`
-	Accessory *AppHomeAccessory `json:"accessory,omitempty"`
-}
-
-type AppHomeElement struct {
-	Type     string            `json:"type"`
-	Text     map[string]string `json:"text,omitempty"`
-	ActionID string            `json:"action_id,omitempty"`
-	Style    string            `json:"style,omitempty"`
-}
-
-type AppHomeAccessory struct {
-	Type           string                   `json:"type"`
-	ActionID       string                   `json:"action_id,omitempty"`
-	Options        []map[string]interface{} `json:"options,omitempty"`
-	InitialOptions []map[string]interface{} `json:"initial_options,omitempty"`
-}
-
 // HandleAppHome handles app home opened events
 func HandleAppHome(w http.ResponseWriter, r *http.Request) {
 	logger := GetGlobalLogger()
@@ -56,155 +29,148 @@ func HandleAppHome(w http.ResponseWriter, r *http.Request) {
 
 	// Handle app home opened
 	if event.Type == "event_callback" && event.Event.Type == "app_home_opened" {
-		if err := PublishAppHomeView(event.Event.User); err != nil {
-			logger.Errorf("Failed to publish app home view: %v", err)
+		// Opening the app shows the dashboard first
+		if err := PublishAppHomeDashboard(event.Event.User, 0); err != nil {
+			logger.Errorf("Failed to publish app home dashboard: %v", err)
+		}
+	}
+
+	// Mentions and direct messages are answered in the background, Slack wants its ack within 3 seconds
+	if event.Type == "event_callback" && isBotQuestion(event.Event) {
+		if isNewSlackEvent(event.EventID) {
+			go HandleBotQuestion(event.Event)
+		} else {
+			logger.Infof("Skipping retried Slack event %s", event.EventID)
 		}
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
-// PublishAppHomeView publishes the app home view for a user
-func PublishAppHomeView(userID string) error {
-	logger := GetGlobalLogger()
-	slackClient := NewSlackAPIClient()
+// isNewSlackEvent reports whether an event has not been handled before.
+// If the check fails the event is treated as new, answering twice beats not answering.
+func isNewSlackEvent(eventID string) bool {
+	if eventID == "" {
+		return true
+	}
+	db, err := GetDB()
+	if err != nil {
+		GetGlobalLogger().Errorf("Failed to get database connection for event dedupe: %v", err)
+		return true
+	}
+	isNew, err := markSlackEventProcessed(db, eventID)
+	if err != nil {
+		GetGlobalLogger().Errorf("Failed to deduplicate Slack event: %v", err)
+		return true
+	}
+	return isNew
+}
+
+// AppHomeData holds the per-user state the App Home view is built from
+type AppHomeData struct {
+	UserProjects       []Project
+	AllProjects        []Project
+	ProjectOwners      map[int]string
+	ProjectGroups      []ProjectGroup
+	EmailDigestEnabled bool
+	DailyUpdate        DailyUpdatePreferences
+}
 
-	// Get user's current project assignments
+// loadAppHomeData fetches everything needed to render the App Home for a user
+func loadAppHomeData(userID string) (*AppHomeData, error) {
 	db, err := GetDB()
 	if err != nil {
-		return fmt.Errorf("failed to get database connection: %w", err)
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
 	}
 
 	userProjects, err := GetUserProjects(db, userID)
 	if err != nil {
-		return fmt.Errorf("failed to get user projects: %w", err)
+		return nil, fmt.Errorf("failed to get user projects: %w", err)
 	}
 
 	allProjects, err := GetAllProjects(db)
 	if err != nil {
-		return fmt.Errorf("failed to get all projects: %w", err)
+		return nil, fmt.Errorf("failed to get all projects: %w", err)
 	}
 
-	view := BuildSimpleAppHomeView(userProjects, allProjects, userID, 0)
-
-	payload := map[string]interface{}{
-		"user_id": userID,
-		"view":    view,
+	projectOwners, err := GetProjectOwners(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project owners: %w", err)
 	}
 
-	// Validate payload size before sending
-	payloadJSON, err := json.Marshal(payload)
+	projectGroups, err := GetProjectGroups(db)
 	if err != nil {
-		return fmt.Errorf("failed to marshal app home payload: %w", err)
+		return nil, err
 	}
 
-	logger.Infof("App Home payload size: %d characters", len(payloadJSON))
-
-	// Debug: log the first part of the payload to see structure
-	payloadStr := string(payloadJSON)
-	if len(payloadStr) > 1000 {
-		logger.Infof("App Home payload preview: %s...", payloadStr[:1000])
-	} else {
-		logger.Infof("App Home payload: %s", payloadStr)
+	emailDigestEnabled, err := IsUserSubscribedToEmailDigest(db, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	const maxAppHomeSize = 3000 // Slack's character limit for App Home
-	if len(payloadJSON) > maxAppHomeSize {
-		logger.Errorf("App Home payload too large: %d > %d characters", len(payloadJSON), maxAppHomeSize)
-		// Return a simplified view instead of failing
-		simpleView := BuildFallbackAppHomeView(len(userProjects), len(allProjects))
-		payload["view"] = simpleView
+	dailyUpdate, err := GetDailyUpdatePreferences(db, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	return slackClient.sendSlackAPIRequest("views.publish", payload)
+	return &AppHomeData{
+		UserProjects:       userProjects,
+		AllProjects:        allProjects,
+		ProjectOwners:      projectOwners,
+		ProjectGroups:      projectGroups,
+		EmailDigestEnabled: emailDigestEnabled,
+		DailyUpdate:        dailyUpdate,
+	}, nil
 }
 
-// PublishAppHomeViewWithPage publishes the app home view for a user with a specific page
-func PublishAppHomeViewWithPage(userID string, page int) error {
-	return PublishAppHomeViewWithSearch(userID, page, "")
+// PublishAppHomeView publishes the settings tab of the app home view for a user
+func PublishAppHomeView(userID string) error {
+	return PublishAppHomeViewWithPage(userID, 0)
 }
 
-// PublishAppHomeViewWithSearch publishes the app home view for a user with a specific page and search query
-func PublishAppHomeViewWithSearch(userID string, page int, searchQuery string) error {
+// PublishAppHomeViewWithPage publishes the settings tab showing the given page of project owners
+func PublishAppHomeViewWithPage(userID string, page int) error {
 	logger := GetGlobalLogger()
 	slackClient := NewSlackAPIClient()
+	cancelDashboardLoad(userID)
 
-	// Get user's current project assignments
-	db, err := GetDB()
-	if err != nil {
-		return fmt.Errorf("failed to get database connection: %w", err)
-	}
-
-	userProjects, err := GetUserProjects(db, userID)
-	if err != nil {
-		return fmt.Errorf("failed to get user projects: %w", err)
-	}
-
-	allProjects, err := GetAllProjects(db)
-	if err != nil {
-		return fmt.Errorf("failed to get all projects: %w", err)
-	}
-
-	view := BuildSimpleAppHomeViewWithSearch(userProjects, allProjects, userID, page, searchQuery)
-
-	payload := map[string]interface{}{
-		"user_id": userID,
-		"view":    view,
-	}
-
-	// Validate payload size before sending
-	payloadJSON, err := json.Marshal(payload)
+	data, err := loadAppHomeData(userID)
 	if err != nil {
-		return fmt.Errorf("failed to marshal app home payload: %w", err)
+		return err
 	}
 
-	const maxAppHomeSize = 3000 // Slack's character limit for App Home
-	if len(payloadJSON) > maxAppHomeSize {
-		logger.Errorf("App Home payload too large: %d > %d characters", len(payloadJSON), maxAppHomeSize)
-		// Return a simplified view instead of failing
-		simpleView := BuildFallbackAppHomeView(len(userProjects), len(allProjects))
-		payload["view"] = simpleView
+	view := BuildSimpleAppHomeView(*data, userID, page)
+	if err := view.Validate(); err != nil {
+		// The builder sizes its sections to fit, so this is a bug rather than a big workspace
+		logger.Errorf("App Home view for user %s exceeds Slack limits: %v", userID, err)
+		view = BuildFallbackAppHomeView(len(data.UserProjects), len(data.AllProjects))
 	}
 
-	return slackClient.sendSlackAPIRequest("views.publish", payload)
+	return slackClient.sendSlackAPIRequest("views.publish", ViewRequest{UserID: userID, View: view})
 }
 
-// BuildSimpleAppHomeView builds a simplified App Home view without complex interactive components
-func BuildSimpleAppHomeView(userProjects []Project, allProjects []Project, userID string, page int) AppHomeView {
-	return BuildSimpleAppHomeViewWithSearch(userProjects, allProjects, userID, page, "")
-}
+// appHomeSettingsFixedBlocks counts the settings blocks that don't depend on the data:
+// header, tabs, assignments (2), owners title, owners paging (2), daily update, digest,
+// divider, groups title, divider, assignment title and select, footer
+const appHomeSettingsFixedBlocks = 15
 
-// BuildSimpleAppHomeViewWithSearch builds App Home view with search functionality
-func BuildSimpleAppHomeViewWithSearch(userProjects []Project, allProjects []Project, userID string, page int, searchQuery string) AppHomeView {
+// BuildSimpleAppHomeView builds the settings tab. Owners are paged and groups collapse
+// into a menu when they don't fit, so the view stays within MAX_HOME_VIEW_BLOCKS.
+func BuildSimpleAppHomeView(data AppHomeData, userID string, page int) View {
+	userProjects, allProjects, projectOwners := data.UserProjects, data.AllProjects, data.ProjectOwners
 	var blocks []Block
+	budget := MAX_HOME_VIEW_BLOCKS - appHomeSettingsFixedBlocks
 
 	// Header
-	blocks = append(blocks, Block{
-		Type: "header",
-		Text: &Text{
-			Type: "plain_text",
-			Text: "🏠 OYE Time Tracker Settings",
-		},
-	})
+	blocks = append(blocks, HeaderBlock("🏠 OYE Time Tracker Settings"))
+	blocks = append(blocks, buildAppHomeTabs(APP_HOME_TAB_SETTINGS))
 
 	// Current assignments section
-	blocks = append(blocks, Block{
-		Type: "section",
-		Text: &Text{
-			Type: "mrkdwn",
-			Text: "*📋 Your Current Project Assignments*",
-		},
-	})
+	blocks = append(blocks, SectionBlock("*📋 Your Current Project Assignments*"))
 
 	// Show current assignments
 	if len(userProjects) == 0 {
-		blocks = append(blocks, Block{
-			Type: "section",
-			Text: &Text{
-				Type: "mrkdwn",
-				Text: "• _No projects assigned yet_\n• Select projects below to assign yourself",
-			},
-		})
+		blocks = append(blocks, SectionBlock("• _No projects assigned yet_\n• Pick projects below to assign yourself"))
 	} else {
 		assignmentText := fmt.Sprintf("*%d projects assigned:*\n", len(userProjects))
 		const maxToShow = 8
@@ -214,247 +180,183 @@ func BuildSimpleAppHomeViewWithSearch(userProjects []Project, allProjects []Proj
 				assignmentText += fmt.Sprintf("• _...and %d more_", remaining)
 				break
 			}
-			assignmentText += fmt.Sprintf("• %s\n", project.Name)
-		}
-
-		blocks = append(blocks, Block{
-			Type: "section",
-			Text: &Text{
-				Type: "mrkdwn",
-				Text: assignmentText,
-			},
-		})
-	}
-
-	// Interactive project assignment section with checkboxes
-	blocks = append(blocks, Block{Type: "divider"})
-	blocks = append(blocks, Block{
-		Type: "section",
-		Text: &Text{
-			Type: "mrkdwn",
-			Text: "*🔧 Project Assignment*\nUse checkboxes to select which projects you want to be assigned to:",
-		},
-	})
-
-	// Show current search state if there's an active search
-	if searchQuery != "" {
-		blocks = append(blocks, Block{
-			Type: "section",
-			Text: &Text{
-				Type: "mrkdwn",
-				Text: fmt.Sprintf("*🔍 Search Results for: \"%s\"*", searchQuery),
-			},
-		})
-	}
-
-	// Search functionality now handled via modal
-
-	// Simple button-based search that opens a modal
-	var buttonElements []interface{}
-	buttonElements = append(buttonElements, ButtonElement{
-		Type:     "button",
-		Text:     &Text{Type: "plain_text", Text: "🔍 Open Search"},
-		ActionID: "open_search_modal",
-		Value:    "open_modal",
-		Style:    "primary",
-	})
-
-	if searchQuery != "" {
-		buttonElements = append(buttonElements, ButtonElement{
-			Type:     "button",
-			Text:     &Text{Type: "plain_text", Text: "❌ Clear"},
-			ActionID: "clear_search",
-			Value:    "clear",
-		})
-	}
-
-	blocks = append(blocks, Block{
-		Type:     "actions",
-		Elements: buttonElements,
-	})
-
-	// Add clear search button if there's an active search (moved this logic above)
-	// Apply search filter to projects
-	filteredProjects := filterProjectsBySearch(allProjects, searchQuery)
-
-	// Create a map of assigned projects for quick lookup
-	assignedProjectMap := make(map[int]bool)
-	for _, up := range userProjects {
-		assignedProjectMap[up.ID] = true
-	}
-
-	// Show projects with pagination to stay within payload limits
-	const projectsPerPage = 10
-	currentPage := page // Use provided page parameter
-
-	startIdx := currentPage * projectsPerPage
-	endIdx := startIdx + projectsPerPage
-	if endIdx > len(filteredProjects) {
-		endIdx = len(filteredProjects)
-	}
-
-	totalPages := (len(filteredProjects) + projectsPerPage - 1) / projectsPerPage
-
-	// Header with pagination info and search results
-	headerText := fmt.Sprintf("*Projects* (Page %d of %d)", currentPage+1, totalPages)
-	if searchQuery != "" {
-		headerText += fmt.Sprintf(" - Found %d projects matching \"%s\"", len(filteredProjects), searchQuery)
-	}
-	blocks = append(blocks, Block{
-		Type: "section",
-		Text: &Text{
-			Type: "mrkdwn",
-			Text: headerText,
-		},
-	})
-
-	// Show current page of projects
-	if startIdx < len(filteredProjects) {
-		currentPageProjects := filteredProjects[startIdx:endIdx]
-
-		// Create checkbox options for current page
-		var checkboxOptions []map[string]interface{}
-		for _, project := range currentPageProjects {
-			option := map[string]interface{}{
-				"text": map[string]string{
-					"type": "plain_text",
-					"text": project.Name,
-				},
-				"value": fmt.Sprintf("%d", project.ID),
+			assignmentText += fmt.Sprintf("• %s\n", truncateUTF8(project.Name, 200))
+		}
+
+		blocks = append(blocks, SectionBlock(assignmentText))
+	}
+
+	// Groups get one row each when they fit in half the budget, otherwise a single menu
+	groupsCollapsed := len(data.ProjectGroups) > budget/2
+	if groupsCollapsed {
+		budget -= 2
+	} else {
+		budget -= max(len(data.ProjectGroups), 1)
+	}
+
+	// Project owners receive escalations for tasks far over budget
+	if len(userProjects) > 0 {
+		blocks = append(blocks, SectionBlock("*👑 Project Owners*\nOwners receive escalation alerts for tasks far over budget:"))
+
+		ownersPerPage := min(budget, APP_HOME_OWNERS_PER_PAGE)
+		totalPages := (len(userProjects) + ownersPerPage - 1) / ownersPerPage
+		page = max(0, min(page, totalPages-1))
+		pageProjects := userProjects[page*ownersPerPage : min((page+1)*ownersPerPage, len(userProjects))]
+
+		for _, project := range pageProjects {
+			ownerText := "_No owner_"
+			if ownerID := projectOwners[project.ID]; ownerID != "" {
+				ownerText = fmt.Sprintf("<@%s>", ownerID)
 			}
 
-			checkboxOptions = append(checkboxOptions, option)
-		}
-
-		// Create initial options (pre-selected checkboxes for assigned projects)
-		var initialOptions []map[string]interface{}
-		for _, project := range currentPageProjects {
-			if assignedProjectMap[project.ID] {
-				initialOption := map[string]interface{}{
-					"text": map[string]string{
-						"type": "plain_text",
-						"text": project.Name,
-					},
-					"value": fmt.Sprintf("%d", project.ID),
-				}
-				initialOptions = append(initialOptions, initialOption)
+			ownerBlock := SectionBlock(fmt.Sprintf("• %s — %s", truncateUTF8(project.Name, 200), ownerText))
+			if canChangeProjectOwner(userID, projectOwners[project.ID]) {
+				ownerSelect := NewSelect("users_select", fmt.Sprintf("project_owner_%d", project.ID), "Choose owner")
+				ownerSelect.InitialUser = projectOwners[project.ID]
+				ownerBlock = ownerBlock.WithAccessory(ownerSelect)
 			}
+			blocks = append(blocks, ownerBlock)
 		}
 
-		// Add checkbox group for project assignments
-		blocks = append(blocks, Block{
-			Type: "section",
-			Text: &Text{
-				Type: "mrkdwn",
-				Text: "Select projects to assign yourself to:",
-			},
-			Accessory: &Accessory{
-				Type:           "checkboxes",
-				ActionID:       fmt.Sprintf("project_assignments_page_%d_search_%s", currentPage, searchQuery),
-				Options:        checkboxOptions,
-				InitialOptions: initialOptions,
-			},
-		})
-
-		// Show pagination info
-		blocks = append(blocks, Block{
-			Type: "context",
-			Elements: []interface{}{
-				Element{
-					Type: "mrkdwn",
-					Text: fmt.Sprintf("_Showing projects %d-%d of %d total_", startIdx+1, endIdx, len(filteredProjects)),
-				},
-			},
-		})
-
 		// Pagination navigation (only show if we have multiple pages)
 		if totalPages > 1 {
-			var navElements []interface{}
-
-			// Previous button
-			if currentPage > 0 {
-				navElements = append(navElements, ButtonElement{
-					Type:     "button",
-					Text:     &Text{Type: "plain_text", Text: "⬅️ Previous"},
-					ActionID: fmt.Sprintf("page_%d_search_%s", currentPage-1, searchQuery),
-					Value:    fmt.Sprintf("%d|%s", currentPage-1, searchQuery),
-				})
+			var navElements []*Element
+			if page > 0 {
+				navElements = append(navElements, NewButton("owners_page_previous", "⬅️ Previous", strconv.Itoa(page-1)))
 			}
-
-			// Page indicator
-			navElements = append(navElements, ButtonElement{
-				Type:     "button",
-				Text:     &Text{Type: "plain_text", Text: fmt.Sprintf("Page %d/%d", currentPage+1, totalPages)},
-				ActionID: "page_info",
-				Value:    "info",
-			})
-
-			// Next button
-			if currentPage < totalPages-1 {
-				navElements = append(navElements, ButtonElement{
-					Type:     "button",
-					Text:     &Text{Type: "plain_text", Text: "Next ➡️"},
-					ActionID: fmt.Sprintf("page_%d_search_%s", currentPage+1, searchQuery),
-					Value:    fmt.Sprintf("%d|%s", currentPage+1, searchQuery),
-				})
+			if page < totalPages-1 {
+				navElements = append(navElements, NewButton("owners_page_next", "Next ➡️", strconv.Itoa(page+1)))
 			}
+			blocks = append(blocks, ActionsBlock(navElements...))
+			blocks = append(blocks, ContextBlock(fmt.Sprintf("_Owners page %d of %d_", page+1, totalPages)))
+		}
+	}
 
-			blocks = append(blocks, Block{
-				Type:     "actions",
-				Elements: navElements,
-			})
+	// Daily update delivery settings
+	dailyUpdateText := "Yesterday's report for your projects, sent as a direct message."
+	if len(userProjects) == 0 {
+		dailyUpdateText = "Assign yourself projects below to get yesterday's report for them as a direct message."
+	}
+	blocks = append(blocks, SectionBlock(
+		fmt.Sprintf("*☀️ Daily Update:* %s\n%s", data.DailyUpdate.Description(), dailyUpdateText)).
+		WithAccessory(NewButton("open_daily_update_settings", "⚙️ Settings", "")))
+
+	// Weekly email digest opt-in
+	digestStatus, digestButton := "_Off_", "Turn on"
+	if data.EmailDigestEnabled {
+		digestStatus, digestButton = "*On*", "Turn off"
+	}
+	blocks = append(blocks, SectionBlock(
+		fmt.Sprintf("*📧 Weekly Email Digest:* %s\nLast week's report for your projects, emailed every Monday.", digestStatus)).
+		WithAccessory(NewButton("toggle_email_digest", digestButton, "")))
+
+	// Saved project groups usable in /oye filters
+	blocks = append(blocks, DividerBlock())
+	blocks = append(blocks, SectionBlock(
+		"*🗂 Project Groups*\nUse a group anywhere a project name goes, e.g. `/oye project client-work for this week`").
+		WithAccessory(NewButton("open_project_group_modal", "➕ New group", "")))
+
+	switch {
+	case len(data.ProjectGroups) == 0:
+		blocks = append(blocks, ContextBlock("_No project groups yet_"))
+	case groupsCollapsed:
+		blocks = append(blocks, buildCollapsedProjectGroupBlocks(data.ProjectGroups, userID)...)
+	default:
+		for _, group := range data.ProjectGroups {
+			// Only the creator or an admin can delete a group, others get a plain edit button
+			editValue := fmt.Sprintf("edit|%d", group.ID)
+			menu := NewButton("project_group_menu", "✏️ Edit", editValue)
+			if canDeleteProjectGroup(userID, group) {
+				menu = NewOverflow("project_group_menu",
+					NewOption("✏️ Edit", editValue),
+					NewOption("🗑 Delete", fmt.Sprintf("delete|%d", group.ID)),
+				)
+			}
+			blocks = append(blocks, SectionBlock(fmt.Sprintf("• *%s* — %s", group.Name, summarizeProjectNames(group.Projects, 4))).
+				WithAccessory(menu))
 		}
 	}
 
+	// Project assignment through a single menu, whatever the number of projects
+	blocks = append(blocks, DividerBlock())
+	blocks = append(blocks, SectionBlock(
+		fmt.Sprintf("*🔧 Project Assignment*\nPick the projects you want to be assigned to (%d available, type to search):", len(allProjects))))
+	blocks = append(blocks, ActionsBlock(buildProjectAssignmentSelect(allProjects, userProjects)))
+
 	// Footer
-	blocks = append(blocks, Block{
-		Type: "context",
-		Elements: []interface{}{
-			Element{
-				Type: "mrkdwn",
-				Text: "🔄 This page updates automatically when you make changes",
-			},
-		},
-	})
-
-	return AppHomeView{
+	blocks = append(blocks, ContextBlock("🔄 This page updates automatically when you make changes"))
+
+	return View{
 		Type:   "home",
 		Blocks: blocks,
 	}
 }
 
+// buildCollapsedProjectGroupBlocks lists groups as one line and offers editing through a menu,
+// and deleting the groups the user may delete
+func buildCollapsedProjectGroupBlocks(groups []ProjectGroup, userID string) []Block {
+	names := make([]string, 0, len(groups))
+	editOptions := make([]Option, 0, len(groups))
+	deleteOptions := make([]Option, 0, len(groups))
+	for _, group := range groups {
+		names = append(names, group.Name)
+		// Slack allows 100 options per option group
+		if len(editOptions) < MAX_STATIC_SELECT_OPTIONS {
+			name := truncateUTF8(group.Name, 70)
+			editOptions = append(editOptions, NewOption(name, fmt.Sprintf("edit|%d", group.ID)))
+			if canDeleteProjectGroup(userID, group) {
+				deleteOptions = append(deleteOptions, NewOption(name, fmt.Sprintf("delete|%d", group.ID)))
+			}
+		}
+	}
+
+	groupMenu := NewSelect("static_select", "project_group_menu", "Edit or delete a group")
+	groupMenu.OptionGroups = []OptionGroup{NewOptionGroup("✏️ Edit", editOptions...)}
+	// Slack rejects empty option groups
+	if len(deleteOptions) > 0 {
+		groupMenu.OptionGroups = append(groupMenu.OptionGroups, NewOptionGroup("🗑 Delete", deleteOptions...))
+	}
+
+	return []Block{
+		SectionBlock(truncateUTF8(fmt.Sprintf("*%d groups:* %s", len(groups), strings.Join(names, ", ")), MAX_SECTION_TEXT_CHARS)),
+		ActionsBlock(groupMenu),
+	}
+}
+
+// buildProjectAssignmentSelect creates the project assignment menu. Small project lists are
+// sent inline; larger ones are searched through the options load URL (HandleBlockSuggestions).
+func buildProjectAssignmentSelect(allProjects, userProjects []Project) *Element {
+	element := NewSelect("multi_static_select", PROJECT_ASSIGNMENT_ACTION_ID, "Choose projects")
+	if len(allProjects) > MAX_STATIC_SELECT_OPTIONS {
+		element.Type = "multi_external_select"
+		element.MinQueryLength = new(int)
+	} else {
+		addProjectSelectOptions(element, allProjects)
+	}
+
+	for _, project := range userProjects {
+		element.InitialOptions = append(element.InitialOptions, projectSelectOption(project))
+	}
+	return element
+}
+
 // BuildFallbackAppHomeView builds a minimal App Home view when the regular view is too large
-func BuildFallbackAppHomeView(userProjectCount, totalProjectCount int) AppHomeView {
+func BuildFallbackAppHomeView(userProjectCount, totalProjectCount int) View {
 	var blocks []Block
 
 	// Minimal header
-	blocks = append(blocks, Block{
-		Type: "header",
-		Text: &Text{
-			Type: "plain_text",
-			Text: "🏠 OYE Time Tracker",
-		},
-	})
+	blocks = append(blocks, HeaderBlock("🏠 OYE Time Tracker"))
+	blocks = append(blocks, buildAppHomeTabs(APP_HOME_TAB_SETTINGS))
 
 	// Simple summary
-	blocks = append(blocks, Block{
-		Type: "section",
-		Text: &Text{
-			Type: "mrkdwn",
-			Text: fmt.Sprintf("*Project Summary*\n• Your assignments: %d\n• Total projects: %d", userProjectCount, totalProjectCount),
-		},
-	})
+	blocks = append(blocks, SectionBlock(
+		fmt.Sprintf("*Project Summary*\n• Your assignments: %d\n• Total projects: %d", userProjectCount, totalProjectCount)))
 
 	// Essential commands
-	blocks = append(blocks, Block{
-		Type: "section",
-		Text: &Text{
-			Type: "mrkdwn",
-			Text: "*Commands*\n• `/oye my-projects` - View your assignments\n• `/oye available-projects` - View all projects\n• `/oye assign \"Project Name\"` - Assign project\n• `/oye unassign \"Project Name\"` - Remove assignment",
-		},
-	})
-
-	return AppHomeView{
+	blocks = append(blocks, SectionBlock(
+		"*Commands*\n• `/oye my-projects` - View your assignments\n• `/oye available-projects` - View all projects\n• `/oye assign \"Project Name\"` - Assign project\n• `/oye unassign \"Project Name\"` - Remove assignment"))
+
+	return View{
 		Type:   "home",
 		Blocks: blocks,
 	}
@@ -462,12 +364,10 @@ func BuildFallbackAppHomeView(userProjectCount, totalProjectCount int) AppHomeVi
 
 // SlackEvent represents events from Slack
 type SlackEvent struct {
-	Type      string `json:"type"`
-	Challenge string `json:"challenge"`
-	Event     struct {
-		Type string `json:"type"`
-		User string `json:"user"`
-	} `json:"event"`
+	Type      string            `json:"type"`
+	Challenge string            `json:"challenge"`
+	EventID   string            `json:"event_id"`
+	Event     SlackMessageEvent `json:"event"`
 }
 
 // HandleInteractiveComponents handles button clicks and form interactions
@@ -522,10 +422,29 @@ func HandleInteractiveComponents(w http.ResponseWriter, r *http.Request) {
 	logger.Infof("Interactive component request from user %s, payload type: %s", payload.User.ID, payload.Type)
 	logger.Infof("Number of actions: %d", len(payload.Actions))
 
+	// Global shortcut for the report builder
+	if payload.Type == "shortcut" && payload.CallbackID == REPORT_BUILDER_SHORTCUT_ID {
+		if err := OpenReportBuilderModal(payload.TriggerID, ""); err != nil {
+			logger.Errorf("Failed to open report builder modal: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
 	// Handle modal submissions first
 	if payload.Type == "view_submission" {
 		logger.Info("Processing modal submission...")
 		if err := HandleModalSubmission(payload); err != nil {
+			var validationErr *ModalValidationError
+			if errors.As(err, &validationErr) {
+				// Show the problem next to the offending input instead of failing the modal
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(ViewSubmissionResponse{
+					ResponseAction: "errors",
+					Errors:         map[string]string{validationErr.BlockID: validationErr.Message},
+				})
+				return
+			}
 			logger.Errorf("Failed to handle modal submission: %v", err)
 			http.Error(w, "Failed to process modal submission", http.StatusInternalServerError)
 			return
@@ -534,94 +453,105 @@ func HandleInteractiveComponents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Extract search value from state if available
-	logger.Info("🔍 ABOUT TO CALL extractSearchValueFromState")
-	searchValue := extractSearchValueFromState(payload.State)
-	logger.Infof("🔍 RETURNED FROM extractSearchValueFromState with value: '%s'", searchValue)
-	if searchValue != "" {
-		logger.Infof("Found search value in state: '%s'", searchValue)
-	} else {
-		logger.Info("No search value found in state")
-	}
-
 	// Log all actions for debugging
 	for i, action := range payload.Actions {
 		logger.Infof("Action %d: ID='%s', Type='%s', Value='%s'", i, action.ActionID, action.Type, action.Value)
 	}
 
-	// Handle checkbox actions
+	// Handle block actions
 	if len(payload.Actions) > 0 {
 		action := payload.Actions[0]
 		logger.Infof("Action ID: %s, Selected options: %d", action.ActionID, len(action.SelectedOptions))
 
-		// Use search value from state for all actions (this ensures search is preserved)
-		currentSearchQuery := searchValue
-
-		if strings.HasPrefix(action.ActionID, "project_assignments") {
-			logger.Info("Processing project assignment checkboxes...")
-
-			// Extract page number and search query from action_id
-			pageNum := 0
-			searchQuery := ""
-			if strings.Contains(action.ActionID, "page_") {
-				parts := strings.Split(action.ActionID, "page_")
-				if len(parts) > 1 {
-					pageSearchParts := strings.Split(parts[1], "_search_")
-					if num, err := strconv.Atoi(pageSearchParts[0]); err == nil {
-						pageNum = num
-					}
-					// Extract search query if present
-					if len(pageSearchParts) > 1 {
-						searchQuery = pageSearchParts[1]
-					}
-				}
-			}
-			logger.Infof("Checkbox action: page=%d, search='%s'", pageNum, searchQuery)
-
-			if err := HandleProjectAssignmentCheckboxes(payload.User.ID, action.SelectedOptions, pageNum, searchQuery); err != nil {
-				logger.Errorf("Failed to handle project assignment checkboxes: %v", err)
+		if strings.HasPrefix(action.ActionID, REPORT_ACTION_PREFIX) {
+			// Report message buttons re-run queries, which can take longer than Slack waits for the ack
+			go HandleReportAction(payload, action)
+		} else if action.ActionID == PROJECT_ASSIGNMENT_ACTION_ID {
+			logger.Info("Processing project assignment selection...")
+			if err := HandleProjectAssignmentSelection(payload.User.ID, action.SelectedOptions); err != nil {
+				logger.Errorf("Failed to handle project assignment selection: %v", err)
 				http.Error(w, "Failed to process assignments", http.StatusInternalServerError)
 				return
 			}
 
-			// Refresh the App Home view with the same page and search from action_id
-			logger.Info("Refreshing App Home view...")
-			if err := PublishAppHomeViewWithSearch(payload.User.ID, pageNum, searchQuery); err != nil {
+			if err := PublishAppHomeView(payload.User.ID); err != nil {
 				logger.Errorf("Failed to refresh app home view: %v", err)
-			} else {
-				logger.Info("App Home view refreshed successfully")
 			}
-		} else if strings.HasPrefix(action.ActionID, "page_") {
-			logger.Info("Processing page navigation...")
-			if err := HandlePageNavigationWithSearch(payload.User.ID, action.ActionID, action.Value); err != nil {
-				logger.Errorf("Failed to handle page navigation: %v", err)
-				http.Error(w, "Failed to process page navigation", http.StatusInternalServerError)
+		} else if strings.HasPrefix(action.ActionID, "project_owner_") {
+			logger.Info("Processing project owner selection...")
+			if err := HandleProjectOwnerSelection(payload.User.ID, action.ActionID, action.SelectedUser); errors.Is(err, errProjectOwnerNotAllowed) {
+				logger.Warnf("User %s may not change the owner in %s", payload.User.ID, action.ActionID)
+			} else if err != nil {
+				logger.Errorf("Failed to set project owner: %v", err)
+				http.Error(w, "Failed to set project owner", http.StatusInternalServerError)
 				return
 			}
-		} else if action.ActionID == "project_search_input" {
-			logger.Info("Processing project search input action...")
-			// For input actions, refresh with the value from state
-			if err := PublishAppHomeViewWithSearch(payload.User.ID, 0, currentSearchQuery); err != nil {
-				logger.Errorf("Failed to update app home with search: %v", err)
+
+			if err := PublishAppHomeView(payload.User.ID); err != nil {
+				logger.Errorf("Failed to refresh app home view: %v", err)
+			}
+		} else if action.ActionID == "toggle_email_digest" {
+			logger.Info("Processing email digest toggle...")
+			if err := HandleEmailDigestToggle(payload.User.ID); err != nil {
+				logger.Errorf("Failed to toggle email digest: %v", err)
+			}
+
+			if err := PublishAppHomeView(payload.User.ID); err != nil {
+				logger.Errorf("Failed to refresh app home view: %v", err)
+			}
+		} else if action.ActionID == "app_home_tab_"+APP_HOME_TAB_DASHBOARD {
+			logger.Info("Processing dashboard tab...")
+			if err := PublishAppHomeDashboard(payload.User.ID, 0); err != nil {
+				logger.Errorf("Failed to publish app home dashboard: %v", err)
+			}
+		} else if action.ActionID == "app_home_tab_"+APP_HOME_TAB_SETTINGS {
+			logger.Info("Processing settings tab...")
+			if err := PublishAppHomeView(payload.User.ID); err != nil {
+				logger.Errorf("Failed to publish app home view: %v", err)
+			}
+		} else if action.ActionID == "dashboard_load_more" {
+			logger.Info("Processing dashboard load more...")
+			shown, _ := strconv.Atoi(action.Value)
+			if err := PublishAppHomeDashboard(payload.User.ID, shown+DASHBOARD_PROJECTS_PER_PAGE); err != nil {
+				logger.Errorf("Failed to publish app home dashboard: %v", err)
 			}
-		} else if action.ActionID == "open_search_modal" {
-			logger.Info("Processing open search modal button click...")
-			if err := OpenSearchModal(payload.TriggerID); err != nil {
-				logger.Errorf("Failed to open search modal: %v", err)
-			} else {
-				logger.Info("Successfully opened search modal")
+		} else if action.ActionID == "open_daily_update_settings" {
+			logger.Info("Processing open daily update settings...")
+			if err := OpenDailyUpdateSettingsModal(payload.TriggerID, payload.User.ID); err != nil {
+				logger.Errorf("Failed to open daily update settings modal: %v", err)
 			}
-		} else if action.ActionID == "clear_search" {
-			logger.Info("Processing clear search...")
-			if err := PublishAppHomeViewWithSearch(payload.User.ID, 0, ""); err != nil {
-				logger.Errorf("Failed to clear search: %v", err)
+		} else if action.ActionID == "open_project_group_modal" {
+			logger.Info("Processing open project group modal...")
+			if err := OpenProjectGroupModal(payload.TriggerID, 0); err != nil {
+				logger.Errorf("Failed to open project group modal: %v", err)
+			}
+		} else if action.ActionID == "project_group_menu" {
+			logger.Info("Processing project group menu...")
+			// Menus send the picked option, the edit button its own value
+			menuValue := action.SelectedOption.Value
+			if menuValue == "" {
+				menuValue = action.Value
+			}
+			if err := HandleProjectGroupMenu(payload.User.ID, payload.TriggerID, menuValue); errors.Is(err, errProjectGroupDeleteNotAllowed) {
+				logger.Warnf("User %s may not delete project group %s", payload.User.ID, menuValue)
+			} else if err != nil {
+				logger.Errorf("Failed to handle project group menu: %v", err)
+			}
+
+			if err := PublishAppHomeView(payload.User.ID); err != nil {
+				logger.Errorf("Failed to refresh app home view: %v", err)
+			}
+		} else if strings.HasPrefix(action.ActionID, "owners_page_") {
+			logger.Info("Processing owners page navigation...")
+			page, _ := strconv.Atoi(action.Value)
+			if err := PublishAppHomeViewWithPage(payload.User.ID, page); err != nil {
+				logger.Errorf("Failed to handle page navigation: %v", err)
 			}
 		} else {
 			logger.Warnf("Unknown action ID: %s", action.ActionID)
-			// For any unknown action, still try to preserve search state
-			logger.Info("Refreshing with preserved search state...")
-			if err := PublishAppHomeViewWithSearch(payload.User.ID, 0, currentSearchQuery); err != nil {
-				logger.Errorf("Failed to refresh with search state: %v", err)
+			// Buttons from older versions of the view end up here, so show the current one
+			if err := PublishAppHomeView(payload.User.ID); err != nil {
+				logger.Errorf("Failed to refresh app home view: %v", err)
 			}
 		}
 	} else {
@@ -633,18 +563,36 @@ func HandleInteractiveComponents(w http.ResponseWriter, r *http.Request) {
 
 // SlackInteractivePayload represents interactive component payloads
 type SlackInteractivePayload struct {
-	Type      string `json:"type"`
-	TriggerID string `json:"trigger_id"`
-	User      struct {
+	Type       string `json:"type"`
+	TriggerID  string `json:"trigger_id"`
+	CallbackID string `json:"callback_id,omitempty"` // shortcuts
+	User       struct {
 		ID   string `json:"id"`
 		Name string `json:"name,omitempty"`
 	} `json:"user"`
-	Actions []struct {
-		ActionID        string           `json:"action_id"`
-		Type            string           `json:"type,omitempty"`
-		SelectedOptions []SelectedOption `json:"selected_options,omitempty"`
-		Value           string           `json:"value,omitempty"`
-	} `json:"actions"`
+	Actions     []SlackAction `json:"actions"`
+	ResponseURL string        `json:"response_url,omitempty"`
+	// ResponseURLs are sent with modal submissions that have response_url_enabled inputs
+	ResponseURLs []struct {
+		BlockID     string `json:"block_id"`
+		ActionID    string `json:"action_id"`
+		ChannelID   string `json:"channel_id"`
+		ResponseURL string `json:"response_url"`
+	} `json:"response_urls,omitempty"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel,omitempty"`
+	Message struct {
+		Timestamp       string  `json:"ts"`
+		ThreadTimestamp string  `json:"thread_ts,omitempty"`
+		Blocks          []Block `json:"blocks,omitempty"`
+	} `json:"message,omitempty"`
+	Container struct {
+		Type        string `json:"type"`
+		IsEphemeral bool   `json:"is_ephemeral,omitempty"`
+		ChannelID   string `json:"channel_id,omitempty"`
+		MessageTS   string `json:"message_ts,omitempty"`
+	} `json:"container,omitempty"`
 	State struct {
 		Values map[string]map[string]struct {
 			Type  string `json:"type"`
@@ -652,23 +600,40 @@ type SlackInteractivePayload struct {
 		} `json:"values"`
 	} `json:"state,omitempty"`
 	View struct {
-		Type       string `json:"type,omitempty"`
-		CallbackID string `json:"callback_id,omitempty"`
-		State      struct {
+		Type            string `json:"type,omitempty"`
+		CallbackID      string `json:"callback_id,omitempty"`
+		PrivateMetadata string `json:"private_metadata,omitempty"`
+		State           struct {
 			Values map[string]map[string]struct {
-				Type  string `json:"type"`
-				Value string `json:"value"`
+				Type                 string           `json:"type"`
+				Value                string           `json:"value"`
+				SelectedOptions      []SelectedOption `json:"selected_options,omitempty"`
+				SelectedOption       SelectedOption   `json:"selected_option,omitempty"`
+				SelectedDate         string           `json:"selected_date,omitempty"`
+				SelectedConversation string           `json:"selected_conversation,omitempty"`
+				SelectedTime         string           `json:"selected_time,omitempty"`
 			} `json:"values"`
 		} `json:"state,omitempty"`
 	} `json:"view,omitempty"`
 }
 
+// SlackAction is a single block action of an interactive payload
+type SlackAction struct {
+	ActionID        string           `json:"action_id"`
+	BlockID         string           `json:"block_id,omitempty"`
+	Type            string           `json:"type,omitempty"`
+	SelectedOptions []SelectedOption `json:"selected_options,omitempty"`
+	SelectedOption  SelectedOption   `json:"selected_option,omitempty"`
+	SelectedUser    string           `json:"selected_user,omitempty"`
+	Value           string           `json:"value,omitempty"`
+}
+
 type SelectedOption struct {
 	Value string `json:"value"`
 }
 
-// HandleProjectAssignmentCheckboxes processes checkbox selections for project assignments
-func HandleProjectAssignmentCheckboxes(userID string, selectedOptions []SelectedOption, pageNum int, searchQuery string) error {
+// HandleProjectAssignmentSelection makes the user's assignments match the projects picked in the menu
+func HandleProjectAssignmentSelection(userID string, selectedOptions []SelectedOption) error {
 	logger := GetGlobalLogger()
 
 	db, err := GetDB()
@@ -676,178 +641,174 @@ func HandleProjectAssignmentCheckboxes(userID string, selectedOptions []Selected
 		return fmt.Errorf("failed to get database connection: %w", err)
 	}
 
-	// Get current user assignments
 	currentProjects, err := GetUserProjects(db, userID)
 	if err != nil {
 		return fmt.Errorf("failed to get current user projects: %w", err)
 	}
 
-	// Get all projects and apply search filter to match what user sees
-	allProjects, err := GetAllProjects(db)
-	if err != nil {
-		return fmt.Errorf("failed to get all projects: %w", err)
-	}
-
-	// Apply same search filter as in the UI
-	filteredProjects := filterProjectsBySearch(allProjects, searchQuery)
-
-	// Calculate which projects are visible on current page using same pagination logic
-	const projectsPerPage = 10
-	startIdx := pageNum * projectsPerPage
-	endIdx := startIdx + projectsPerPage
-	if endIdx > len(filteredProjects) {
-		endIdx = len(filteredProjects)
-	}
-
-	if startIdx >= len(filteredProjects) {
-		return fmt.Errorf("page %d is out of range for search results", pageNum)
-	}
-
-	currentPageProjects := filteredProjects[startIdx:endIdx]
-
-	// Create a map of projects visible on current page
-	visibleProjectIDs := make(map[int]bool)
-	for _, project := range currentPageProjects {
-		visibleProjectIDs[project.ID] = true
-	}
-
-	// Convert current assignments to a map for easy lookup
-	currentAssignments := make(map[int]bool)
-	for _, project := range currentProjects {
-		currentAssignments[project.ID] = true
-	}
-
-	// Convert selected options to project IDs
 	selectedProjectIDs := make(map[int]bool)
 	for _, option := range selectedOptions {
 		projectID, err := strconv.Atoi(option.Value)
 		if err != nil {
-			logger.Warnf("Invalid project ID in checkbox selection: %s", option.Value)
+			logger.Warnf("Invalid project ID in project selection: %s", option.Value)
 			continue
 		}
 		selectedProjectIDs[projectID] = true
 	}
 
-	// Determine what to add and what to remove - ONLY for projects visible on current page
-	var toAdd []int
-	var toRemove []int
-
-	// Find projects to add (selected but not currently assigned, and visible on current page)
-	for projectID := range selectedProjectIDs {
-		if visibleProjectIDs[projectID] && !currentAssignments[projectID] {
-			toAdd = append(toAdd, projectID)
+	added, removed := 0, 0
+	currentAssignments := make(map[int]bool)
+	for _, project := range currentProjects {
+		currentAssignments[project.ID] = true
+		if selectedProjectIDs[project.ID] {
+			continue
 		}
-	}
-
-	// Find projects to remove (currently assigned, visible on current page, but not selected)
-	for projectID := range visibleProjectIDs {
-		if currentAssignments[projectID] && !selectedProjectIDs[projectID] {
-			toRemove = append(toRemove, projectID)
+		if err := UnassignUserFromProject(db, userID, project.ID); err != nil {
+			logger.Errorf("Failed to unassign user %s from project %d: %v", userID, project.ID, err)
+			continue
 		}
+		removed++
 	}
 
-	// Add new assignments
-	for _, projectID := range toAdd {
+	for projectID := range selectedProjectIDs {
+		if currentAssignments[projectID] {
+			continue
+		}
 		if err := AssignUserToProject(db, userID, projectID); err != nil {
 			logger.Errorf("Failed to assign user %s to project %d: %v", userID, projectID, err)
-			// Continue with other assignments even if one fails
-		} else {
-			logger.Infof("Assigned user %s to project %d", userID, projectID)
+			continue
 		}
+		added++
 	}
 
-	// Remove old assignments
-	for _, projectID := range toRemove {
-		if err := UnassignUserFromProject(db, userID, projectID); err != nil {
-			logger.Errorf("Failed to unassign user %s from project %d: %v", userID, projectID, err)
-			// Continue with other unassignments even if one fails
-		} else {
-			logger.Infof("Unassigned user %s from project %d", userID, projectID)
+	logger.Infof("Project assignment update completed for user %s: %d added, %d removed", userID, added, removed)
+	return nil
+}
+
+// HandleBlockSuggestions serves the options of external select menus (the Slack app's options load URL)
+func HandleBlockSuggestions(w http.ResponseWriter, r *http.Request) {
+	logger := GetGlobalLogger()
+
+	if err := r.ParseForm(); err != nil {
+		logger.Errorf("Failed to parse options request: %v", err)
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	var payload struct {
+		Type     string `json:"type"`
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+		User     struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal([]byte(r.FormValue("payload")), &payload); err != nil {
+		logger.Errorf("Failed to unmarshal options payload: %v", err)
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	response := OptionsResponse{Options: []Option{}}
+	switch payload.ActionID {
+	case PROJECT_ASSIGNMENT_ACTION_ID:
+		db, err := GetDB()
+		if err != nil {
+			logger.Errorf("Failed to get database connection for options: %v", err)
+			http.Error(w, "Database unavailable", http.StatusInternalServerError)
+			return
+		}
+		allProjects, err := GetAllProjects(db)
+		if err != nil {
+			logger.Errorf("Failed to get projects for options: %v", err)
+			http.Error(w, "Failed to load projects", http.StatusInternalServerError)
+			return
+		}
+		for _, project := range filterProjectsBySearch(allProjects, payload.Value) {
+			if len(response.Options) >= MAX_STATIC_SELECT_OPTIONS {
+				break
+			}
+			response.Options = append(response.Options, projectSelectOption(project))
 		}
+	default:
+		logger.Warnf("No options source for action ID: %s", payload.ActionID)
 	}
 
-	logger.Infof("Project assignment update completed for user %s on page %d: %d added, %d removed", userID, pageNum, len(toAdd), len(toRemove))
-	return nil
-}
+	if err := response.Validate(); err != nil {
+		logger.Errorf("Invalid options for '%s': %v", payload.ActionID, err)
+		response.Options = []Option{}
+	}
 
-// HandlePageNavigation processes page navigation button clicks
-func HandlePageNavigation(userID, actionID, value string) error {
-	return HandlePageNavigationWithSearch(userID, actionID, value)
+	logger.Debugf("Returning %d options for '%s' to user %s", len(response.Options), payload.Value, payload.User.ID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
 }
 
-// HandlePageNavigationWithSearch processes page navigation with search support
-func HandlePageNavigationWithSearch(userID, actionID, value string) error {
+// errProjectOwnerNotAllowed is returned when someone other than the owner or an admin picks an owner
+var errProjectOwnerNotAllowed = errors.New("only the project owner or an OYE admin can change the owner")
+
+// HandleProjectOwnerSelection sets the owner of the project encoded in the action ID,
+// if the acting user is its current owner or an OYE admin
+func HandleProjectOwnerSelection(actingUserID, actionID, selectedUser string) error {
 	logger := GetGlobalLogger()
 
-	// Skip the page_info button (just shows current page)
-	if actionID == "page_info" {
-		return nil
+	projectID, err := strconv.Atoi(strings.TrimPrefix(actionID, "project_owner_"))
+	if err != nil {
+		return fmt.Errorf("invalid project ID in action %s", actionID)
+	}
+
+	if selectedUser == "" {
+		return fmt.Errorf("no user selected for project %d", projectID)
 	}
 
-	// Extract page number and search query from value (format: "pageNum|searchQuery")
-	parts := strings.Split(value, "|")
-	pageNum, err := strconv.Atoi(parts[0])
+	db, err := GetDB()
 	if err != nil {
-		return fmt.Errorf("invalid page number: %s", parts[0])
+		return fmt.Errorf("failed to get database connection: %w", err)
 	}
 
-	searchQuery := ""
-	if len(parts) > 1 {
-		searchQuery = parts[1]
+	currentOwner, err := GetProjectOwner(db, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to get owner of project %d: %w", projectID, err)
+	}
+	if !canChangeProjectOwner(actingUserID, currentOwner) {
+		return errProjectOwnerNotAllowed
 	}
 
-	// Refresh the App Home view with the new page and search
-	logger.Infof("Navigating to page %d with search '%s' for user %s", pageNum, searchQuery, userID)
-	if err := PublishAppHomeViewWithSearch(userID, pageNum, searchQuery); err != nil {
-		return fmt.Errorf("failed to refresh app home view with page %d and search '%s': %w", pageNum, searchQuery, err)
+	if err := SetProjectOwner(db, projectID, selectedUser); err != nil {
+		return err
 	}
 
-	logger.Infof("Successfully navigated to page %d with search '%s' for user %s", pageNum, searchQuery, userID)
+	logger.Infof("User %s set user %s as owner of project %d", actingUserID, selectedUser, projectID)
 	return nil
 }
 
-// extractSearchValueFromState extracts the search input value from the Slack state object
-func extractSearchValueFromState(state struct {
-	Values map[string]map[string]struct {
-		Type  string `json:"type"`
-		Value string `json:"value"`
-	} `json:"values"`
-}) string {
+// HandleEmailDigestToggle flips the user's weekly email digest opt-in
+func HandleEmailDigestToggle(userID string) error {
 	logger := GetGlobalLogger()
-	logger.Infof("=== DEBUGGING STATE EXTRACTION ===")
-	logger.Infof("Total blocks in state: %d", len(state.Values))
 
-	// Debug: Print the entire state structure
-	for blockID, block := range state.Values {
-		logger.Infof("BLOCK ID: '%s' (contains %d elements)", blockID, len(block))
-		for actionID, element := range block {
-			logger.Infof("  ACTION ID: '%s' | TYPE: '%s' | VALUE: '%s'", actionID, element.Type, element.Value)
-		}
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
 	}
 
-	// Look for the search input in the state values
-	for blockID, block := range state.Values {
-		for actionID, element := range block {
-			// Primary check: look for our specific action_id
-			if actionID == "project_search_input" {
-				return strings.TrimSpace(element.Value)
-			}
-		}
+	subscribed, err := IsUserSubscribedToEmailDigest(db, userID)
+	if err != nil {
+		return err
+	}
 
-		// Fallback: check if this is our specific search block
-		if blockID == "search_input_block" {
-			logger.Infof("✅ FOUND search_input_block, checking all elements...")
-			for actionID, element := range block {
-				logger.Infof("  Checking element in search block: actionID='%s', type='%s', value='%s'", actionID, element.Type, element.Value)
-				if element.Type == "plain_text_input" {
-					logger.Infof("✅ FOUND plain_text_input in search block with value: '%s'", element.Value)
-					return strings.TrimSpace(element.Value)
-				}
-			}
+	if subscribed {
+		if err := UnsubscribeUserFromEmailDigest(db, userID); err != nil {
+			return err
 		}
+		logger.Infof("User %s opted out of the weekly email digest", userID)
+		return nil
 	}
 
-	return ""
+	if err := SubscribeUserToEmailDigest(db, userID); err != nil {
+		return err
+	}
+	logger.Infof("User %s opted into the weekly email digest", userID)
+	return nil
 }
 
 // filterProjectsBySearch filters projects based on a search query
@@ -872,80 +833,276 @@ func filterProjectsBySearch(projects []Project, query string) []Project {
 	return filtered
 }
 
-// OpenSearchModal opens a modal dialog for project search
-func OpenSearchModal(triggerID string) error {
+// HandleModalSubmission handles modal form submissions
+func HandleModalSubmission(payload SlackInteractivePayload) error {
 	logger := GetGlobalLogger()
-	slackClient := NewSlackAPIClient()
 
-	modal := map[string]interface{}{
-		"type":        "modal",
-		"callback_id": "search_modal",
-		"title": map[string]string{
-			"type": "plain_text",
-			"text": "Search Projects",
-		},
-		"submit": map[string]string{
-			"type": "plain_text",
-			"text": "Search",
-		},
-		"close": map[string]string{
-			"type": "plain_text",
-			"text": "Cancel",
-		},
-		"blocks": []map[string]interface{}{
-			{
-				"type":     "input",
-				"block_id": "search_input",
-				"label": map[string]string{
-					"type": "plain_text",
-					"text": "Search Term",
-				},
-				"element": map[string]interface{}{
-					"type":      "plain_text_input",
-					"action_id": "search_value",
-					"placeholder": map[string]string{
-						"type": "plain_text",
-						"text": "Enter project name to search...",
-					},
-				},
-			},
-		},
-	}
-
-	payload := map[string]interface{}{
-		"trigger_id": triggerID,
-		"view":       modal,
-	}
-
-	logger.Infof("Opening search modal with trigger_id: %s", triggerID)
-	return slackClient.sendSlackAPIRequest("views.open", payload)
+	switch payload.View.CallbackID {
+	case "project_group_modal":
+		return HandleProjectGroupSubmission(payload)
+	case PROJECT_GROUP_DELETE_CALLBACK_ID:
+		return HandleProjectGroupDeleteSubmission(payload)
+	case REPORT_BUILDER_CALLBACK_ID:
+		return HandleReportBuilderSubmission(payload)
+	case DAILY_UPDATE_SETTINGS_CALLBACK_ID:
+		return HandleDailyUpdateSettingsSubmission(payload)
+	}
+
+	logger.Warnf("Unknown modal callback_id: %s", payload.View.CallbackID)
+	return nil
 }
 
-// HandleModalSubmission handles modal form submissions
-func HandleModalSubmission(payload SlackInteractivePayload) error {
+// ModalValidationError is shown by Slack next to the modal input identified by BlockID
+type ModalValidationError struct {
+	BlockID string
+	Message string
+}
+
+func (e *ModalValidationError) Error() string {
+	return e.Message
+}
+
+// summarizeProjectNames lists up to max project names followed by a count of the rest
+func summarizeProjectNames(projects []Project, max int) string {
+	if len(projects) == 0 {
+		return "_no projects_"
+	}
+
+	var names []string
+	for i, project := range projects {
+		if i >= max {
+			names = append(names, fmt.Sprintf("+%d more", len(projects)-max))
+			break
+		}
+		names = append(names, project.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// projectSelectOption builds a select menu option for a project
+func projectSelectOption(project Project) Option {
+	name := project.Name
+	if len([]rune(name)) > MAX_OPTION_TEXT_CHARS {
+		name = string([]rune(name)[:MAX_OPTION_TEXT_CHARS-3]) + "..."
+	}
+	return NewOption(name, strconv.Itoa(project.ID))
+}
+
+// addProjectSelectOptions fills a static select with project options. Slack allows
+// 100 options per menu, so larger project lists are split into option groups by first letter.
+func addProjectSelectOptions(element *Element, projects []Project) {
+	if len(projects) <= MAX_STATIC_SELECT_OPTIONS {
+		for _, project := range projects {
+			element.Options = append(element.Options, projectSelectOption(project))
+		}
+		return
+	}
+
+	var currentLabel string
+	var currentOptions []Option
+	flush := func() {
+		if len(currentOptions) > 0 {
+			element.OptionGroups = append(element.OptionGroups, NewOptionGroup(currentLabel, currentOptions...))
+		}
+		currentOptions = nil
+	}
+
+	for _, project := range projects {
+		label := strings.ToUpper(string([]rune(strings.TrimSpace(project.Name) + "#")[0]))
+		if label != currentLabel || len(currentOptions) >= MAX_STATIC_SELECT_OPTIONS {
+			flush()
+			currentLabel = label
+		}
+		currentOptions = append(currentOptions, projectSelectOption(project))
+	}
+	flush()
+
+	if len(element.OptionGroups) > MAX_OPTION_GROUPS {
+		element.OptionGroups = element.OptionGroups[:MAX_OPTION_GROUPS]
+	}
+}
+
+// OpenProjectGroupModal opens the create/edit modal for a project group (groupID 0 creates a new group)
+func OpenProjectGroupModal(triggerID string, groupID int) error {
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	allProjects, err := GetAllProjects(db)
+	if err != nil {
+		return fmt.Errorf("failed to get all projects: %w", err)
+	}
+
+	var group *ProjectGroup
+	if groupID != 0 {
+		group, err = GetProjectGroup(db, groupID)
+		if err != nil {
+			return err
+		}
+		if group == nil {
+			return fmt.Errorf("project group %d not found", groupID)
+		}
+	}
+
+	nameInput := NewPlainTextInput("group_name_value", "e.g. client-work")
+	projectSelect := NewSelect("multi_static_select", "group_projects_value", "Choose projects")
+	addProjectSelectOptions(projectSelect, allProjects)
+
+	title := "New Project Group"
+	if group != nil {
+		title = "Edit Project Group"
+		nameInput.InitialValue = group.Name
+		for _, project := range group.Projects {
+			projectSelect.InitialOptions = append(projectSelect.InitialOptions, projectSelectOption(project))
+		}
+	}
+
+	modal := NewModal("project_group_modal", title, "Save",
+		InputBlock("group_name", "Group name", nameInput).
+			WithHint("Single words work best in commands, e.g. client-work"),
+		InputBlock("group_projects", "Projects", projectSelect),
+	)
+	modal.PrivateMetadata = strconv.Itoa(groupID)
+
+	return NewSlackAPIClient().sendSlackAPIRequest("views.open", ViewRequest{TriggerID: triggerID, View: modal})
+}
+
+// PROJECT_GROUP_DELETE_CALLBACK_ID identifies the modal confirming a project group deletion
+const PROJECT_GROUP_DELETE_CALLBACK_ID = "project_group_delete_modal"
+
+// errProjectGroupDeleteNotAllowed is returned when someone other than the creator or an admin deletes a group
+var errProjectGroupDeleteNotAllowed = errors.New("only the creator of a project group or an OYE admin can delete it")
+
+// HandleProjectGroupMenu handles the edit/delete overflow menu of a project group.
+// Deleting asks for confirmation first, and only the group's creator or an admin may.
+func HandleProjectGroupMenu(actingUserID, triggerID, value string) error {
+	parts := strings.SplitN(value, "|", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid project group menu value: %s", value)
+	}
+	groupID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid project group ID: %s", parts[1])
+	}
+
+	switch parts[0] {
+	case "edit":
+		return OpenProjectGroupModal(triggerID, groupID)
+	case "delete":
+		return OpenProjectGroupDeleteModal(actingUserID, triggerID, groupID)
+	default:
+		return fmt.Errorf("unknown project group menu action: %s", parts[0])
+	}
+}
+
+// OpenProjectGroupDeleteModal asks the user to confirm deleting a project group
+func OpenProjectGroupDeleteModal(actingUserID, triggerID string, groupID int) error {
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	group, err := GetProjectGroup(db, groupID)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return fmt.Errorf("project group %d not found", groupID)
+	}
+	if !canDeleteProjectGroup(actingUserID, *group) {
+		return errProjectGroupDeleteNotAllowed
+	}
+
+	modal := NewModal(PROJECT_GROUP_DELETE_CALLBACK_ID, "Delete Project Group", "Delete",
+		SectionBlock(truncateUTF8(fmt.Sprintf("Delete the project group *%s* (%s)? Commands and subscriptions using it will stop matching. This can't be undone.",
+			group.Name, summarizeProjectNames(group.Projects, 4)), MAX_SECTION_TEXT_CHARS)),
+	)
+	modal.PrivateMetadata = strconv.Itoa(groupID)
+
+	return NewSlackAPIClient().sendSlackAPIRequest("views.open", ViewRequest{TriggerID: triggerID, View: modal})
+}
+
+// HandleProjectGroupDeleteSubmission deletes a project group once the user confirmed it
+func HandleProjectGroupDeleteSubmission(payload SlackInteractivePayload) error {
 	logger := GetGlobalLogger()
 
-	// Check if this is our search modal
-	if payload.View.CallbackID != "search_modal" {
-		logger.Warnf("Unknown modal callback_id: %s", payload.View.CallbackID)
-		return nil
+	groupID, err := strconv.Atoi(payload.View.PrivateMetadata)
+	if err != nil {
+		return fmt.Errorf("invalid project group ID: %s", payload.View.PrivateMetadata)
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
 	}
 
-	// Extract search value from modal state
-	searchValue := ""
-	if values, ok := payload.View.State.Values["search_input"]; ok {
-		if searchField, ok := values["search_value"]; ok {
-			searchValue = strings.TrimSpace(searchField.Value)
+	// Checked again, the modal may have been open while admins changed
+	group, err := GetProjectGroup(db, groupID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case group == nil:
+		logger.Infof("Project group %d was already deleted", groupID)
+	case !canDeleteProjectGroup(payload.User.ID, *group):
+		logger.Warnf("User %s may not delete project group %d", payload.User.ID, groupID)
+	default:
+		if err := DeleteProjectGroup(db, groupID); err != nil {
+			return err
 		}
+		logger.Infof("User %s deleted project group %d '%s'", payload.User.ID, groupID, group.Name)
 	}
 
-	logger.Infof("Modal search submitted with value: '%s'", searchValue)
+	if err := PublishAppHomeView(payload.User.ID); err != nil {
+		logger.Errorf("Failed to refresh app home view: %v", err)
+	}
+	return nil
+}
+
+// HandleProjectGroupSubmission saves the project group modal and refreshes the App Home
+func HandleProjectGroupSubmission(payload SlackInteractivePayload) error {
+	logger := GetGlobalLogger()
 
-	// Refresh the App Home view with the search results
-	if err := PublishAppHomeViewWithSearch(payload.User.ID, 0, searchValue); err != nil {
-		return fmt.Errorf("failed to refresh app home with search results: %w", err)
+	name := strings.TrimSpace(payload.View.State.Values["group_name"]["group_name_value"].Value)
+	if name == "" {
+		return &ModalValidationError{BlockID: "group_name", Message: "Please enter a group name"}
+	}
+	if strings.ContainsAny(name, ",\"'") {
+		return &ModalValidationError{BlockID: "group_name", Message: "Group names can't contain commas or quotes"}
+	}
+
+	var projectIDs []int
+	for _, option := range payload.View.State.Values["group_projects"]["group_projects_value"].SelectedOptions {
+		projectID, err := strconv.Atoi(option.Value)
+		if err != nil {
+			logger.Warnf("Invalid project ID in group selection: %s", option.Value)
+			continue
+		}
+		projectIDs = append(projectIDs, projectID)
+	}
+	if len(projectIDs) == 0 {
+		return &ModalValidationError{BlockID: "group_projects", Message: "Please choose at least one project"}
 	}
 
-	logger.Infof("Successfully processed modal search for query: '%s'", searchValue)
+	groupID, _ := strconv.Atoi(payload.View.PrivateMetadata)
+
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	savedID, err := SaveProjectGroup(db, groupID, name, payload.User.ID, projectIDs)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return &ModalValidationError{BlockID: "group_name", Message: err.Error()}
+		}
+		return err
+	}
+	logger.Infof("User %s saved project group %d '%s' with %d projects", payload.User.ID, savedID, name, len(projectIDs))
+
+	if err := PublishAppHomeView(payload.User.ID); err != nil {
+		logger.Errorf("Failed to refresh app home view: %v", err)
+	}
 	return nil
 }
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// App Home dashboard: per assigned project budget stats, shown before the settings tab.
+// Project sections are published as placeholders first and filled in once their
+// queries finish, a page of projects at a time, to stay within the home view limits.
+
+const (
+	APP_HOME_TAB_DASHBOARD = "dashboard"
+	APP_HOME_TAB_SETTINGS  = "settings"
+
+	DASHBOARD_PROJECTS_PER_PAGE = 5
+	DASHBOARD_TOP_TASKS         = 5
+	DASHBOARD_ALERT_DAYS        = 7
+	DASHBOARD_MAX_ALERTS        = 3
+	dashboardBlocksPerProject   = 4 // stats, top tasks, alerts, divider
+	dashboardFixedBlocks        = 5 // header, tabs, intro, load more, footer
+)
+
+// dashboardLoads holds each user's latest dashboard load that is still running, so slow
+// queries don't overwrite the settings tab or a newer page the user switched to meanwhile.
+// Entries are removed when the load finishes, the map only grows with concurrent loads.
+var (
+	dashboardLoadsMu sync.Mutex
+	dashboardLoads   = make(map[string]uint64)
+	dashboardLoadSeq uint64
+)
+
+// startDashboardLoad registers a dashboard load for the user, replacing any older one
+func startDashboardLoad(userID string) uint64 {
+	dashboardLoadsMu.Lock()
+	defer dashboardLoadsMu.Unlock()
+	dashboardLoadSeq++
+	dashboardLoads[userID] = dashboardLoadSeq
+	return dashboardLoadSeq
+}
+
+// cancelDashboardLoad drops the user's running dashboard load, e.g. when they open settings
+func cancelDashboardLoad(userID string) {
+	dashboardLoadsMu.Lock()
+	defer dashboardLoadsMu.Unlock()
+	delete(dashboardLoads, userID)
+}
+
+// finishDashboardLoad removes the load and reports whether it's still the one to publish
+func finishDashboardLoad(userID string, load uint64) bool {
+	dashboardLoadsMu.Lock()
+	defer dashboardLoadsMu.Unlock()
+	if dashboardLoads[userID] != load {
+		return false
+	}
+	delete(dashboardLoads, userID)
+	return true
+}
+
+// DashboardTask is a task shown in a project's top list
+type DashboardTask struct {
+	Name       string
+	Percentage float64
+}
+
+// DashboardAlert is a recent threshold notification for a task
+type DashboardAlert struct {
+	TaskName   string
+	Threshold  int
+	NotifiedAt time.Time
+}
+
+// ProjectDashboard holds the stats of one project section
+type ProjectDashboard struct {
+	Name         string
+	WeekSeconds  int
+	MonthSeconds int
+	OverBudget   int // estimated tasks with time this month at or over 100%
+	TopTasks     []DashboardTask
+	Alerts       []DashboardAlert
+}
+
+// buildAppHomeTabs creates the tab switcher shown under the App Home header
+func buildAppHomeTabs(active string) Block {
+	tab := func(text, value string) *Element {
+		button := NewButton("app_home_tab_"+value, text, value)
+		if value == active {
+			button.Style = "primary"
+		}
+		return button
+	}
+
+	return ActionsBlock(
+		tab("📊 Dashboard", APP_HOME_TAB_DASHBOARD),
+		tab("⚙️ Settings", APP_HOME_TAB_SETTINGS),
+	)
+}
+
+// maxDashboardProjects is how many project sections fit in one home view
+func maxDashboardProjects() int {
+	return (MAX_HOME_VIEW_BLOCKS - dashboardFixedBlocks) / dashboardBlocksPerProject
+}
+
+// loadProjectDashboards computes the stats of the given projects in a few queries
+func loadProjectDashboards(db *sql.DB, projects []Project, now time.Time) ([]ProjectDashboard, error) {
+	dashboards := make([]ProjectDashboard, len(projects))
+	byName := make(map[string]*ProjectDashboard, len(projects))
+	for i, project := range projects {
+		dashboards[i].Name = project.Name
+		byName[strings.ToLower(project.Name)] = &dashboards[i]
+	}
+	if len(projects) == 0 {
+		return dashboards, nil
+	}
+
+	allTasks, err := getAllTasks(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all tasks: %w", err)
+	}
+	dashboardFor := func(taskID int) *ProjectDashboard {
+		return byName[strings.ToLower(getProjectNameForTask(taskID, allTasks))]
+	}
+
+	// The week can start in the previous month, so query from whichever is earlier
+	weekStart := startOfWeek(now).Format("2006-01-02")
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format("2006-01-02")
+	since := monthStart
+	if weekStart < since {
+		since = weekStart
+	}
+
+	rows, err := db.Query(`
+		SELECT
+			t.task_id,
+			t.name,
+			COALESCE(SUM(CASE WHEN te.date >= $1::text THEN te.duration ELSE 0 END), 0) as week_duration,
+			COALESCE(SUM(CASE WHEN te.date >= $2::text THEN te.duration ELSE 0 END), 0) as month_duration,
+			(SELECT COALESCE(SUM(all_te.duration), 0) FROM time_entries all_te WHERE all_te.task_id = t.task_id) as total_duration
+		FROM tasks t
+		JOIN time_entries te ON te.task_id = t.task_id
+		WHERE te.date >= $3::text
+		GROUP BY t.task_id, t.name`,
+		weekStart, monthStart, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project time: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID, weekDuration, monthDuration, totalDuration int
+		var name string
+		if err := rows.Scan(&taskID, &name, &weekDuration, &monthDuration, &totalDuration); err != nil {
+			return nil, fmt.Errorf("failed to scan project time: %w", err)
+		}
+
+		dashboard := dashboardFor(taskID)
+		if dashboard == nil {
+			continue
+		}
+		dashboard.WeekSeconds += weekDuration
+		dashboard.MonthSeconds += monthDuration
+
+		if monthDuration == 0 {
+			continue
+		}
+		estimation := ParseTaskEstimationWithUsage(name, formatDuration(totalDuration), "0h 0m")
+		if estimation.ErrorMessage != "" {
+			continue
+		}
+		dashboard.TopTasks = append(dashboard.TopTasks, DashboardTask{Name: name, Percentage: estimation.Percentage})
+		if estimation.Percentage >= THRESHOLD_OVER {
+			dashboard.OverBudget++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read project time: %w", err)
+	}
+
+	alertRows, err := db.Query(`
+		SELECT n.task_id, t.name, n.threshold_percentage, n.notified_at
+		FROM threshold_notifications n
+		JOIN tasks t ON t.task_id = n.task_id
+		WHERE n.notified_at >= $1
+		ORDER BY n.notified_at DESC`,
+		now.AddDate(0, 0, -DASHBOARD_ALERT_DAYS))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query threshold alerts: %w", err)
+	}
+	defer alertRows.Close()
+
+	for alertRows.Next() {
+		var taskID int
+		var alert DashboardAlert
+		if err := alertRows.Scan(&taskID, &alert.TaskName, &alert.Threshold, &alert.NotifiedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan threshold alert: %w", err)
+		}
+		if dashboard := dashboardFor(taskID); dashboard != nil && len(dashboard.Alerts) < DASHBOARD_MAX_ALERTS {
+			dashboard.Alerts = append(dashboard.Alerts, alert)
+		}
+	}
+	if err := alertRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read threshold alerts: %w", err)
+	}
+
+	for i := range dashboards {
+		tasks := dashboards[i].TopTasks
+		sort.SliceStable(tasks, func(a, b int) bool { return tasks[a].Percentage > tasks[b].Percentage })
+		if len(tasks) > DASHBOARD_TOP_TASKS {
+			dashboards[i].TopTasks = tasks[:DASHBOARD_TOP_TASKS]
+		}
+	}
+	return dashboards, nil
+}
+
+// buildProjectDashboardBlocks renders one project section; a nil dashboard renders a placeholder
+func buildProjectDashboardBlocks(projectName string, dashboard *ProjectDashboard) []Block {
+	if dashboard == nil {
+		return []Block{
+			SectionBlock(fmt.Sprintf("%s *%s*", EMOJI_FOLDER, projectName)),
+			ContextBlock("⏳ _Loading..._"),
+			DividerBlock(),
+		}
+	}
+
+	blocks := []Block{FieldsBlock(fmt.Sprintf("%s *%s*", EMOJI_FOLDER, dashboard.Name),
+		fmt.Sprintf("*This week*\n%s", formatDuration(dashboard.WeekSeconds)),
+		fmt.Sprintf("*This month*\n%s", formatDuration(dashboard.MonthSeconds)),
+		fmt.Sprintf("*Over budget*\n%d tasks", dashboard.OverBudget),
+	)}
+
+	taskText := "_No estimated tasks with time this month_"
+	if len(dashboard.TopTasks) > 0 {
+		lines := make([]string, 0, len(dashboard.TopTasks))
+		for _, task := range dashboard.TopTasks {
+			lines = append(lines, fmt.Sprintf("%s %s — %.0f%%", GetTaskStatus(task.Percentage).Emoji, truncateUTF8(task.Name, 80), task.Percentage))
+		}
+		taskText = "*Top tasks by budget used*\n" + strings.Join(lines, "\n")
+	}
+	blocks = append(blocks, SectionBlock(taskText))
+
+	if len(dashboard.Alerts) > 0 {
+		alerts := make([]string, 0, len(dashboard.Alerts))
+		for _, alert := range dashboard.Alerts {
+			alerts = append(alerts, fmt.Sprintf("%s %s reached %d%% (%s)",
+				GetThresholdStatus(float64(alert.Threshold)).Emoji, truncateUTF8(alert.TaskName, 60), alert.Threshold, alert.NotifiedAt.Format("Jan 2")))
+		}
+		blocks = append(blocks, ContextBlock("🔔 "+strings.Join(alerts, " · ")))
+	}
+
+	return append(blocks, DividerBlock())
+}
+
+// BuildAppHomeDashboardView builds the dashboard tab for the first shown projects;
+// projects missing from dashboards render as loading placeholders
+func BuildAppHomeDashboardView(userProjects []Project, shown int, dashboards map[string]ProjectDashboard) View {
+	blocks := []Block{
+		HeaderBlock("🏠 OYE Time Tracker"),
+		buildAppHomeTabs(APP_HOME_TAB_DASHBOARD),
+	}
+
+	if len(userProjects) == 0 {
+		blocks = append(blocks, SectionBlock("_You have no projects assigned yet._\nOpen *⚙️ Settings* to pick the projects you follow."))
+		return View{Type: "home", Blocks: blocks}
+	}
+
+	blocks = append(blocks, ContextBlock(fmt.Sprintf(
+		"Budget overview for your %d projects · updated %s", len(userProjects), time.Now().Format("Jan 2 15:04"))))
+
+	for _, project := range userProjects[:shown] {
+		var dashboard *ProjectDashboard
+		if loaded, ok := dashboards[project.Name]; ok {
+			dashboard = &loaded
+		}
+		blocks = append(blocks, buildProjectDashboardBlocks(project.Name, dashboard)...)
+	}
+
+	if shown < len(userProjects) {
+		if shown < maxDashboardProjects() {
+			blocks = append(blocks, ActionsBlock(NewButton("dashboard_load_more",
+				fmt.Sprintf("Show more projects (%d left)", len(userProjects)-shown), strconv.Itoa(shown))))
+		} else {
+			blocks = append(blocks, ContextBlock(fmt.Sprintf(
+				"_%d more projects don't fit here, use `/oye project [name] for this month summary`_", len(userProjects)-shown)))
+		}
+	}
+
+	return View{Type: "home", Blocks: blocks}
+}
+
+// PublishAppHomeDashboard publishes the dashboard with the first shown projects. The
+// sections are published as placeholders first and filled in when the queries finish.
+func PublishAppHomeDashboard(userID string, shown int) error {
+	logger := GetGlobalLogger()
+
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	userProjects, err := GetUserProjects(db, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user projects: %w", err)
+	}
+
+	if shown < DASHBOARD_PROJECTS_PER_PAGE {
+		shown = DASHBOARD_PROJECTS_PER_PAGE
+	}
+	if shown > maxDashboardProjects() {
+		shown = maxDashboardProjects()
+	}
+	if shown > len(userProjects) {
+		shown = len(userProjects)
+	}
+
+	slackClient := NewSlackAPIClient()
+	publish := func(dashboards map[string]ProjectDashboard) error {
+		return slackClient.sendSlackAPIRequest("views.publish", ViewRequest{
+			UserID: userID,
+			View:   BuildAppHomeDashboardView(userProjects, shown, dashboards),
+		})
+	}
+
+	if err := publish(nil); err != nil {
+		return err
+	}
+	if shown == 0 {
+		cancelDashboardLoad(userID)
+		return nil
+	}
+
+	load := startDashboardLoad(userID)
+	go func() {
+		loaded, err := loadProjectDashboards(db, userProjects[:shown], time.Now())
+		if err != nil {
+			finishDashboardLoad(userID, load)
+			logger.Errorf("Failed to load dashboard for user %s: %v", userID, err)
+			return
+		}
+
+		dashboards := make(map[string]ProjectDashboard, len(loaded))
+		for _, dashboard := range loaded {
+			dashboards[dashboard.Name] = dashboard
+		}
+
+		// The user may have switched to settings or another page while the queries ran
+		if !finishDashboardLoad(userID, load) {
+			return
+		}
+		if err := publish(dashboards); err != nil {
+			logger.Errorf("Failed to publish dashboard for user %s: %v", userID, err)
+		}
+	}()
+	return nil
+}
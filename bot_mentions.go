@@ -0,0 +1,291 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SlackMessageEvent is the inner event of app_mention and message events
+type SlackMessageEvent struct {
+	Type        string `json:"type"`
+	User        string `json:"user"`
+	Text        string `json:"text"`
+	Channel     string `json:"channel"`
+	ChannelType string `json:"channel_type"`
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts"`
+	BotID       string `json:"bot_id"`
+	Subtype     string `json:"subtype"`
+}
+
+// isBotQuestion reports whether an event is a person talking to the bot:
+// a mention in a channel or a direct message. Edits, joins and bot posts
+// (including our own replies) have a subtype or bot ID and are skipped.
+func isBotQuestion(event SlackMessageEvent) bool {
+	if event.BotID != "" || event.Subtype != "" || event.User == "" {
+		return false
+	}
+	switch event.Type {
+	case "app_mention":
+		return true
+	case "message":
+		return event.ChannelType == "im"
+	}
+	return false
+}
+
+// markSlackEventProcessed records an event ID and reports whether it was new.
+// Slack retries events it did not see acknowledged in time, so a retry finds its ID taken.
+func markSlackEventProcessed(db *sql.DB, eventID string) (bool, error) {
+	result, err := db.Exec(`
+		INSERT INTO processed_slack_events (event_id) VALUES ($1)
+		ON CONFLICT (event_id) DO NOTHING`, eventID)
+	if err != nil {
+		return false, fmt.Errorf("failed to record Slack event %s: %w", eventID, err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check Slack event %s: %w", eventID, err)
+	}
+	return inserted == 1, nil
+}
+
+// cleanupProcessedSlackEvents forgets event IDs older than Slack's retry window by a wide margin
+func cleanupProcessedSlackEvents(db *sql.DB) (int64, error) {
+	result, err := db.Exec(`DELETE FROM processed_slack_events WHERE received_at < NOW() - INTERVAL '7 days'`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up processed Slack events: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+var (
+	slackMentionPattern = regexp.MustCompile(`<@[A-Z0-9]+(\|[^>]*)?>`)
+	statusIntentPattern = regexp.MustCompile(`(?i)^(?:what(?:'s|’s| is)\s+(?:the\s+)?)?status\s+(?:of|for|on)\s+(?:project\s+)?(.+)$`)
+	howIsIntentPattern  = regexp.MustCompile(`(?i)^how(?:'s|’s| is)\s+(?:project\s+)?(.+?)\s+doing$`)
+	overBudgetPattern   = regexp.MustCompile(`(?i)^(?:what(?:'s|’s| is)\s+|what\s+is\s+|which\s+tasks\s+are\s+|anything\s+)?over\s*budget$`)
+	myTimeIntentPattern = regexp.MustCompile(`(?i)^(?:what(?:'s|’s| is)\s+|show\s+)?my\s+time(?:\s+(.+))?$`)
+)
+
+// botQuestionText strips mentions and trailing punctuation from a message
+func botQuestionText(text string) string {
+	text = slackMentionPattern.ReplaceAllString(text, "")
+	return strings.TrimRight(strings.TrimSpace(text), "?!. ")
+}
+
+// botIntentCommand maps the canned questions to report commands.
+// Returns ok false when the text is none of them.
+func botIntentCommand(text string) (command string, ok bool) {
+	if match := statusIntentPattern.FindStringSubmatch(text); match != nil {
+		return fmt.Sprintf(`project "%s" for this week`, strings.Trim(match[1], `"“”`)), true
+	}
+	if match := howIsIntentPattern.FindStringSubmatch(text); match != nil {
+		return fmt.Sprintf(`project "%s" for this week`, strings.Trim(match[1], `"“”`)), true
+	}
+	if overBudgetPattern.MatchString(text) {
+		return "over 100 for this month", true
+	}
+	return "", false
+}
+
+const botHelpText = "I can answer:\n" +
+	"• `status of <project>`: this week's time on a project\n" +
+	"• `what's over budget`: tasks over their estimate this month\n" +
+	"• `my time this week`: your own time, any period works\n" +
+	"• any `/oye` report, e.g. `project Website for last week summary`"
+
+// HandleBotQuestion answers a mention or direct message in the thread it was asked in
+func HandleBotQuestion(event SlackMessageEvent) {
+	logger := GetGlobalLogger()
+
+	threadTS := event.ThreadTS
+	if threadTS == "" {
+		threadTS = event.TS
+	}
+	reply := func(text string) {
+		if err := replyInThread(event.Channel, threadTS, text); err != nil {
+			logger.Errorf("Failed to reply to %s in %s: %v", event.User, event.Channel, err)
+		}
+	}
+
+	text := botQuestionText(event.Text)
+	logger.Infof("Bot question from %s in %s: %q", event.User, event.Channel, text)
+	if text == "" || strings.EqualFold(text, "help") {
+		reply(botHelpText)
+		return
+	}
+
+	if match := myTimeIntentPattern.FindStringSubmatch(text); match != nil {
+		period := strings.TrimSpace(match[1])
+		if period == "" {
+			period = "this week"
+		}
+		blocks, err := buildMyTimeBlocks(event.User, period)
+		if err != nil {
+			reply(formatOYEError(err))
+			return
+		}
+		if err := sendSlackMessage(event.Channel, blocks, threadTS); err != nil {
+			logger.Errorf("Failed to reply to %s in %s: %v", event.User, event.Channel, err)
+		}
+		return
+	}
+
+	commandText, isIntent := botIntentCommand(text)
+	if !isIntent {
+		if !isOYECommandStart(text) {
+			reply("Sorry, I didn't get that. " + botHelpText)
+			return
+		}
+		commandText = text
+	}
+
+	command, err := ParseOYECommand(commandText)
+	if err != nil {
+		reply(formatOYEError(err))
+		return
+	}
+	projectFilter, err := confirmProjects(command)
+	if err != nil {
+		reply(formatOYEError(err))
+		return
+	}
+
+	// Answers always go in the question's thread, a mention has no response_url to whisper through
+	command.Delivery = SLACK_DELIVERY_THREAD
+	req := &SlackCommandRequest{
+		ChannelID: event.Channel,
+		UserID:    event.User,
+		Text:      commandText,
+		ThreadTS:  threadTS,
+	}
+	runOYEReport(req, command, projectFilter)
+}
+
+// replyInThread posts a plain text reply in a thread
+func replyInThread(channel, threadTS, text string) error {
+	return sendSlackMessage(channel, []Block{SectionBlock(text)}, threadTS)
+}
+
+// timecampUserIDsForSlackUser finds the TimeCamp users matching a Slack user by email or name
+func timecampUserIDsForSlackUser(db *sql.DB, slackUserID string) ([]int, error) {
+	rows, err := db.Query(`
+		SELECT u.user_id
+		FROM users u
+		JOIN slack_users s ON s.slack_user_id = $1
+		WHERE (COALESCE(s.email, '') <> '' AND LOWER(u.username) = LOWER(s.email))
+		   OR (COALESCE(s.real_name, '') <> '' AND LOWER(u.display_name) = LOWER(s.real_name))
+		   OR (COALESCE(s.display_name, '') <> '' AND LOWER(u.display_name) = LOWER(s.display_name))`,
+		slackUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up TimeCamp user for %s: %w", slackUserID, err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan TimeCamp user: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// myTimeMaxProjects caps the rows of the my time table
+const myTimeMaxProjects = 40
+
+// buildMyTimeBlocks sums a Slack user's tracked time in a period per project, as a table.
+// The period is written as in a report, with or without the leading `for`.
+func buildMyTimeBlocks(slackUserID, period string) ([]Block, error) {
+	if !isOYECommandStart(period) {
+		period = "for " + period
+	}
+	command, err := ParseOYECommand(period)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	userIDs, err := timecampUserIDsForSlackUser(db, slackUserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return []Block{SectionBlock("I couldn't match your Slack account to a TimeCamp user. Your TimeCamp login email or name has to match your Slack profile.")}, nil
+	}
+
+	placeholders := make([]string, len(userIDs))
+	args := []interface{}{command.Start.Format("2006-01-02"), command.End.Format("2006-01-02")}
+	for i, id := range userIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+3)
+		args = append(args, id)
+	}
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT task_id, SUM(duration)
+		FROM time_entries
+		WHERE date >= $1 AND date <= $2 AND user_id IN (%s)
+		GROUP BY task_id`, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time entries: %w", err)
+	}
+	defer rows.Close()
+
+	allTasks, err := getAllTasks(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+
+	perProject := make(map[string]int)
+	total := 0
+	for rows.Next() {
+		var taskID, seconds int
+		if err := rows.Scan(&taskID, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan time entries: %w", err)
+		}
+		project := getProjectNameForTask(taskID, allTasks)
+		if project == "" {
+			project = "No project"
+		}
+		perProject[project] += seconds
+		total += seconds
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read time entries: %w", err)
+	}
+
+	if total == 0 {
+		return []Block{SectionBlock(fmt.Sprintf("You have no tracked time for %s.", command.Period))}, nil
+	}
+
+	projects := make([]string, 0, len(perProject))
+	for project := range perProject {
+		projects = append(projects, project)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if perProject[projects[i]] != perProject[projects[j]] {
+			return perProject[projects[i]] > perProject[projects[j]]
+		}
+		return projects[i] < projects[j]
+	})
+
+	title := fmt.Sprintf("*Your time for %s (%s – %s): %s*", command.Period,
+		command.Start.Format("Jan 2"), command.End.Format("Jan 2"), formatDuration(total))
+	table := make([][2]string, 0, min(len(projects), myTimeMaxProjects))
+	for _, project := range projects[:min(len(projects), myTimeMaxProjects)] {
+		table = append(table, [2]string{truncateUTF8(project, 200), formatDuration(perProject[project])})
+	}
+	blocks := FieldTableBlocks(title, table)
+	if len(projects) > myTimeMaxProjects {
+		blocks = append(blocks, ContextBlock(fmt.Sprintf("…and %d more projects", len(projects)-myTimeMaxProjects)))
+	}
+	return blocks, nil
+}
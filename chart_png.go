@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strings"
+)
+
+// BurnChart is a cumulative hours line over the days of a period,
+// drawn against horizontal reference lines such as the estimate
+type BurnChart struct {
+	Title      string
+	Days       []string  // YYYY-MM-DD, one per point
+	Cumulative []float64 // hours tracked up to and including each day
+	References []ChartReference
+}
+
+// ChartReference is a horizontal line at a number of hours
+type ChartReference struct {
+	Label  string
+	Hours  float64
+	Color  color.RGBA
+	Dashed bool
+}
+
+var (
+	chartBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	chartGrid       = color.RGBA{0xe8, 0xe8, 0xe8, 0xff}
+	chartText       = color.RGBA{0x61, 0x60, 0x61, 0xff}
+	chartTitle      = color.RGBA{0x1d, 0x1c, 0x1d, 0xff}
+	chartTracked    = color.RGBA{0x12, 0x64, 0xa3, 0xff}
+	chartPessimist  = color.RGBA{0xe0, 0x1e, 0x5a, 0xff}
+	chartOptimist   = color.RGBA{0xec, 0xb2, 0x2e, 0xff}
+)
+
+// Chart layout in pixels
+const (
+	chartWidth       = 800
+	chartHeight      = 420
+	chartMarginLeft  = 70
+	chartMarginRight = 30
+	chartMarginTop   = 50
+	chartMarginBot   = 80
+	chartTextScale   = 2
+)
+
+// RenderPNG draws the chart. Text uses a built-in bitmap font, so the chart needs no font files
+// and renders the same everywhere; letters are drawn in upper case.
+func (c BurnChart) RenderPNG() ([]byte, error) {
+	if len(c.Days) == 0 || len(c.Days) != len(c.Cumulative) {
+		return nil, fmt.Errorf("chart needs one value per day, got %d days and %d values", len(c.Days), len(c.Cumulative))
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{chartBackground}, image.Point{}, draw.Src)
+
+	left, right := chartMarginLeft, chartWidth-chartMarginRight
+	top, bottom := chartMarginTop, chartHeight-chartMarginBot
+
+	maxHours := 0.0
+	for _, hours := range c.Cumulative {
+		maxHours = math.Max(maxHours, hours)
+	}
+	for _, reference := range c.References {
+		maxHours = math.Max(maxHours, reference.Hours)
+	}
+	step := chartTickStep(maxHours * 1.1)
+	yMax := math.Max(step, math.Ceil(maxHours*1.1/step)*step)
+	y := func(hours float64) int {
+		return bottom - int(math.Round(hours/yMax*float64(bottom-top)))
+	}
+	x := func(index int) int {
+		if len(c.Days) == 1 {
+			return (left + right) / 2
+		}
+		return left + int(math.Round(float64(index)/float64(len(c.Days)-1)*float64(right-left)))
+	}
+
+	// Title, grid and axes
+	drawChartText(img, left, 16, fitChartText(c.Title, right-left), chartTitle)
+	for hours := 0.0; hours <= yMax+step/2; hours += step {
+		drawChartLine(img, left, y(hours), right, y(hours), chartGrid, 1, false)
+		label := chartNumber(hours)
+		drawChartText(img, left-10-chartTextWidth(label), y(hours)-chartTextScale*3, label, chartText)
+	}
+	drawChartText(img, left-10-chartTextWidth("HOURS"), top-chartTextScale*12, "HOURS", chartText)
+	drawChartLine(img, left, top, left, bottom, chartText, 1, false)
+	drawChartLine(img, left, bottom, right, bottom, chartText, 1, false)
+
+	labelEvery := int(math.Ceil(float64(len(c.Days)) / 8))
+	for i, day := range c.Days {
+		if i%labelEvery != 0 {
+			continue
+		}
+		drawChartLine(img, x(i), bottom, x(i), bottom+4, chartText, 1, false)
+		label := day[5:]
+		drawChartText(img, x(i)-chartTextWidth(label)/2, bottom+10, label, chartText)
+	}
+
+	// Reference lines under the tracked line, so it stays visible where they meet
+	for _, reference := range c.References {
+		drawChartLine(img, left, y(reference.Hours), right, y(reference.Hours), reference.Color, 2, reference.Dashed)
+	}
+	if len(c.Days) == 1 {
+		fillChartRect(img, x(0)-3, y(c.Cumulative[0])-3, x(0)+3, y(c.Cumulative[0])+3, chartTracked)
+	}
+	for i := 1; i < len(c.Days); i++ {
+		drawChartLine(img, x(i-1), y(c.Cumulative[i-1]), x(i), y(c.Cumulative[i]), chartTracked, 3, false)
+	}
+
+	// Legend
+	legendX, legendY := left, chartHeight-30
+	legend := append([]ChartReference{{Label: fmt.Sprintf("Tracked %sh", chartNumber(c.Cumulative[len(c.Cumulative)-1])), Color: chartTracked}}, c.References...)
+	for _, entry := range legend {
+		label := entry.Label
+		if entry.Hours > 0 {
+			label = fmt.Sprintf("%s %sh", entry.Label, chartNumber(entry.Hours))
+		}
+		fillChartRect(img, legendX, legendY+3, legendX+20, legendY+9, entry.Color)
+		drawChartText(img, legendX+28, legendY, label, chartText)
+		legendX += 28 + chartTextWidth(label) + 30
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// chartTickStep picks a round grid step giving at most six lines up to limit
+func chartTickStep(limit float64) float64 {
+	if limit <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(limit/6)))
+	for _, factor := range []float64{1, 2, 5, 10} {
+		if limit/(factor*magnitude) <= 6 {
+			return math.Max(factor*magnitude, 0.5)
+		}
+	}
+	return 10 * magnitude
+}
+
+// chartNumber formats hours without needless decimals
+func chartNumber(hours float64) string {
+	if hours == math.Trunc(hours) {
+		return fmt.Sprintf("%.0f", hours)
+	}
+	return fmt.Sprintf("%.1f", hours)
+}
+
+// drawChartLine draws a straight line of the given thickness, optionally dashed
+func drawChartLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA, thickness int, dashed bool) {
+	dx, dy := chartAbs(x1-x0), -chartAbs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for step := 0; ; step++ {
+		if !dashed || (step/8)%2 == 0 {
+			fillChartRect(img, x0-thickness/2, y0-thickness/2, x0-thickness/2+thickness, y0-thickness/2+thickness, c)
+		}
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func chartAbs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// fillChartRect fills the rectangle from (x0, y0) up to but excluding (x1, y1)
+func fillChartRect(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	draw.Draw(img, image.Rect(x0, y0, x1, y1), &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+// chartGlyphWidth is the advance of one character: five pixels and a gap, scaled
+const chartGlyphWidth = 6 * chartTextScale
+
+func chartTextWidth(text string) int {
+	return len([]rune(text)) * chartGlyphWidth
+}
+
+// fitChartText shortens text to fit a width, ending it with ".." when cut
+func fitChartText(text string, width int) string {
+	runes := []rune(text)
+	maxRunes := width / chartGlyphWidth
+	if len(runes) <= maxRunes {
+		return text
+	}
+	return string(runes[:maxRunes-2]) + ".."
+}
+
+// drawChartText draws text with its top left corner at (x, y)
+func drawChartText(img *image.RGBA, x, y int, text string, c color.RGBA) {
+	for _, r := range strings.ToUpper(text) {
+		glyph, ok := chartFont[chartFontFallback(r)]
+		if !ok {
+			glyph = chartFont['?']
+		}
+		for row, bits := range glyph {
+			for col := 0; col < 5; col++ {
+				if bits&(0x10>>col) != 0 {
+					fillChartRect(img, x+col*chartTextScale, y+row*chartTextScale, x+(col+1)*chartTextScale, y+(row+1)*chartTextScale, c)
+				}
+			}
+		}
+		x += chartGlyphWidth
+	}
+}
+
+// chartFontFallback maps letters the font lacks to the closest one it has, e.g. Polish diacritics
+func chartFontFallback(r rune) rune {
+	switch r {
+	case 'Ą', 'Á', 'À', 'Ä':
+		return 'A'
+	case 'Ć', 'Č':
+		return 'C'
+	case 'Ę', 'É', 'È', 'Ě':
+		return 'E'
+	case 'Ł':
+		return 'L'
+	case 'Ń', 'Ň':
+		return 'N'
+	case 'Ó', 'Ö':
+		return 'O'
+	case 'Ś', 'Š':
+		return 'S'
+	case 'Ź', 'Ż', 'Ž':
+		return 'Z'
+	case 'Ü', 'Ú':
+		return 'U'
+	case '–', '—':
+		return '-'
+	}
+	return r
+}
+
+// chartFont is a 5x7 bitmap font, one byte per row with the leftmost pixel in bit 4
+var chartFont = map[rune][7]byte{
+	' ':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	'0':  {0x0E, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0E},
+	'1':  {0x04, 0x0C, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'2':  {0x0E, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1F},
+	'3':  {0x1F, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0E},
+	'4':  {0x02, 0x06, 0x0A, 0x12, 0x1F, 0x02, 0x02},
+	'5':  {0x1F, 0x10, 0x1E, 0x01, 0x01, 0x11, 0x0E},
+	'6':  {0x06, 0x08, 0x10, 0x1E, 0x11, 0x11, 0x0E},
+	'7':  {0x1F, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8':  {0x0E, 0x11, 0x11, 0x0E, 0x11, 0x11, 0x0E},
+	'9':  {0x0E, 0x11, 0x11, 0x0F, 0x01, 0x02, 0x0C},
+	'A':  {0x0E, 0x11, 0x11, 0x11, 0x1F, 0x11, 0x11},
+	'B':  {0x1E, 0x11, 0x11, 0x1E, 0x11, 0x11, 0x1E},
+	'C':  {0x0E, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0E},
+	'D':  {0x1C, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1C},
+	'E':  {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x1F},
+	'F':  {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x10},
+	'G':  {0x0E, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0F},
+	'H':  {0x11, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11},
+	'I':  {0x0E, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'J':  {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0C},
+	'K':  {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L':  {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1F},
+	'M':  {0x11, 0x1B, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N':  {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O':  {0x0E, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'P':  {0x1E, 0x11, 0x11, 0x1E, 0x10, 0x10, 0x10},
+	'Q':  {0x0E, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0D},
+	'R':  {0x1E, 0x11, 0x11, 0x1E, 0x14, 0x12, 0x11},
+	'S':  {0x0F, 0x10, 0x10, 0x0E, 0x01, 0x01, 0x1E},
+	'T':  {0x1F, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'U':  {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'V':  {0x11, 0x11, 0x11, 0x11, 0x11, 0x0A, 0x04},
+	'W':  {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0A},
+	'X':  {0x11, 0x11, 0x0A, 0x04, 0x0A, 0x11, 0x11},
+	'Y':  {0x11, 0x11, 0x11, 0x0A, 0x04, 0x04, 0x04},
+	'Z':  {0x1F, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1F},
+	'.':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x0C, 0x0C},
+	',':  {0x00, 0x00, 0x00, 0x00, 0x0C, 0x04, 0x08},
+	':':  {0x00, 0x0C, 0x0C, 0x00, 0x0C, 0x0C, 0x00},
+	'-':  {0x00, 0x00, 0x00, 0x1F, 0x00, 0x00, 0x00},
+	'_':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F},
+	'+':  {0x00, 0x04, 0x04, 0x1F, 0x04, 0x04, 0x00},
+	'%':  {0x18, 0x19, 0x02, 0x04, 0x08, 0x13, 0x03},
+	'/':  {0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00},
+	'(':  {0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02},
+	')':  {0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08},
+	'[':  {0x0E, 0x08, 0x08, 0x08, 0x08, 0x08, 0x0E},
+	']':  {0x0E, 0x02, 0x02, 0x02, 0x02, 0x02, 0x0E},
+	'#':  {0x0A, 0x0A, 0x1F, 0x0A, 0x1F, 0x0A, 0x0A},
+	'&':  {0x0C, 0x12, 0x14, 0x08, 0x15, 0x12, 0x0D},
+	'\'': {0x04, 0x04, 0x08, 0x00, 0x00, 0x00, 0x00},
+	'"':  {0x0A, 0x0A, 0x00, 0x00, 0x00, 0x00, 0x00},
+	'!':  {0x04, 0x04, 0x04, 0x04, 0x04, 0x00, 0x04},
+	'?':  {0x0E, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04},
+}
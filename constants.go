@@ -38,12 +38,93 @@ const (
 	THRESHOLD_OVER     = 100.0
 )
 
+// Project assignment roles
+const (
+	PROJECT_ROLE_MEMBER = "member"
+	PROJECT_ROLE_OWNER  = "owner"
+)
+
+// Escalation recipients
+const (
+	ESCALATE_TO_ASSIGNEES = "assignees"
+	ESCALATE_TO_OWNER     = "owner"
+	ESCALATE_TO_CHANNEL   = "channel"
+)
+
 // Slack Limits
 const (
 	MAX_SLACK_BLOCKS         = 50
 	MAX_SLACK_MESSAGE_CHARS  = 3000
 	MAX_BLOCKS_PER_MESSAGE   = 47   // Leave buffer for header/footer
 	MAX_MESSAGE_CHARS_BUFFER = 2900 // Leave buffer for safety
+
+	MAX_HOME_VIEW_BLOCKS         = 100  // Slack's limit for a home tab view
+	MAX_SECTION_TEXT_CHARS       = 3000 // Slack's limit for a section's text
+	MAX_STATIC_SELECT_OPTIONS    = 100  // larger menus load options from the options load URL
+	APP_HOME_OWNERS_PER_PAGE     = 10
+	PROJECT_ASSIGNMENT_ACTION_ID = "project_assignments_select"
+)
+
+// Block Kit Limits, checked before a payload is sent
+const (
+	MAX_MODAL_BLOCKS       = 100
+	MAX_MESSAGE_TEXT_CHARS = 40000 // top-level text of a message
+	MAX_HEADER_TEXT_CHARS  = 150
+	MAX_SECTION_FIELDS     = 10
+	MAX_FIELD_TEXT_CHARS   = 2000
+	MAX_CONTEXT_ELEMENTS   = 10
+	MAX_ACTIONS_ELEMENTS   = 25
+	MAX_BLOCK_ID_CHARS     = 255 // also the limit for action IDs
+	MAX_BUTTON_TEXT_CHARS  = 75
+	MAX_BUTTON_VALUE_CHARS = 2000
+	MAX_OPTION_TEXT_CHARS  = 75
+	MAX_OPTION_VALUE_CHARS = 150
+	MAX_OPTION_GROUPS      = 100
+	MAX_PLACEHOLDER_CHARS  = 150
+	MAX_INPUT_LABEL_CHARS  = 2000
+	MAX_VIEW_TITLE_CHARS   = 24 // also the limit for modal submit and close buttons
+)
+
+// Mattermost and Teams Limits
+const (
+	MAX_MATTERMOST_POST_CHARS = 15000 // Mattermost rejects posts over 16383 characters by default
+	MAX_TEAMS_PAYLOAD_BYTES   = 25000 // Teams incoming webhooks reject payloads over ~28 KB
+)
+
+// Report sort keys
+const (
+	REPORT_SORT_NAME    = "name"
+	REPORT_SORT_TIME    = "time"
+	REPORT_SORT_PERCENT = "percent"
+
+	DEFAULT_SUMMARY_THRESHOLD = 100.0 // usage percentage counted as over in summaries
+)
+
+// Slack report delivery modes
+const (
+	SLACK_DELIVERY_THREAD  = "thread"  // header post in the channel, report in its thread
+	SLACK_DELIVERY_SHARE   = "share"   // report posted in the channel itself
+	SLACK_DELIVERY_PRIVATE = "private" // ephemeral report only the requester sees
+
+	SLACK_RESPONSE_URL_MAX_POSTS = 5 // Slack accepts up to 5 posts per slash command response_url
+)
+
+// Report export formats
+const (
+	EXPORT_FORMAT_CSV  = "csv"
+	EXPORT_FORMAT_XLSX = "xlsx"
+)
+
+// Report message interactivity
+const (
+	REPORT_ACTION_PREFIX        = "oye_report_"
+	REPORT_ACTION_REFRESH       = "oye_report_refresh"
+	REPORT_ACTION_COMMENTS      = "oye_report_comments"
+	REPORT_ACTION_BREAKDOWN     = "oye_report_breakdown"
+	REPORT_PROJECT_BLOCK_PREFIX = "oye_project|"
+	REPORT_ACTIONS_BLOCK_PREFIX = "oye_actions|"
+	REPORT_REFRESHED_BLOCK_ID   = "oye_refreshed"
+	REPORT_CONTINUED_BLOCK_ID   = "oye_continued"
 )
 
 // Default configuration values
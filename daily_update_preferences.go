@@ -0,0 +1,369 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Per-user daily update settings, edited from the App Home. A tick job checks
+// every few minutes whose delivery time has passed in their own timezone.
+
+const DAILY_UPDATE_SETTINGS_CALLBACK_ID = "daily_update_settings_modal"
+
+// defaultDailyUpdateMaxAttempts is how many ticks try to send a user's update before giving up for the day
+const defaultDailyUpdateMaxAttempts = 3
+
+var dailyUpdateTimePattern = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+// DailyUpdatePreferences are a user's daily update settings
+type DailyUpdatePreferences struct {
+	UserID            string
+	Enabled           bool
+	DeliveryTime      string // "HH:MM" local time; empty uses DAILY_UPDATE_DEFAULT_TIME
+	WeekdaysOnly      bool
+	OnlyOverThreshold bool    // skip the update unless a task reached Threshold
+	Threshold         float64 // percent of the estimate
+	Timezone          string  // IANA name chosen by the user; empty uses ProfileTimezone
+	ProfileTimezone   string  // from the Slack profile
+	LastSent          string  // local date of the last update, "2006-01-02"
+}
+
+// defaultDailyUpdatePreferences are the settings of users who never changed them
+func defaultDailyUpdatePreferences(userID, profileTimezone string) DailyUpdatePreferences {
+	return DailyUpdatePreferences{
+		UserID:          userID,
+		Enabled:         true,
+		Threshold:       DEFAULT_SUMMARY_THRESHOLD,
+		ProfileTimezone: profileTimezone,
+	}
+}
+
+// defaultDailyUpdateTime is the delivery time for users who didn't pick one
+func defaultDailyUpdateTime() string {
+	if value := os.Getenv("DAILY_UPDATE_DEFAULT_TIME"); dailyUpdateTimePattern.MatchString(value) {
+		return value
+	}
+	return "06:00"
+}
+
+// EffectiveDeliveryTime returns the local "HH:MM" the update is sent at
+func (p DailyUpdatePreferences) EffectiveDeliveryTime() string {
+	if p.DeliveryTime != "" {
+		return p.DeliveryTime
+	}
+	return defaultDailyUpdateTime()
+}
+
+// EffectiveTimezone returns the timezone name the update is scheduled in
+func (p DailyUpdatePreferences) EffectiveTimezone() string {
+	if p.Timezone != "" {
+		return p.Timezone
+	}
+	if p.ProfileTimezone != "" {
+		return p.ProfileTimezone
+	}
+	return time.Local.String()
+}
+
+// Location returns the user's timezone, falling back to the server's
+func (p DailyUpdatePreferences) Location() *time.Location {
+	for _, name := range []string{p.Timezone, p.ProfileTimezone} {
+		if name == "" {
+			continue
+		}
+		if location, err := time.LoadLocation(name); err == nil {
+			return location
+		}
+	}
+	return time.Local
+}
+
+// DueDate returns the user's local date when their update should go out at now, or "" if it isn't due
+func (p DailyUpdatePreferences) DueDate(now time.Time) string {
+	if !p.Enabled {
+		return ""
+	}
+
+	local := now.In(p.Location())
+	if p.WeekdaysOnly && (local.Weekday() == time.Saturday || local.Weekday() == time.Sunday) {
+		return ""
+	}
+
+	today := local.Format("2006-01-02")
+	if p.LastSent == today || local.Format("15:04") < p.EffectiveDeliveryTime() {
+		return ""
+	}
+	return today
+}
+
+// Description summarizes the settings for the App Home
+func (p DailyUpdatePreferences) Description() string {
+	if !p.Enabled {
+		return "_Off_"
+	}
+
+	days := "every day"
+	if p.WeekdaysOnly {
+		days = "on weekdays"
+	}
+	text := fmt.Sprintf("*On*, %s at %s (%s)", days, p.EffectiveDeliveryTime(), p.EffectiveTimezone())
+	if p.OnlyOverThreshold {
+		text += fmt.Sprintf(", only when a task is over %.0f%%", p.Threshold)
+	}
+	return text
+}
+
+const dailyUpdatePreferencesColumns = `
+	su.slack_user_id, su.tz,
+	up.daily_update_enabled, up.daily_update_time, up.weekdays_only,
+	up.only_over_threshold, up.threshold, up.timezone, up.last_daily_update`
+
+// scanDailyUpdatePreferences scans a slack_users row LEFT JOINed with user_preferences
+func scanDailyUpdatePreferences(scanner interface{ Scan(...interface{}) error }) (DailyUpdatePreferences, error) {
+	var userID, profileTimezone string
+	var enabled, weekdaysOnly, onlyOverThreshold sql.NullBool
+	var deliveryTime, timezone sql.NullString
+	var threshold sql.NullFloat64
+	var lastSent sql.NullTime
+
+	if err := scanner.Scan(&userID, &profileTimezone, &enabled, &deliveryTime, &weekdaysOnly,
+		&onlyOverThreshold, &threshold, &timezone, &lastSent); err != nil {
+		return DailyUpdatePreferences{}, fmt.Errorf("failed to scan user preferences: %w", err)
+	}
+
+	prefs := defaultDailyUpdatePreferences(userID, profileTimezone)
+	if !enabled.Valid {
+		// No saved preferences yet
+		return prefs, nil
+	}
+	prefs.Enabled = enabled.Bool
+	prefs.DeliveryTime = deliveryTime.String
+	prefs.WeekdaysOnly = weekdaysOnly.Bool
+	prefs.OnlyOverThreshold = onlyOverThreshold.Bool
+	prefs.Threshold = threshold.Float64
+	prefs.Timezone = timezone.String
+	if lastSent.Valid {
+		prefs.LastSent = lastSent.Time.Format("2006-01-02")
+	}
+	return prefs, nil
+}
+
+// GetDailyUpdatePreferences returns a user's daily update settings, or the defaults
+func GetDailyUpdatePreferences(db *sql.DB, userID string) (DailyUpdatePreferences, error) {
+	row := db.QueryRow(`SELECT `+dailyUpdatePreferencesColumns+`
+		FROM slack_users su
+		LEFT JOIN user_preferences up ON up.slack_user_id = su.slack_user_id
+		WHERE su.slack_user_id = $1`, userID)
+
+	prefs, err := scanDailyUpdatePreferences(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Not synced from Slack yet
+		return defaultDailyUpdatePreferences(userID, ""), nil
+	}
+	return prefs, err
+}
+
+// GetAllDailyUpdatePreferences returns the settings of every active Slack user, keyed by user ID
+func GetAllDailyUpdatePreferences(db *sql.DB) (map[string]DailyUpdatePreferences, error) {
+	rows, err := db.Query(`SELECT ` + dailyUpdatePreferencesColumns + `
+		FROM slack_users su
+		LEFT JOIN user_preferences up ON up.slack_user_id = su.slack_user_id
+		WHERE su.deleted = false AND su.is_bot = false`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user preferences: %w", err)
+	}
+	defer rows.Close()
+
+	preferences := make(map[string]DailyUpdatePreferences)
+	for rows.Next() {
+		prefs, err := scanDailyUpdatePreferences(rows)
+		if err != nil {
+			return nil, err
+		}
+		preferences[prefs.UserID] = prefs
+	}
+	return preferences, rows.Err()
+}
+
+// SaveDailyUpdatePreferences stores a user's settings, keeping the last delivery date
+func SaveDailyUpdatePreferences(db *sql.DB, prefs DailyUpdatePreferences) error {
+	_, err := db.Exec(`
+		INSERT INTO user_preferences (slack_user_id, daily_update_enabled, daily_update_time, weekdays_only,
+			only_over_threshold, threshold, timezone, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)
+		ON CONFLICT (slack_user_id) DO UPDATE SET
+			daily_update_enabled = EXCLUDED.daily_update_enabled,
+			daily_update_time = EXCLUDED.daily_update_time,
+			weekdays_only = EXCLUDED.weekdays_only,
+			only_over_threshold = EXCLUDED.only_over_threshold,
+			threshold = EXCLUDED.threshold,
+			timezone = EXCLUDED.timezone,
+			updated_at = CURRENT_TIMESTAMP`,
+		prefs.UserID, prefs.Enabled, prefs.DeliveryTime, prefs.WeekdaysOnly,
+		prefs.OnlyOverThreshold, prefs.Threshold, prefs.Timezone)
+	if err != nil {
+		return fmt.Errorf("failed to save preferences for user %s: %w", prefs.UserID, err)
+	}
+	return nil
+}
+
+// markDailyUpdateSent records the local date a user's update was handled for
+func markDailyUpdateSent(db *sql.DB, userID, localDate string) error {
+	_, err := db.Exec(`
+		INSERT INTO user_preferences (slack_user_id, last_daily_update)
+		VALUES ($1, $2)
+		ON CONFLICT (slack_user_id) DO UPDATE SET last_daily_update = EXCLUDED.last_daily_update`,
+		userID, localDate)
+	if err != nil {
+		return fmt.Errorf("failed to record daily update for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// recordDailyUpdateFailure counts a failed send for the user's local date. Once the
+// update failed DAILY_UPDATE_MAX_ATTEMPTS times that day it's recorded as handled,
+// so it isn't retried on every tick. Returns whether it will be retried.
+func recordDailyUpdateFailure(db *sql.DB, userID, localDate string) (bool, error) {
+	var failures int
+	err := db.QueryRow(`
+		INSERT INTO user_preferences (slack_user_id, daily_update_failures, daily_update_failed_on)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (slack_user_id) DO UPDATE SET
+			daily_update_failures = CASE
+				WHEN user_preferences.daily_update_failed_on = EXCLUDED.daily_update_failed_on
+				THEN user_preferences.daily_update_failures + 1 ELSE 1 END,
+			daily_update_failed_on = EXCLUDED.daily_update_failed_on
+		RETURNING daily_update_failures`,
+		userID, localDate).Scan(&failures)
+	if err != nil {
+		return false, fmt.Errorf("failed to record daily update failure for user %s: %w", userID, err)
+	}
+
+	if failures < getEnvInt("DAILY_UPDATE_MAX_ATTEMPTS", defaultDailyUpdateMaxAttempts) {
+		return true, nil
+	}
+	return false, markDailyUpdateSent(db, userID, localDate)
+}
+
+// hasTaskOverThreshold reports whether any estimated task in the report reached threshold percent
+func hasTaskOverThreshold(report Report, threshold float64) bool {
+	for _, project := range report.Projects {
+		for _, task := range project.Tasks {
+			if task.Estimated && task.Percentage >= threshold {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// OpenDailyUpdateSettingsModal opens the daily update settings for a user
+func OpenDailyUpdateSettingsModal(triggerID, userID string) error {
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	prefs, err := GetDailyUpdatePreferences(db, userID)
+	if err != nil {
+		return err
+	}
+
+	options := []Option{
+		NewOption("Send me a daily update", "enabled"),
+		NewOption("Weekdays only", "weekdays_only"),
+		NewOption("Only when a task is over the threshold", "only_over_threshold"),
+	}
+	checkboxes := NewSelect("checkboxes", "options_value", "", options...)
+	for i, on := range []bool{prefs.Enabled, prefs.WeekdaysOnly, prefs.OnlyOverThreshold} {
+		if on {
+			checkboxes.InitialOptions = append(checkboxes.InitialOptions, options[i])
+		}
+	}
+
+	profileTimezone := prefs.ProfileTimezone
+	if profileTimezone == "" {
+		profileTimezone = time.Local.String()
+	}
+
+	deliveryTime := NewPicker("timepicker", "delivery_time_value")
+	deliveryTime.InitialTime = prefs.EffectiveDeliveryTime()
+
+	thresholdInput := NewPlainTextInput("threshold_value", "")
+	thresholdInput.InitialValue = strconv.FormatFloat(prefs.Threshold, 'f', -1, 64)
+
+	// An empty initial value is left out, Slack rejects it
+	timezoneInput := NewPlainTextInput("timezone_value", "e.g. Europe/Warsaw")
+	timezoneInput.InitialValue = prefs.Timezone
+
+	modal := NewModal(DAILY_UPDATE_SETTINGS_CALLBACK_ID, "Daily Update", "Save",
+		InputBlock("options", "Daily update", checkboxes).AsOptional(),
+		InputBlock("delivery_time", "Delivery time", deliveryTime),
+		InputBlock("threshold", "Threshold (% of estimate)", thresholdInput),
+		InputBlock("timezone", "Timezone", timezoneInput).AsOptional().
+			WithHint(fmt.Sprintf("Leave empty to use your Slack profile timezone (%s)", profileTimezone)),
+	)
+
+	return NewSlackAPIClient().sendSlackAPIRequest("views.open", ViewRequest{TriggerID: triggerID, View: modal})
+}
+
+// HandleDailyUpdateSettingsSubmission validates and saves the daily update settings modal
+func HandleDailyUpdateSettingsSubmission(payload SlackInteractivePayload) error {
+	logger := GetGlobalLogger()
+	values := payload.View.State.Values
+
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	prefs, err := GetDailyUpdatePreferences(db, payload.User.ID)
+	if err != nil {
+		return err
+	}
+
+	checked := make(map[string]bool)
+	for _, option := range values["options"]["options_value"].SelectedOptions {
+		checked[option.Value] = true
+	}
+	prefs.Enabled = checked["enabled"]
+	prefs.WeekdaysOnly = checked["weekdays_only"]
+	prefs.OnlyOverThreshold = checked["only_over_threshold"]
+
+	deliveryTime := values["delivery_time"]["delivery_time_value"].SelectedTime
+	if !dailyUpdateTimePattern.MatchString(deliveryTime) {
+		return &ModalValidationError{BlockID: "delivery_time", Message: "Pick a delivery time"}
+	}
+	prefs.DeliveryTime = deliveryTime
+
+	thresholdText := strings.TrimSuffix(strings.TrimSpace(values["threshold"]["threshold_value"].Value), "%")
+	threshold, err := strconv.ParseFloat(thresholdText, 64)
+	if err != nil || threshold <= 0 {
+		return &ModalValidationError{BlockID: "threshold", Message: "Enter a percentage above 0, e.g. 100"}
+	}
+	prefs.Threshold = threshold
+
+	timezone := strings.TrimSpace(values["timezone"]["timezone_value"].Value)
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return &ModalValidationError{BlockID: "timezone", Message: "Unknown timezone, use a name like Europe/Warsaw or America/New_York"}
+		}
+	}
+	prefs.Timezone = timezone
+
+	if err := SaveDailyUpdatePreferences(db, prefs); err != nil {
+		return err
+	}
+	logger.Infof("User %s saved daily update settings: %s", payload.User.ID, prefs.Description())
+
+	if err := PublishAppHomeView(payload.User.ID); err != nil {
+		logger.Errorf("Failed to refresh app home view: %v", err)
+	}
+	return nil
+}
@@ -15,10 +15,11 @@ import (
 var (
 	globalDB *sql.DB
 	dbMutex  sync.RWMutex
-	dbOnce   sync.Once
-	initErr  error
 )
 
+// SCHEMA_VERSION is the number of the latest migration in runDatabaseMigrations
+const SCHEMA_VERSION = 5
+
 func getDBConnectionString() string {
 	logger := GetGlobalLogger()
 
@@ -74,63 +75,82 @@ func validateDatabaseWriteAccess() error {
 }
 
 func GetDB() (*sql.DB, error) {
-	dbOnce.Do(func() {
-		logger := GetGlobalLogger()
-		connStr := getDBConnectionString()
-		if connStr == "" {
-			initErr = fmt.Errorf("database connection string not configured")
-			return
-		}
+	dbMutex.RLock()
+	db := globalDB
+	dbMutex.RUnlock()
+	if db != nil {
+		return db, nil
+	}
 
-		db, err := sql.Open("postgres", connStr)
-		if err != nil {
-			initErr = fmt.Errorf("failed to open database connection: %w", err)
-			return
-		}
+	// Connect under the write lock so concurrent callers wait for one attempt.
+	// A failure isn't kept, the next call tries again.
+	dbMutex.Lock()
+	defer dbMutex.Unlock()
+	if globalDB != nil {
+		return globalDB, nil
+	}
+	db, err := openDatabase()
+	if err != nil {
+		return nil, err
+	}
+	globalDB = db
+	return db, nil
+}
 
-		db.SetConnMaxLifetime(5 * time.Minute)
-		db.SetMaxOpenConns(25)
-		db.SetMaxIdleConns(10)
-		db.SetConnMaxIdleTime(90 * time.Second)
-
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		if err := db.PingContext(ctx); err != nil {
-			db.Close()
-			if strings.Contains(err.Error(), "network is unreachable") && strings.Contains(err.Error(), "dial tcp [") {
-				initErr = fmt.Errorf("IPv6 connectivity issue detected: %w", err)
-			} else {
-				initErr = fmt.Errorf("failed to ping database: %w", err)
-			}
-			return
-		}
+// openDatabase connects to the database and creates or migrates its tables
+func openDatabase() (*sql.DB, error) {
+	logger := GetGlobalLogger()
+	connStr := getDBConnectionString()
+	if connStr == "" {
+		return nil, fmt.Errorf("database connection string not configured")
+	}
 
-		if err := createAllTables(db); err != nil {
-			db.Close()
-			initErr = fmt.Errorf("failed to create tables: %w", err)
-			return
-		}
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
 
-		logger.Info("Database connection established and tables created")
+	db.SetConnMaxLifetime(5 * time.Minute)
+	db.SetMaxOpenConns(25)
+	db.SetMaxIdleConns(10)
+	db.SetConnMaxIdleTime(90 * time.Second)
 
-		dbMutex.Lock()
-		globalDB = db
-		dbMutex.Unlock()
-	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	if initErr != nil {
-		return nil, initErr
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		if strings.Contains(err.Error(), "network is unreachable") && strings.Contains(err.Error(), "dial tcp [") {
+			return nil, fmt.Errorf("IPv6 connectivity issue detected: %w", err)
+		}
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	dbMutex.RLock()
-	defer dbMutex.RUnlock()
-
-	if globalDB == nil {
-		return nil, fmt.Errorf("database connection not initialized")
+	if err := createAllTables(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create tables: %w", err)
 	}
 
-	return globalDB, nil
+	logger.Info("Database connection established and tables created")
+	return db, nil
+}
+
+// waitForDB connects to the database at startup, retrying while it isn't reachable yet
+func waitForDB(config RetryConfig) (*sql.DB, error) {
+	logger := GetGlobalLogger()
+	waitTime := config.InitialWait
+	for attempt := 0; ; attempt++ {
+		db, err := GetDB()
+		if err == nil || attempt >= config.MaxRetries {
+			return db, err
+		}
+		logger.Warnf("Database not available (attempt %d/%d), retrying in %v: %v", attempt+1, config.MaxRetries+1, waitTime, err)
+		time.Sleep(waitTime)
+		waitTime = time.Duration(float64(waitTime) * config.Multiplier)
+		if waitTime > config.MaxWait {
+			waitTime = config.MaxWait
+		}
+	}
 }
 
 func createAllTables(db *sql.DB) error {
@@ -145,7 +165,23 @@ func createAllTables(db *sql.DB) error {
 		{"projects", createProjectsTable},
 		{"user_project_assignments", createUserProjectAssignmentsTable},
 		{"threshold_notifications", createThresholdNotificationsTable},
+		{"escalation_notifications", createEscalationNotificationsTable},
+		{"unestimated_task_alerts", createUnestimatedTaskAlertsTable},
 		{"slack_users", createSlackUsersTable},
+		{"webhook_subscriptions", createWebhookSubscriptionsTable},
+		{"webhook_deliveries", createWebhookDeliveriesTable},
+		{"email_digest_subscriptions", createEmailDigestSubscriptionsTable},
+		{"project_groups", createProjectGroupsTable},
+		{"project_group_members", createProjectGroupMembersTable},
+		{"report_subscriptions", createReportSubscriptionsTable},
+		{"user_preferences", createUserPreferencesTable},
+		{"processed_slack_events", createProcessedSlackEventsTable},
+		{"slack_outbox", createSlackOutboxTable},
+		{"recurring_digests", createRecurringDigestsTable},
+		{"task_alert_threads", createTaskAlertThreadsTable},
+		{"api_tokens", createAPITokensTable},
+		{"cron_job_status", createCronJobStatusTable},
+		{"schema_version", createSchemaVersionTable},
 	}
 
 	for _, table := range tables {
@@ -253,6 +289,7 @@ func createUserProjectAssignmentsTable(db *sql.DB) error {
 		id SERIAL PRIMARY KEY,
 		slack_user_id TEXT NOT NULL,
 		project_id INTEGER NOT NULL,
+		role TEXT NOT NULL DEFAULT 'member',
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		FOREIGN KEY (project_id) REFERENCES projects(id),
@@ -279,6 +316,37 @@ func createThresholdNotificationsTable(db *sql.DB) error {
 	return err
 }
 
+func createEscalationNotificationsTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS escalation_notifications (
+		id SERIAL PRIMARY KEY,
+		task_id INTEGER NOT NULL,
+		threshold_percentage INTEGER NOT NULL,
+		current_percentage DECIMAL(7,2) NOT NULL,
+		recipients TEXT,
+		notified_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (task_id) REFERENCES tasks(task_id),
+		UNIQUE(task_id, threshold_percentage)
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createUnestimatedTaskAlertsTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS unestimated_task_alerts (
+		id SERIAL PRIMARY KEY,
+		task_id INTEGER NOT NULL,
+		hours_step INTEGER NOT NULL,
+		total_hours DECIMAL(10,2) NOT NULL,
+		notified_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (task_id) REFERENCES tasks(task_id),
+		UNIQUE(task_id, hours_step)
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
 type project struct {
 	taskID int
 	name   string
@@ -396,6 +464,213 @@ func createSlackUsersTable(db *sql.DB) error {
 	return err
 }
 
+func createWebhookSubscriptionsTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+		id SERIAL PRIMARY KEY,
+		url TEXT NOT NULL,
+		event_types TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		active BOOLEAN DEFAULT TRUE,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createWebhookDeliveriesTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id SERIAL PRIMARY KEY,
+		subscription_id INTEGER NOT NULL,
+		event_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status_code INTEGER,
+		success BOOLEAN NOT NULL,
+		error TEXT,
+		duration_ms INTEGER,
+		delivered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id) ON DELETE CASCADE
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createEmailDigestSubscriptionsTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS email_digest_subscriptions (
+		id SERIAL PRIMARY KEY,
+		email TEXT NOT NULL,
+		slack_user_id TEXT UNIQUE,
+		project_id INTEGER,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createProjectGroupsTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS project_groups (
+		id SERIAL PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		created_by TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createProjectGroupMembersTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS project_group_members (
+		group_id INTEGER NOT NULL,
+		project_id INTEGER NOT NULL,
+		PRIMARY KEY (group_id, project_id),
+		FOREIGN KEY (group_id) REFERENCES project_groups(id) ON DELETE CASCADE,
+		FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createReportSubscriptionsTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS report_subscriptions (
+		id SERIAL PRIMARY KEY,
+		created_by TEXT NOT NULL,
+		target_id TEXT NOT NULL,
+		cron_schedule TEXT NOT NULL,
+		schedule_description TEXT NOT NULL,
+		command TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createUserPreferencesTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS user_preferences (
+		slack_user_id TEXT PRIMARY KEY,
+		daily_update_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+		daily_update_time TEXT NOT NULL DEFAULT '',
+		weekdays_only BOOLEAN NOT NULL DEFAULT FALSE,
+		only_over_threshold BOOLEAN NOT NULL DEFAULT FALSE,
+		threshold DECIMAL(10,2) NOT NULL DEFAULT 100,
+		timezone TEXT NOT NULL DEFAULT '',
+		last_daily_update DATE,
+		daily_update_failures INTEGER NOT NULL DEFAULT 0,
+		daily_update_failed_on DATE,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createProcessedSlackEventsTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS processed_slack_events (
+		event_id TEXT PRIMARY KEY,
+		received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createSlackOutboxTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS slack_outbox (
+		id SERIAL PRIMARY KEY,
+		channel TEXT NOT NULL,
+		thread_ts TEXT NOT NULL DEFAULT '',
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT,
+		next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		sent_at TIMESTAMP,
+		message_ts TEXT NOT NULL DEFAULT ''
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createRecurringDigestsTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS recurring_digests (
+		recipient TEXT NOT NULL,
+		digest TEXT NOT NULL,
+		period TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		message_ts TEXT NOT NULL,
+		reply_outbox_ids INTEGER[] NOT NULL DEFAULT '{}',
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (recipient, digest, period)
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createTaskAlertThreadsTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS task_alert_threads (
+		slack_user_id TEXT NOT NULL,
+		task_id INTEGER NOT NULL,
+		channel TEXT NOT NULL,
+		message_ts TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (slack_user_id, task_id)
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createAPITokensTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS api_tokens (
+		id SERIAL PRIMARY KEY,
+		name TEXT NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_used_at TIMESTAMP,
+		revoked_at TIMESTAMP
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createCronJobStatusTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS cron_job_status (
+		job_name TEXT PRIMARY KEY,
+		last_run_at TIMESTAMP NOT NULL,
+		last_duration_ms BIGINT NOT NULL,
+		last_error TEXT,
+		last_success_at TIMESTAMP,
+		runs INTEGER NOT NULL DEFAULT 0,
+		failures INTEGER NOT NULL DEFAULT 0
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// createSchemaVersionTable holds a single row with the number of the latest migration run
+func createSchemaVersionTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS schema_version (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		version INTEGER NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
 // runDatabaseMigrations handles schema migrations for existing databases
 func runDatabaseMigrations(db *sql.DB) error {
 	logger := GetGlobalLogger()
@@ -404,6 +679,33 @@ func runDatabaseMigrations(db *sql.DB) error {
 	if err := addTaskTimeColumns(db); err != nil {
 		return fmt.Errorf("failed to add time columns to tasks table: %w", err)
 	}
+
+	// Migration 002: Add role column to user_project_assignments table
+	if err := addAssignmentRoleColumn(db); err != nil {
+		return fmt.Errorf("failed to add role column to user_project_assignments table: %w", err)
+	}
+
+	// Migration 003: Add tz column to slack_users table
+	if err := addSlackUserTimezoneColumn(db); err != nil {
+		return fmt.Errorf("failed to add tz column to slack_users table: %w", err)
+	}
+
+	// Migration 004: Add message_ts column to slack_outbox table
+	if err := addSlackOutboxMessageTSColumn(db); err != nil {
+		return fmt.Errorf("failed to add message_ts column to slack_outbox table: %w", err)
+	}
+
+	// Migration 005: Add daily update failure columns to user_preferences table
+	if err := addDailyUpdateFailureColumns(db); err != nil {
+		return fmt.Errorf("failed to add daily update failure columns to user_preferences table: %w", err)
+	}
+
+	// Readiness checks compare this with SCHEMA_VERSION
+	if _, err := db.Exec(`INSERT INTO schema_version (id, version) VALUES (1, $1)
+		ON CONFLICT (id) DO UPDATE SET version = EXCLUDED.version, updated_at = CURRENT_TIMESTAMP
+		WHERE schema_version.version < EXCLUDED.version`, SCHEMA_VERSION); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
 	
 	logger.Debug("Database migrations completed successfully")
 	return nil
@@ -452,6 +754,95 @@ func addTaskTimeColumns(db *sql.DB) error {
 	return nil
 }
 
+// addAssignmentRoleColumn adds the role column to user_project_assignments if it doesn't exist
+func addAssignmentRoleColumn(db *sql.DB) error {
+	logger := GetGlobalLogger()
+
+	var exists bool
+	checkQuery := `SELECT EXISTS (SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'user_project_assignments' AND column_name = 'role')`
+	if err := db.QueryRow(checkQuery).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check existing columns: %w", err)
+	}
+
+	if !exists {
+		alterQuery := `ALTER TABLE user_project_assignments ADD COLUMN role TEXT NOT NULL DEFAULT 'member'`
+		if _, err := db.Exec(alterQuery); err != nil {
+			return fmt.Errorf("failed to add role column: %w", err)
+		}
+		logger.Debug("Added role column to user_project_assignments table")
+	}
+
+	return nil
+}
+
+// addSlackUserTimezoneColumn adds the tz column holding the Slack profile timezone to slack_users if it doesn't exist
+func addSlackUserTimezoneColumn(db *sql.DB) error {
+	logger := GetGlobalLogger()
+
+	var exists bool
+	checkQuery := `SELECT EXISTS (SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'slack_users' AND column_name = 'tz')`
+	if err := db.QueryRow(checkQuery).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check existing columns: %w", err)
+	}
+
+	if !exists {
+		alterQuery := `ALTER TABLE slack_users ADD COLUMN tz TEXT NOT NULL DEFAULT ''`
+		if _, err := db.Exec(alterQuery); err != nil {
+			return fmt.Errorf("failed to add tz column: %w", err)
+		}
+		logger.Debug("Added tz column to slack_users table")
+	}
+
+	return nil
+}
+
+func addSlackOutboxMessageTSColumn(db *sql.DB) error {
+	logger := GetGlobalLogger()
+
+	var exists bool
+	checkQuery := `SELECT EXISTS (SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'slack_outbox' AND column_name = 'message_ts')`
+	if err := db.QueryRow(checkQuery).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check existing columns: %w", err)
+	}
+
+	if !exists {
+		alterQuery := `ALTER TABLE slack_outbox ADD COLUMN message_ts TEXT NOT NULL DEFAULT ''`
+		if _, err := db.Exec(alterQuery); err != nil {
+			return fmt.Errorf("failed to add message_ts column: %w", err)
+		}
+		logger.Debug("Added message_ts column to slack_outbox table")
+	}
+
+	return nil
+}
+
+// addDailyUpdateFailureColumns adds the columns counting failed daily update sends if they don't exist
+func addDailyUpdateFailureColumns(db *sql.DB) error {
+	logger := GetGlobalLogger()
+
+	var exists bool
+	checkQuery := `SELECT EXISTS (SELECT 1 FROM information_schema.columns
+		WHERE table_name = 'user_preferences' AND column_name = 'daily_update_failures')`
+	if err := db.QueryRow(checkQuery).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check existing columns: %w", err)
+	}
+
+	if !exists {
+		alterQuery := `ALTER TABLE user_preferences
+			ADD COLUMN daily_update_failures INTEGER NOT NULL DEFAULT 0,
+			ADD COLUMN daily_update_failed_on DATE`
+		if _, err := db.Exec(alterQuery); err != nil {
+			return fmt.Errorf("failed to add daily update failure columns: %w", err)
+		}
+		logger.Debug("Added daily update failure columns to user_preferences table")
+	}
+
+	return nil
+}
+
 // createStrategicIndexes creates database indexes for better query performance
 func createStrategicIndexes(db *sql.DB) error {
 	logger := GetGlobalLogger()
@@ -501,6 +892,22 @@ func createStrategicIndexes(db *sql.DB) error {
 			"idx_threshold_notifications_task",
 			"CREATE INDEX IF NOT EXISTS idx_threshold_notifications_task ON threshold_notifications(task_id)",
 		},
+		{
+			"idx_escalation_notifications_task",
+			"CREATE INDEX IF NOT EXISTS idx_escalation_notifications_task ON escalation_notifications(task_id)",
+		},
+		{
+			"idx_unestimated_task_alerts_task",
+			"CREATE INDEX IF NOT EXISTS idx_unestimated_task_alerts_task ON unestimated_task_alerts(task_id)",
+		},
+		{
+			"idx_webhook_deliveries_subscription",
+			"CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, delivered_at)",
+		},
+		{
+			"idx_slack_outbox_pending",
+			"CREATE INDEX IF NOT EXISTS idx_slack_outbox_pending ON slack_outbox(channel, thread_ts, id) WHERE status = 'pending'",
+		},
 	}
 	
 	// Create each index
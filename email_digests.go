@@ -0,0 +1,292 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmailDigestSubscription opts an address into the weekly email digest.
+// User subscriptions (SlackUserID set) get the tasks of the user's assigned projects,
+// project subscriptions (ProjectID set) get the tasks of a single project.
+type EmailDigestSubscription struct {
+	ID          int
+	Email       string
+	SlackUserID string
+	ProjectID   int
+	ProjectName string
+	CreatedAt   time.Time
+}
+
+// GetEmailDigestSubscriptions returns all weekly email digest subscriptions
+func GetEmailDigestSubscriptions(db *sql.DB) ([]EmailDigestSubscription, error) {
+	query := `
+		SELECT s.id, s.email, COALESCE(s.slack_user_id, ''), COALESCE(s.project_id, 0), COALESCE(p.name, ''), s.created_at
+		FROM email_digest_subscriptions s
+		LEFT JOIN projects p ON s.project_id = p.id
+		ORDER BY s.id
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query email digest subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []EmailDigestSubscription
+	for rows.Next() {
+		var subscription EmailDigestSubscription
+		if err := rows.Scan(&subscription.ID, &subscription.Email, &subscription.SlackUserID,
+			&subscription.ProjectID, &subscription.ProjectName, &subscription.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan email digest subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// IsUserSubscribedToEmailDigest reports whether the Slack user has opted into the weekly digest
+func IsUserSubscribedToEmailDigest(db *sql.DB, slackUserID string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM email_digest_subscriptions WHERE slack_user_id = $1)`, slackUserID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check email digest subscription: %w", err)
+	}
+	return exists, nil
+}
+
+// SubscribeUserToEmailDigest opts a Slack user into the weekly digest using their Slack profile email
+func SubscribeUserToEmailDigest(db *sql.DB, slackUserID string) error {
+	var email sql.NullString
+	err := db.QueryRow(`SELECT email FROM slack_users WHERE slack_user_id = $1`, slackUserID).Scan(&email)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to look up email for user %s: %w", slackUserID, err)
+	}
+	if !email.Valid || email.String == "" {
+		return fmt.Errorf("no email address known for user %s", slackUserID)
+	}
+
+	query := `
+		INSERT INTO email_digest_subscriptions (email, slack_user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (slack_user_id) DO UPDATE SET email = EXCLUDED.email
+	`
+	if _, err := db.Exec(query, email.String, slackUserID); err != nil {
+		return fmt.Errorf("failed to subscribe user %s to email digest: %w", slackUserID, err)
+	}
+	return nil
+}
+
+// UnsubscribeUserFromEmailDigest removes a Slack user's weekly digest opt-in
+func UnsubscribeUserFromEmailDigest(db *sql.DB, slackUserID string) error {
+	if _, err := db.Exec(`DELETE FROM email_digest_subscriptions WHERE slack_user_id = $1`, slackUserID); err != nil {
+		return fmt.Errorf("failed to unsubscribe user %s from email digest: %w", slackUserID, err)
+	}
+	return nil
+}
+
+// SubscribeEmailToProjectDigest opts an address into the weekly digest of a single project
+func SubscribeEmailToProjectDigest(db *sql.DB, email string, projectID int) (int, error) {
+	var id int
+	err := db.QueryRow(`SELECT id FROM email_digest_subscriptions WHERE email = $1 AND project_id = $2`, email, projectID).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to check existing email digest subscription: %w", err)
+	}
+
+	query := `INSERT INTO email_digest_subscriptions (email, project_id) VALUES ($1, $2) RETURNING id`
+	if err := db.QueryRow(query, email, projectID).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to subscribe %s to project %d digest: %w", email, projectID, err)
+	}
+	return id, nil
+}
+
+// DeleteEmailDigestSubscription removes a digest subscription by ID
+func DeleteEmailDigestSubscription(db *sql.DB, id int) error {
+	result, err := db.Exec(`DELETE FROM email_digest_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete email digest subscription: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("email digest subscription %d not found", id)
+	}
+	return nil
+}
+
+// sendWeeklyEmailDigests emails last week's report to every digest subscriber
+func sendWeeklyEmailDigests(logger *Logger) {
+	if !isEmailConfigured() {
+		logger.Debug("SMTP_HOST not set, skipping weekly email digests")
+		return
+	}
+
+	notifier, err := NewEmailNotifierFromEnv()
+	if err != nil {
+		logger.Errorf("Failed to configure email notifier: %v", err)
+		return
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		logger.Errorf("Failed to get database connection for email digests: %v", err)
+		return
+	}
+
+	subscriptions, err := GetEmailDigestSubscriptions(db)
+	if err != nil {
+		logger.Errorf("Failed to get email digest subscriptions: %v", err)
+		return
+	}
+	if len(subscriptions) == 0 {
+		logger.Debug("No email digest subscriptions")
+		return
+	}
+
+	startTime, endTime, err := confirmPeriod("for last week")
+	if err != nil {
+		logger.Errorf("Failed to parse period for email digests: %v", err)
+		return
+	}
+
+	userProjectMap, err := getAllUserProjectAssignments(db)
+	if err != nil {
+		logger.Errorf("Failed to get user project assignments: %v", err)
+		return
+	}
+
+	allTasksWithTime := getFilteredTasksWithTimeout(startTime, endTime, []string{}, "")
+	allTasksWithTime = addCommentsToTasks(allTasksWithTime, startTime, endTime)
+	allGroups := groupTasksByProject(allTasksWithTime)
+
+	subject := fmt.Sprintf("Weekly time report: %s – %s", startTime.Format("Jan 2"), endTime.Format("Jan 2, 2006"))
+
+	sent := 0
+	for _, subscription := range subscriptions {
+		var groups map[string][]TaskInfo
+		if subscription.SlackUserID != "" {
+			groups = groupTasksByProject(filterTasksForUser(subscription.SlackUserID, userProjectMap, allTasksWithTime))
+		} else {
+			groups = map[string][]TaskInfo{}
+			if tasks, ok := allGroups[subscription.ProjectName]; ok {
+				groups[subscription.ProjectName] = tasks
+			}
+		}
+
+		if len(groups) == 0 {
+			logger.Debugf("No tracked time for email digest subscription %d, skipping", subscription.ID)
+			continue
+		}
+
+		if err := notifier.Notify(subscription.Email, NewReport(subject, groups)); err != nil {
+			logger.Errorf("Failed to send email digest %d: %v", subscription.ID, err)
+			continue
+		}
+		sent++
+	}
+
+	logger.Infof("Sent %d of %d weekly email digests", sent, len(subscriptions))
+}
+
+// handleEmailDigestCliCommand manages email digest subscriptions from the command line
+func handleEmailDigestCliCommand(args []string, logger *Logger) error {
+	subcommand := "list"
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+
+	if subcommand == "send-test" {
+		if len(args) < 2 {
+			return fmt.Errorf("usage: email-digest send-test <email>")
+		}
+		return sendTestEmail(args[1])
+	}
+	if subcommand == "send-now" {
+		sendWeeklyEmailDigests(logger)
+		return nil
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+
+	switch subcommand {
+	case "list":
+		subscriptions, err := GetEmailDigestSubscriptions(db)
+		if err != nil {
+			return err
+		}
+		if len(subscriptions) == 0 {
+			fmt.Println("No email digest subscriptions")
+			return nil
+		}
+		for _, subscription := range subscriptions {
+			scope := "user " + subscription.SlackUserID
+			if subscription.SlackUserID == "" {
+				scope = "project " + subscription.ProjectName
+			}
+			fmt.Printf("%d\t%s\t%s\n", subscription.ID, subscription.Email, scope)
+		}
+	case "add":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: email-digest add <email> <project name>")
+		}
+		projectName := strings.Join(args[2:], " ")
+		project, err := GetProjectByName(db, projectName)
+		if err != nil {
+			return err
+		}
+		if project == nil {
+			return fmt.Errorf("project '%s' not found", projectName)
+		}
+		id, err := SubscribeEmailToProjectDigest(db, args[1], project.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Subscribed %s to the weekly digest of %s (subscription %d)\n", args[1], project.Name, id)
+	case "remove":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: email-digest remove <id>")
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid subscription id: %s", args[1])
+		}
+		if err := DeleteEmailDigestSubscription(db, id); err != nil {
+			return err
+		}
+		logger.Infof("Removed email digest subscription %d", id)
+	default:
+		return fmt.Errorf("unknown email-digest subcommand: %s", subcommand)
+	}
+
+	return nil
+}
+
+// sendTestEmail sends a sample report so SMTP settings can be checked against a local sink
+func sendTestEmail(recipient string) error {
+	notifier, err := NewEmailNotifierFromEnv()
+	if err != nil {
+		return err
+	}
+
+	sample := map[string][]TaskInfo{
+		"Sample Project": {
+			{
+				TaskID:         1,
+				Name:           "Sample task",
+				CurrentTime:    "2h 30m",
+				TotalDuration:  "10h 0m",
+				EstimationInfo: EstimationInfo{Text: "Estimation: 8-12 hours"},
+				Comments:       []string{"This is a test email from Observe Yor Estimates"},
+			},
+		},
+	}
+
+	return notifier.Notify(recipient, NewReport("OYE test email", sample))
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// EmailNotifier sends reports as multipart (plain text + HTML) emails over SMTP.
+// Authentication is skipped when no username is configured, which allows
+// delivering to a local SMTP sink such as MailHog during development.
+type EmailNotifier struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// NewEmailNotifierFromEnv creates an email notifier from the SMTP_* environment variables
+func NewEmailNotifierFromEnv() (*EmailNotifier, error) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil, fmt.Errorf("SMTP_HOST environment variable not set")
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		return nil, fmt.Errorf("SMTP_FROM environment variable not set")
+	}
+
+	return &EmailNotifier{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}, nil
+}
+
+// isEmailConfigured reports whether SMTP delivery has been set up
+func isEmailConfigured() bool {
+	return os.Getenv("SMTP_HOST") != ""
+}
+
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+func (n *EmailNotifier) Notify(recipient string, report Report) error {
+	if recipient == "" {
+		return fmt.Errorf("no email recipient provided")
+	}
+
+	message, err := buildEmailMessage(n.From, recipient, report)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		auth = smtp.PlainAuth("", n.Username, n.Password, n.Host)
+	}
+
+	addr := net.JoinHostPort(n.Host, n.Port)
+	if err := smtp.SendMail(addr, auth, n.From, []string{recipient}, message); err != nil {
+		return fmt.Errorf("failed to send email to %s via %s: %w", recipient, addr, err)
+	}
+
+	GetGlobalLogger().Infof("Sent email '%s' to %s", report.Title, recipient)
+	return nil
+}
+
+// buildEmailMessage renders the report into a multipart/alternative MIME message, using the title as subject
+func buildEmailMessage(from, to string, report Report) ([]byte, error) {
+	htmlBody, err := renderReportHTML(report)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		// Least preferred alternative first, per RFC 2046
+		{"text/plain; charset=utf-8", renderReportPlainText(report)},
+		{"text/html; charset=utf-8", htmlBody},
+	}
+	for _, part := range parts {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(partWriter)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", to)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", report.Title))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%q\r\n", writer.Boundary())
+	fmt.Fprintf(&message, "\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
+
+// renderReportPlainText renders the report as plain text
+func renderReportPlainText(report Report) string {
+	var sb strings.Builder
+	sb.WriteString(report.Title + "\n")
+	sb.WriteString(strings.Repeat("=", len(report.Title)) + "\n\n")
+
+	if report.IsEmpty() {
+		sb.WriteString("No time was tracked in this period.\n")
+		return sb.String()
+	}
+
+	if report.Summary {
+		for _, summary := range report.ProjectSummaries() {
+			sb.WriteString("* " + report.SummaryText(summary) + "\n")
+		}
+		return sb.String()
+	}
+
+	for _, project := range report.Projects {
+		sb.WriteString(project.Name + "\n")
+		sb.WriteString(strings.Repeat("-", len(project.Name)) + "\n")
+		for _, task := range project.Tasks {
+			sb.WriteString(fmt.Sprintf("* %s\n", task.Name))
+			sb.WriteString("  " + task.taskSummaryLine() + "\n")
+			for _, comment := range task.Comments {
+				if comment != "" {
+					sb.WriteString(fmt.Sprintf("  - %s\n", comment))
+				}
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+var reportEmailTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"formatDuration": formatDuration,
+}).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, Segoe UI, Helvetica, Arial, sans-serif; color: #1d1c1d;">
+<h2>{{.Title}}</h2>
+{{if not .Projects}}<p>No time was tracked in this period.</p>{{end}}
+{{if .Summary}}
+<table cellpadding="6" cellspacing="0" style="border-collapse: collapse; width: 100%;">
+<tr style="background: #f4f4f4; text-align: left;"><th>Project</th><th>Time spent</th><th>Tasks</th><th>Over {{printf "%.0f" .Threshold}}%</th><th>Worst task</th></tr>
+{{range .ProjectSummaries}}
+<tr style="border-top: 1px solid #ddd; vertical-align: top;">
+<td><strong>{{.Name}}</strong></td>
+<td>{{formatDuration .TotalSeconds}}</td>
+<td>{{.TaskCount}}</td>
+<td>{{.OverThreshold}}</td>
+<td>{{with .Worst}}{{.Name}} ({{printf "%.0f" .Percentage}}%){{else}}–{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+{{range .Projects}}
+<h3 style="margin-bottom: 4px;">{{.Name}}</h3>
+<table cellpadding="6" cellspacing="0" style="border-collapse: collapse; width: 100%;">
+<tr style="background: #f4f4f4; text-align: left;"><th>Task</th><th>Time spent</th><th>Total time</th><th>Estimation</th></tr>
+{{range .Tasks}}
+<tr style="border-top: 1px solid #ddd; vertical-align: top;">
+<td><strong>{{.Name}}</strong>{{if .Comments}}<ul style="margin: 4px 0;">{{range .Comments}}{{if .}}<li>{{.}}</li>{{end}}{{end}}</ul>{{end}}</td>
+<td>{{.TimeSpent}}</td>
+<td>{{.TotalTime}}</td>
+<td>{{.Estimation}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+{{end}}
+<p style="color: #888; font-size: 12px;">Sent by Observe Yor Estimates</p>
+</body>
+</html>
+`))
+
+// renderReportHTML renders the report as an HTML email body
+func renderReportHTML(report Report) (string, error) {
+	var buf bytes.Buffer
+	if err := reportEmailTemplate.Execute(&buf, report); err != nil {
+		return "", fmt.Errorf("failed to render email template: %w", err)
+	}
+	return buf.String(), nil
+}
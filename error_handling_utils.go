@@ -37,6 +37,13 @@ func CloseWithErrorLog(closer interface{ Close() error }, resourceName string) {
 	}
 }
 
+func getEnvString(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -61,6 +68,7 @@ func DefaultRetryConfig() RetryConfig {
 		InitialWait: time.Duration(getEnvInt("TIMECAMP_API_INITIAL_WAIT_MS", 1000)) * time.Millisecond,
 		MaxWait:     time.Duration(getEnvInt("TIMECAMP_API_MAX_WAIT_MS", 30000)) * time.Millisecond,
 		Multiplier:  getEnvFloat("TIMECAMP_API_RETRY_MULTIPLIER", 2.0),
+		Target:      "timecamp",
 	}
 }
 
@@ -81,11 +89,17 @@ func DoHTTPWithRetry(client *http.Client, request *http.Request, config RetryCon
 		client = http.DefaultClient
 	}
 
+	target := config.Target
+	if target == "" {
+		target = "other"
+	}
+
 	var lastErr error
 	waitTime := config.InitialWait
 
 	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
 		if attempt > 0 {
+			httpClientRetries.Inc(target)
 			logger.Warnf("Retrying HTTP request (attempt %d/%d) after %v: %s",
 				attempt, config.MaxRetries, waitTime, request.URL.String())
 			time.Sleep(waitTime)
@@ -94,9 +108,20 @@ func DoHTTPWithRetry(client *http.Client, request *http.Request, config RetryCon
 			if waitTime > config.MaxWait {
 				waitTime = config.MaxWait
 			}
+
+			// Rewind the body so retried POSTs resend the full payload
+			if request.GetBody != nil {
+				body, err := request.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("failed to rewind request body: %w", err)
+				}
+				request.Body = body
+			}
 		}
 
+		attemptStart := time.Now()
 		response, err := client.Do(request)
+		httpClientDuration.ObserveSince(attemptStart, target)
 		if err != nil {
 			lastErr = fmt.Errorf("HTTP request failed: %w", err)
 			logger.Debugf("Request attempt %d failed: %v", attempt+1, err)
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default escalation policy used when ESCALATION_TIERS is not set
+const defaultEscalationTiers = "125=assignees;150=assignees,owner;200=owner,channel"
+
+// getEscalationTiers parses the escalation policy from ESCALATION_TIERS
+// Format: "<percentage>=<recipient>,<recipient>;..." where a recipient is
+// "assignees", "owner", "channel" (ESCALATION_CHANNEL_ID) or "channel:<channel id>"
+func getEscalationTiers() []EscalationTier {
+	logger := GetGlobalLogger()
+
+	config := os.Getenv("ESCALATION_TIERS")
+	if config == "" {
+		config = defaultEscalationTiers
+	}
+
+	var tiers []EscalationTier
+	for _, tierConfig := range strings.Split(config, ";") {
+		tierConfig = strings.TrimSpace(tierConfig)
+		if tierConfig == "" {
+			continue
+		}
+
+		parts := strings.SplitN(tierConfig, "=", 2)
+		if len(parts) != 2 {
+			logger.Warnf("Ignoring invalid escalation tier '%s': expected <percentage>=<recipients>", tierConfig)
+			continue
+		}
+
+		threshold, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(parts[0]), "%"))
+		if err != nil || threshold <= int(THRESHOLD_OVER) {
+			logger.Warnf("Ignoring invalid escalation tier '%s': percentage must be a whole number above %.0f", tierConfig, THRESHOLD_OVER)
+			continue
+		}
+
+		var recipients []string
+		for _, recipient := range strings.Split(parts[1], ",") {
+			recipient = strings.TrimSpace(recipient)
+			if recipient != "" {
+				recipients = append(recipients, recipient)
+			}
+		}
+
+		if len(recipients) == 0 {
+			logger.Warnf("Ignoring escalation tier '%s': no recipients", tierConfig)
+			continue
+		}
+
+		tiers = append(tiers, EscalationTier{Threshold: threshold, Recipients: recipients})
+	}
+
+	// Highest tier first, same as thresholdLevels
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].Threshold > tiers[j].Threshold
+	})
+
+	return tiers
+}
+
+// checkAndRecordEscalation atomically checks and records an escalation tier crossing
+func checkAndRecordEscalation(db *sql.DB, taskID int, currentPercentage float64) (*EscalationTier, bool, error) {
+	var crossedTier *EscalationTier
+	for _, tier := range getEscalationTiers() {
+		if currentPercentage >= float64(tier.Threshold) {
+			tier := tier
+			crossedTier = &tier
+			break
+		}
+	}
+
+	if crossedTier == nil {
+		return nil, false, nil // No escalation tier crossed
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be no-op if committed
+
+	// Tiers at or above the crossed one have already been handled
+	var existingThreshold sql.NullInt64
+	checkQuery := `
+		SELECT threshold_percentage 
+		FROM escalation_notifications 
+		WHERE task_id = $1 AND threshold_percentage >= $2 
+		ORDER BY threshold_percentage DESC 
+		LIMIT 1 
+		FOR UPDATE`
+
+	err = tx.QueryRow(checkQuery, taskID, crossedTier.Threshold).Scan(&existingThreshold)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to check existing escalations: %w", err)
+	}
+
+	if existingThreshold.Valid {
+		tx.Commit()
+		return crossedTier, false, nil // Already escalated
+	}
+
+	insertQuery := `
+		INSERT INTO escalation_notifications (task_id, threshold_percentage, current_percentage)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (task_id, threshold_percentage) 
+		DO UPDATE SET 
+			current_percentage = EXCLUDED.current_percentage,
+			notified_at = CURRENT_TIMESTAMP
+	`
+	if _, err = tx.Exec(insertQuery, taskID, crossedTier.Threshold, currentPercentage); err != nil {
+		return nil, false, fmt.Errorf("failed to record escalation: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return crossedTier, true, nil
+}
+
+// sendEscalationNotifications delivers escalation alerts to the recipients of each tier
+func sendEscalationNotifications(db *sql.DB, alerts []ThresholdAlert) error {
+	logger := GetGlobalLogger()
+
+	allTasks, err := getAllTasks(db)
+	if err != nil {
+		return fmt.Errorf("failed to load task hierarchy: %w", err)
+	}
+
+	for _, alert := range alerts {
+		project, err := GetProjectForTask(db, allTasks, alert.TaskID)
+		if err != nil {
+			logger.Errorf("Failed to resolve project for task %d: %v", alert.TaskID, err)
+			continue
+		}
+
+		recipients := resolveEscalationRecipients(db, alert.Escalation, project)
+		if len(recipients) == 0 {
+			logger.Warnf("No recipients resolved for %d%% escalation of task %d (%s)", alert.Escalation.Threshold, alert.TaskID, alert.Name)
+			continue
+		}
+
+		logger.Infof("Escalating task %d (%s) at %d%% to %d recipients", alert.TaskID, alert.Name, alert.Escalation.Threshold, len(recipients))
+		for _, recipient := range recipients {
+			sendTaskAlertsToUser(db, recipient, []ThresholdAlert{alert})
+		}
+
+		updateQuery := `UPDATE escalation_notifications SET recipients = $1 WHERE task_id = $2 AND threshold_percentage = $3`
+		if _, err := db.Exec(updateQuery, strings.Join(recipients, ","), alert.TaskID, alert.Escalation.Threshold); err != nil {
+			logger.Errorf("Failed to log escalation recipients for task %d: %v", alert.TaskID, err)
+		}
+	}
+
+	return nil
+}
+
+// resolveEscalationRecipients turns a tier's recipient list into Slack user and channel IDs
+func resolveEscalationRecipients(db *sql.DB, tier *EscalationTier, project *Project) []string {
+	logger := GetGlobalLogger()
+
+	seen := make(map[string]bool)
+	var recipients []string
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			recipients = append(recipients, id)
+		}
+	}
+
+	for _, recipient := range tier.Recipients {
+		switch {
+		case recipient == ESCALATE_TO_ASSIGNEES:
+			if project == nil {
+				logger.Warnf("Cannot escalate to assignees: task has no project")
+				continue
+			}
+			assignees, err := GetProjectAssignees(db, project.ID)
+			if err != nil {
+				logger.Errorf("Failed to get assignees for project %s: %v", project.Name, err)
+				continue
+			}
+			for _, assignee := range assignees {
+				add(assignee)
+			}
+		case recipient == ESCALATE_TO_OWNER:
+			if project == nil {
+				logger.Warnf("Cannot escalate to owner: task has no project")
+				continue
+			}
+			owner, err := GetProjectOwner(db, project.ID)
+			if err != nil {
+				logger.Errorf("Failed to get owner for project %s: %v", project.Name, err)
+				continue
+			}
+			if owner == "" {
+				logger.Warnf("Project %s has no owner, skipping owner escalation", project.Name)
+				continue
+			}
+			add(owner)
+		case recipient == ESCALATE_TO_CHANNEL:
+			channelID := os.Getenv("ESCALATION_CHANNEL_ID")
+			if channelID == "" {
+				logger.Warnf("ESCALATION_CHANNEL_ID not configured, skipping channel escalation")
+				continue
+			}
+			add(channelID)
+		case strings.HasPrefix(recipient, ESCALATE_TO_CHANNEL+":"):
+			add(strings.TrimPrefix(recipient, ESCALATE_TO_CHANNEL+":"))
+		default:
+			logger.Warnf("Unknown escalation recipient '%s'", recipient)
+		}
+	}
+
+	return recipients
+}
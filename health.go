@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// The job whose last success /readyz holds against SYNC_SLA_MINUTES
+const readinessSyncJob = "time entries sync"
+
+// processStartedAt gives a fresh process until the SLA to run its first sync
+var processStartedAt = time.Now()
+
+// databaseStartupRetryConfig is how long startup waits for the database
+func databaseStartupRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries:  getEnvInt("DB_CONNECT_MAX_RETRIES", 5),
+		InitialWait: time.Duration(getEnvInt("DB_CONNECT_INITIAL_WAIT_MS", 2000)) * time.Millisecond,
+		MaxWait:     time.Duration(getEnvInt("DB_CONNECT_MAX_WAIT_MS", 30000)) * time.Millisecond,
+		Multiplier:  getEnvFloat("DB_CONNECT_RETRY_MULTIPLIER", 2.0),
+	}
+}
+
+// CronJobStatus is the outcome of a scheduled job's latest run
+type CronJobStatus struct {
+	JobName       string
+	LastRunAt     time.Time
+	LastDuration  time.Duration
+	LastError     string // empty when the last run succeeded
+	LastSuccessAt sql.NullTime
+	Runs          int
+	Failures      int
+}
+
+// recordCronJobRun stores the outcome of a scheduled job run started at start.
+// Times come from the database clock, like the rest of its timestamps.
+func recordCronJobRun(jobName string, start time.Time, runErr error) error {
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	var lastError sql.NullString
+	failures := 0
+	if runErr != nil {
+		lastError = sql.NullString{String: truncateUTF8(runErr.Error(), 1000), Valid: true}
+		failures = 1
+	}
+	_, err = db.Exec(`
+		INSERT INTO cron_job_status (job_name, last_run_at, last_duration_ms, last_error, last_success_at, runs, failures)
+		VALUES ($1, NOW() - $2 * INTERVAL '1 millisecond', $2, $3, CASE WHEN $3::text IS NULL THEN NOW() END, 1, $4)
+		ON CONFLICT (job_name) DO UPDATE SET
+			last_run_at = EXCLUDED.last_run_at,
+			last_duration_ms = EXCLUDED.last_duration_ms,
+			last_error = EXCLUDED.last_error,
+			last_success_at = COALESCE(EXCLUDED.last_success_at, cron_job_status.last_success_at),
+			runs = cron_job_status.runs + 1,
+			failures = cron_job_status.failures + EXCLUDED.failures`,
+		jobName, time.Since(start).Milliseconds(), lastError, failures)
+	if err != nil {
+		return fmt.Errorf("failed to record cron job status: %w", err)
+	}
+	return nil
+}
+
+// GetCronJobStatuses returns the latest run of every job that has run, by name
+func GetCronJobStatuses(db *sql.DB) ([]CronJobStatus, error) {
+	rows, err := db.Query(`
+		SELECT job_name, last_run_at, last_duration_ms, COALESCE(last_error, ''), last_success_at, runs, failures
+		FROM cron_job_status
+		ORDER BY job_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cron job status: %w", err)
+	}
+	defer rows.Close()
+
+	var statuses []CronJobStatus
+	for rows.Next() {
+		var status CronJobStatus
+		var durationMs int64
+		if err := rows.Scan(&status.JobName, &status.LastRunAt, &durationMs, &status.LastError,
+			&status.LastSuccessAt, &status.Runs, &status.Failures); err != nil {
+			return nil, fmt.Errorf("failed to scan cron job status: %w", err)
+		}
+		status.LastDuration = time.Duration(durationMs) * time.Millisecond
+		statuses = append(statuses, status)
+	}
+	return statuses, rows.Err()
+}
+
+// getSchemaVersion returns the number of the latest migration the database has run
+func getSchemaVersion(db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRow(`SELECT version FROM schema_version WHERE id = 1`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// readinessCheck is the outcome of one /readyz check
+type readinessCheck struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// handleHealthz answers as long as the process serves requests
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeAPIJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReadyz reports whether OYE can serve reports: the database answers, its schema is
+// up to date and the time entries sync succeeded within SYNC_SLA_MINUTES
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]readinessCheck{}
+	ready := true
+	fail := func(name, detail string) {
+		checks[name] = readinessCheck{Detail: detail}
+		ready = false
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		fail("database", err.Error())
+	} else {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+		if err := db.PingContext(ctx); err != nil {
+			fail("database", err.Error())
+		} else {
+			checks["database"] = readinessCheck{OK: true, Detail: "reachable"}
+		}
+	}
+
+	if ready {
+		if version, err := getSchemaVersion(db); err != nil {
+			fail("schema", err.Error())
+		} else if version < SCHEMA_VERSION {
+			fail("schema", fmt.Sprintf("version %d, expected %d", version, SCHEMA_VERSION))
+		} else {
+			checks["schema"] = readinessCheck{OK: true, Detail: fmt.Sprintf("version %d", version)}
+		}
+
+		if check := syncReadiness(db); check.OK {
+			checks["sync"] = check
+		} else {
+			fail("sync", check.Detail)
+		}
+	}
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not ready", http.StatusServiceUnavailable
+	}
+	writeAPIJSON(w, code, map[string]interface{}{"status": status, "checks": checks})
+}
+
+// syncReadiness checks the last successful time entries sync against SYNC_SLA_MINUTES,
+// counting from startup when that is later so a restart isn't held against the sync
+func syncReadiness(db *sql.DB) readinessCheck {
+	sla := time.Duration(getEnvInt("SYNC_SLA_MINUTES", 90)) * time.Minute
+
+	var lastSuccessAge sql.NullFloat64
+	err := db.QueryRow(`SELECT EXTRACT(EPOCH FROM NOW() - last_success_at) FROM cron_job_status WHERE job_name = $1`,
+		readinessSyncJob).Scan(&lastSuccessAge)
+	if err != nil && err != sql.ErrNoRows {
+		return readinessCheck{Detail: fmt.Sprintf("failed to read sync status: %v", err)}
+	}
+
+	age := time.Since(processStartedAt)
+	detail := "no successful sync yet"
+	if lastSuccessAge.Valid {
+		lastAge := time.Duration(lastSuccessAge.Float64 * float64(time.Second))
+		detail = fmt.Sprintf("last successful sync %v ago", lastAge.Round(time.Second))
+		if lastAge < age {
+			age = lastAge
+		}
+	}
+	if age > sla {
+		return readinessCheck{Detail: fmt.Sprintf("%s, SLA is %v", detail, sla)}
+	}
+	return readinessCheck{OK: true, Detail: detail}
+}
+
+// isStatusCommand reports whether the /oye text asks for the service status
+func isStatusCommand(text string) bool {
+	return strings.EqualFold(strings.TrimSpace(text), "status")
+}
+
+// handleStatusCommand shows an admin how the scheduled jobs, data and connections are doing.
+// Reaching TimeCamp and Slack can take a while, so the status follows the acknowledgement.
+func handleStatusCommand(w http.ResponseWriter, req *SlackCommandRequest) {
+	if !isOYEAdmin(req.UserID) {
+		sendImmediateResponse(w, "Sorry, `/oye status` is for OYE admins only.", "ephemeral")
+		return
+	}
+	sendImmediateResponse(w, "Checking OYE's status…", "ephemeral")
+	go func() {
+		replyToRequester(req, buildStatusText())
+	}()
+}
+
+// statusRowCountTables are the tables whose size `/oye status` shows
+var statusRowCountTables = []struct {
+	table, label string
+}{
+	{"projects", "projects"},
+	{"tasks", "tasks"},
+	{"time_entries", "time entries"},
+	{"slack_users", "Slack users"},
+}
+
+// buildStatusText renders the status of jobs, data and connections for Slack
+func buildStatusText() string {
+	var b strings.Builder
+	b.WriteString("*🩺 OYE status*\n")
+
+	db, err := GetDB()
+	if err != nil {
+		fmt.Fprintf(&b, "%s Database unavailable: `%s`\n", EMOJI_CROSS, truncateUTF8(err.Error(), 200))
+	} else {
+		writeStatusJobs(&b, db)
+		writeStatusData(&b, db)
+	}
+
+	b.WriteString("\n*Connections*\n")
+	if db != nil {
+		if version, err := getSchemaVersion(db); err != nil {
+			fmt.Fprintf(&b, "• %s Database: `%s`\n", EMOJI_CROSS, truncateUTF8(err.Error(), 200))
+		} else {
+			fmt.Fprintf(&b, "• %s Database: schema version %d of %d\n", statusEmoji(version >= SCHEMA_VERSION), version, SCHEMA_VERSION)
+		}
+	}
+	writeStatusConnection(&b, "TimeCamp", checkTimeCampReachable)
+	writeStatusConnection(&b, "Slack", checkSlackReachable)
+	return b.String()
+}
+
+func statusEmoji(ok bool) string {
+	if ok {
+		return EMOJI_CHECK
+	}
+	return EMOJI_CROSS
+}
+
+// writeStatusJobs lists each scheduled job's latest run
+func writeStatusJobs(b *strings.Builder, db *sql.DB) {
+	b.WriteString("\n*Scheduled jobs*\n")
+	statuses, err := GetCronJobStatuses(db)
+	if err != nil {
+		fmt.Fprintf(b, "• %s `%s`\n", EMOJI_CROSS, truncateUTF8(err.Error(), 200))
+		return
+	}
+	if len(statuses) == 0 {
+		b.WriteString("• No scheduled job has run yet\n")
+		return
+	}
+	for _, status := range statuses {
+		fmt.Fprintf(b, "• %s *%s*: last run %s, took %v, %d runs, %d failed",
+			statusEmoji(status.LastError == ""), status.JobName, status.LastRunAt.Format("Jan 2 15:04"),
+			status.LastDuration.Round(100*time.Millisecond), status.Runs, status.Failures)
+		if status.LastError != "" {
+			if status.LastSuccessAt.Valid {
+				fmt.Fprintf(b, ", last success %s", status.LastSuccessAt.Time.Format("Jan 2 15:04"))
+			}
+			fmt.Fprintf(b, "\n    `%s`", truncateUTF8(status.LastError, 200))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// writeStatusData shows row counts and the orphaned entries waiting for their tasks
+func writeStatusData(b *strings.Builder, db *sql.DB) {
+	b.WriteString("\n*Data*\n")
+	counts := make([]string, 0, len(statusRowCountTables))
+	for _, table := range statusRowCountTables {
+		var count int
+		if err := db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", table.table)).Scan(&count); err != nil {
+			counts = append(counts, fmt.Sprintf("%s: ?", table.label))
+			continue
+		}
+		counts = append(counts, fmt.Sprintf("%d %s", count, table.label))
+	}
+	fmt.Fprintf(b, "• %s\n", strings.Join(counts, ", "))
+
+	if orphaned, err := GetOrphanedTimeEntriesCount(db); err != nil {
+		fmt.Fprintf(b, "• Orphaned time entries: `%s`\n", truncateUTF8(err.Error(), 200))
+	} else {
+		fmt.Fprintf(b, "• %d orphaned time entries waiting for their task\n", orphaned)
+	}
+}
+
+// writeStatusConnection runs a reachability check and reports its outcome and latency
+func writeStatusConnection(b *strings.Builder, name string, check func() error) {
+	start := time.Now()
+	if err := check(); err != nil {
+		fmt.Fprintf(b, "• %s %s: `%s`\n", EMOJI_CROSS, name, truncateUTF8(err.Error(), 200))
+		return
+	}
+	fmt.Fprintf(b, "• %s %s: reachable in %v\n", EMOJI_CHECK, name, time.Since(start).Round(time.Millisecond))
+}
+
+// checkTimeCampReachable asks the TimeCamp API who the API key belongs to
+func checkTimeCampReachable() error {
+	apiURL := getEnvString("TIMECAMP_API_URL", "https://app.timecamp.com/third_party/api")
+	request, err := http.NewRequest("GET", apiURL+"/me", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+os.Getenv("TIMECAMP_API_KEY"))
+	request.Header.Set("Accept", "application/json")
+
+	response, err := (&http.Client{Timeout: 5 * time.Second}).Do(request)
+	if err != nil {
+		return err
+	}
+	defer CloseWithErrorLog(response.Body, "TimeCamp status response body")
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("TimeCamp API returned status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// checkSlackReachable verifies the bot token with auth.test
+func checkSlackReachable() error {
+	return NewSlackAPIClient().sendSlackFormRequest("auth.test", url.Values{}, nil)
+}
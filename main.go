@@ -30,12 +30,10 @@ func main() {
 
 	logger.Info("Starting Observe-Yor-Estimates application")
 
-	// Initialize database connection for CLI operations
+	// Wait for the database, it may still be starting next to us
 	logger.Info("Initializing database connection...")
-	_, initErr := GetDB()
-	if initErr != nil {
-		logger.Errorf("Failed to initialize database: %v", initErr)
-		return
+	if _, err := waitForDB(databaseStartupRetryConfig()); err != nil {
+		logger.Fatalf("Critical error: Failed to initialize database: %v", err)
 	}
 	logger.Info("Database connection initialized successfully")
 
@@ -46,6 +44,9 @@ func main() {
 	logger.Info("Setting up scheduled tasks...")
 	setupCronJobs(logger)
 
+	// Send queued Slack messages, including those left over from before a restart
+	slackOutbox.Start()
+
 	// Start HTTP server for Slack commands (this will block)
 	logger.Info("Starting HTTP server for Slack integrations...")
 	StartServer(logger)
@@ -85,9 +86,31 @@ func handleCliCommands(args []string, logger *Logger) {
 		logger.Info("Running full synchronization command")
 		if err := FullSyncAll(); err != nil {
 			logger.Errorf("Full sync failed: %v", err)
+			// Deliver synchronously, the process exits right after
+			PublishWebhookEvent(WEBHOOK_EVENT_SYNC_FAILED, syncFailedWebhookData("full sync", err))
 			os.Exit(1)
 		}
 		logger.Info("Full synchronization completed successfully")
+	case "webhooks":
+		if err := handleWebhookCliCommand(args[1:], logger); err != nil {
+			logger.Errorf("Webhooks command failed: %v", err)
+			os.Exit(1)
+		}
+	case "api-tokens":
+		if err := handleAPITokensCliCommand(args[1:], logger); err != nil {
+			logger.Errorf("API tokens command failed: %v", err)
+			os.Exit(1)
+		}
+	case "export":
+		if err := handleExportCliCommand(args[1:], logger); err != nil {
+			logger.Errorf("Export failed: %v", err)
+			os.Exit(1)
+		}
+	case "email-digest":
+		if err := handleEmailDigestCliCommand(args[1:], logger); err != nil {
+			logger.Errorf("Email digest command failed: %v", err)
+			os.Exit(1)
+		}
 	default:
 		logger.Warnf("Unknown command line argument: %s", command)
 		showHelp()
@@ -97,149 +120,350 @@ func handleCliCommands(args []string, logger *Logger) {
 func setupCronJobs(logger *Logger) {
 	cronScheduler := cron.New()
 
-	addCronJob(cronScheduler, "TASK_SYNC_SCHEDULE", "0 */6 * * *", "task sync", logger, func() {
-		if err := SyncTasksToDatabase(false); err != nil {
-			logger.Errorf("Scheduled task sync failed: %v", err)
+	addCronJob(cronScheduler, "TASK_SYNC_SCHEDULE", "0 */6 * * *", "task sync", logger, func() error {
+		err := SyncTasksToDatabase(false)
+		if err != nil {
+			dispatchSyncFailedWebhook("task sync", err)
+		}
+		return err
+	})
+
+	addCronJob(cronScheduler, "TIME_ENTRIES_SYNC_SCHEDULE", "*/30 * * * *", "time entries sync", logger, func() error {
+		err := SyncTimeEntriesToDatabaseWithOptions("", "", false)
+		if err != nil {
+			dispatchSyncFailedWebhook("time entries sync", err)
 		}
+		return err
+	})
+
+	addCronJob(cronScheduler, "SLACK_USER_SYNC_SCHEDULE", "0 5 * * *", "Slack user sync", logger, func() error {
+		err := SyncSlackUsersToDatabase()
+		if err != nil {
+			dispatchSyncFailedWebhook("Slack user sync", err)
+		}
+		return err
+	})
+
+	addCronJob(cronScheduler, "DAILY_UPDATE_SCHEDULE", "0 6 * * *", "daily channel update", logger, func() error {
+		return sendDailyUpdate(logger)
+	})
+
+	// Checks whose daily update is due in their own timezone. Users are marked sent only once
+	// their update went out, so a slow tick must not overlap the next one or both send it.
+	addCronJob(cronScheduler, "DAILY_UPDATE_TICK_SCHEDULE", "*/5 * * * *", "daily Slack update", logger, func() error {
+		return sendDueDailyUpdates(logger)
+	}, cron.SkipIfStillRunning(cronLogger{logger}))
+
+	addCronJob(cronScheduler, "SLACK_EVENT_CLEANUP_SCHEDULE", "0 4 * * *", "Slack event dedupe cleanup", logger, func() error {
+		db, err := GetDB()
+		if err != nil {
+			return fmt.Errorf("failed to get database connection: %w", err)
+		}
+		removed, err := cleanupProcessedSlackEvents(db)
+		if err != nil {
+			return err
+		}
+		if removed > 0 {
+			logger.Infof("Removed %d processed Slack event IDs", removed)
+		}
+		return nil
 	})
 
-	addCronJob(cronScheduler, "TIME_ENTRIES_SYNC_SCHEDULE", "*/30 * * * *", "time entries sync", logger, func() {
-		if err := SyncTimeEntriesToDatabaseWithOptions("", "", false); err != nil {
-			logger.Errorf("Scheduled time entries sync failed: %v", err)
+	addCronJob(cronScheduler, "SLACK_OUTBOX_CLEANUP_SCHEDULE", "30 4 * * *", "Slack outbox cleanup", logger, func() error {
+		db, err := GetDB()
+		if err != nil {
+			return fmt.Errorf("failed to get database connection: %w", err)
+		}
+		removed, err := cleanupSlackOutbox(db)
+		if err != nil {
+			return err
 		}
+		if removed > 0 {
+			logger.Infof("Removed %d delivered or dead Slack outbox messages", removed)
+		}
+		return nil
 	})
 
-	addCronJob(cronScheduler, "SLACK_USER_SYNC_SCHEDULE", "0 5 * * *", "Slack user sync", logger, func() {
-		if err := SyncSlackUsersToDatabase(); err != nil {
-			logger.Errorf("Scheduled Slack user sync failed: %v", err)
+	addCronJob(cronScheduler, "DIGEST_MESSAGE_CLEANUP_SCHEDULE", "45 4 * * *", "digest message cleanup", logger, func() error {
+		db, err := GetDB()
+		if err != nil {
+			return fmt.Errorf("failed to get database connection: %w", err)
 		}
+		removed, err := cleanupDigestMessages(db)
+		if err != nil {
+			return err
+		}
+		if removed > 0 {
+			logger.Infof("Forgot %d old digest messages and alert threads", removed)
+		}
+		return nil
 	})
 
-	addCronJob(cronScheduler, "DAILY_UPDATE_SCHEDULE", "0 6 * * *", "daily Slack update", logger, func() {
-		sendDailyUpdate(logger)
+	addCronJob(cronScheduler, "WEEKLY_EMAIL_DIGEST_SCHEDULE", "0 7 * * 1", "weekly email digest", logger, func() error {
+		sendWeeklyEmailDigests(logger)
+		return nil
 	})
 
 	// Add orphaned time entries processing cron job (every 6 hours)
-	addCronJob(cronScheduler, "ORPHANED_PROCESSING_SCHEDULE", "0 */6 * * *", "orphaned time entries processing", logger, func() {
+	addCronJob(cronScheduler, "ORPHANED_PROCESSING_SCHEDULE", "0 */6 * * *", "orphaned time entries processing", logger, func() error {
 		db, err := GetDB()
 		if err != nil {
-			logger.Errorf("Failed to get database connection for orphaned processing: %v", err)
-			return
+			return fmt.Errorf("failed to get database connection: %w", err)
 		}
 
 		// Check if there are any orphaned entries to process
 		count, err := GetOrphanedTimeEntriesCount(db)
 		if err != nil {
-			logger.Errorf("Failed to count orphaned entries: %v", err)
-			return
+			return err
 		}
 
 		if count > 0 {
 			logger.Infof("Found %d orphaned time entries, processing...", count)
 			if err := ProcessOrphanedTimeEntries(db); err != nil {
-				logger.Errorf("Orphaned time entries processing failed: %v", err)
-			} else {
-				logger.Debug("Orphaned time entries processing completed successfully")
+				return err
 			}
+			logger.Debug("Orphaned time entries processing completed successfully")
 		}
+		return nil
 	})
 
+	// Report subscriptions are added and removed at runtime as users change them
+	reportScheduler = NewReportScheduler(cronScheduler)
+	if err := reportScheduler.Sync(); err != nil {
+		logger.Errorf("Failed to schedule report subscriptions: %v", err)
+	}
+
 	cronScheduler.Start()
 	logger.Info("Cron scheduler started successfully")
 }
 
-func sendDailyUpdate(logger *Logger) {
-	commandText := "for yesterday"
-
-	db, err := GetDB()
-	if err != nil {
-		logger.Errorf("Failed to get database connection for daily update: %v", err)
-		return
+// dailyUpdateReportOptions reads sorting, top-N and summary for the daily update, e.g. "sort by time top 20"
+func dailyUpdateReportOptions(logger *Logger) ReportOptions {
+	optionsText := os.Getenv("DAILY_UPDATE_OPTIONS")
+	if optionsText == "" {
+		return ReportOptions{}
 	}
-
-	// Get all Slack users from database (synced earlier by user sync cron job)
-	users, err := GetSlackUsersFromDatabase()
+	reportOptions, err := parseReportOptions(optionsText)
 	if err != nil {
-		logger.Errorf("Failed to get Slack users for daily update: %v", err)
-		return
+		logger.Warnf("Ignoring invalid DAILY_UPDATE_OPTIONS '%s': %v", optionsText, err)
+		return ReportOptions{}
 	}
+	return reportOptions
+}
 
-	logger.Infof("Starting optimized daily updates for %d users", len(users))
+// sendDailyUpdate posts yesterday's full report to the Mattermost and Teams channels.
+// Direct messages go out per user at their own time, see sendDueDailyUpdates.
+func sendDailyUpdate(logger *Logger) error {
+	commandText := "for yesterday"
 
 	// Get time period for filtering tasks
 	startTime, endTime, err := confirmPeriod(commandText)
 	if err != nil {
-		logger.Errorf("Failed to parse period for daily update: %v", err)
-		return
-	}
-
-	// OPTIMIZATION: Query ALL data once instead of per-user queries
-	logger.Info("Fetching ALL yesterday's data in single queries...")
-
-	// 1. Get ALL user-project assignments at once
-	userProjectMap, err := getAllUserProjectAssignments(db)
-	if err != nil {
-		logger.Errorf("Failed to get user project assignments: %v", err)
-		return
+		return fmt.Errorf("failed to parse period for daily update: %w", err)
 	}
 
-	// 2. Get ALL tasks with time entries for yesterday at once
 	allTasksWithTime := getFilteredTasksWithTimeout(startTime, endTime, []string{}, "")
 	if len(allTasksWithTime) == 0 {
 		logger.Info("No tasks with time entries found for yesterday")
-		return
+		return nil
 	}
-
-	// 3. Add comments to all tasks at once
 	allTasksWithTime = addCommentsToTasks(allTasksWithTime, startTime, endTime)
 
-	logger.Infof("Successfully fetched data: %d user-project assignments, %d tasks with time",
-		len(userProjectMap), len(allTasksWithTime))
+	// Post the full report to Mattermost and Teams channels when configured
+	broadcastReportToChatWebhooks(NewReport(
+		fmt.Sprintf("%s Daily update for %s", EMOJI_CHART, startTime.Format("Monday, Jan 2")),
+		groupTasksByProject(allTasksWithTime)).WithOptions(dailyUpdateReportOptions(logger)))
+	return nil
+}
+
+// sendDueDailyUpdates DMs every user whose delivery time has passed in their timezone today.
+// Users due at the same time share one query per local day, so a tick costs little when nobody is due.
+// Each local day that had updates sent publishes a daily_summary.sent webhook event with their count.
+func sendDueDailyUpdates(logger *Logger) error {
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection for daily update: %w", err)
+	}
 
-	// Process each user with pre-fetched data
-	for _, user := range users {
-		logger.Infof("Processing daily update for user %s (%s)", user.ID, user.RealName)
+	preferences, err := GetAllDailyUpdatePreferences(db)
+	if err != nil {
+		return fmt.Errorf("failed to get user preferences for daily update: %w", err)
+	}
 
-		// Filter tasks for this user based on their project assignments
-		userTasks := filterTasksForUser(user.ID, userProjectMap, allTasksWithTime)
-		if len(userTasks) == 0 {
-			logger.Infof("No tasks found for user %s in the specified period", user.ID)
+	// Group due users by their local date, since "yesterday" depends on the timezone
+	now := time.Now()
+	dueByDate := make(map[string][]DailyUpdatePreferences)
+	for _, prefs := range preferences {
+		if date := prefs.DueDate(now); date != "" {
+			dueByDate[date] = append(dueByDate[date], prefs)
+		}
+	}
+	if len(dueByDate) == 0 {
+		return nil
+	}
+
+	// Get all user-project assignments at once
+	userProjectMap, err := getAllUserProjectAssignments(db)
+	if err != nil {
+		return fmt.Errorf("failed to get user project assignments: %w", err)
+	}
+
+	reportOptions := dailyUpdateReportOptions(logger)
+	notifiedUsers := 0
+	for date, dueUsers := range dueByDate {
+		today, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			logger.Errorf("Invalid daily update date %s: %v", date, err)
 			continue
 		}
+		startTime, endTime := startOfDay(today.AddDate(0, 0, -1)), endOfDay(today.AddDate(0, 0, -1))
+
+		logger.Infof("Sending daily updates for %s to %d users", startTime.Format("2006-01-02"), len(dueUsers))
+		allTasksWithTime := getFilteredTasksWithTimeout(startTime, endTime, []string{}, "")
+		if len(allTasksWithTime) > 0 {
+			allTasksWithTime = addCommentsToTasks(allTasksWithTime, startTime, endTime)
+		}
+
+		dateNotified := 0
+		for _, prefs := range dueUsers {
+			sent, err := sendUserDailyUpdate(prefs, userProjectMap, allTasksWithTime, reportOptions, startTime, endTime)
+			if err != nil {
+				retry, markErr := recordDailyUpdateFailure(db, prefs.UserID, date)
+				if markErr != nil {
+					logger.Errorf("%v", markErr)
+				}
+				logger.Errorf("Daily update for user %s failed (retrying: %t): %v", prefs.UserID, retry, err)
+				continue
+			}
+			// Recorded only once the update went out or was skipped, so failures are retried
+			if err := markDailyUpdateSent(db, prefs.UserID, date); err != nil {
+				logger.Errorf("%v", err)
+			}
+			if sent {
+				dateNotified++
+			}
+		}
+
+		notifiedUsers += dateNotified
+		if dateNotified > 0 {
+			DispatchWebhookEvent(WEBHOOK_EVENT_DAILY_SUMMARY, map[string]interface{}{
+				"period":         "for yesterday",
+				"start_date":     startTime.Format("2006-01-02"),
+				"end_date":       endTime.Format("2006-01-02"),
+				"tasks":          len(allTasksWithTime),
+				"users_notified": dateNotified,
+			})
+		}
+	}
+
+	logger.Infof("Sent daily updates to %d users", notifiedUsers)
+	return nil
+}
 
-		logger.Infof("Found %d tasks for user %s", len(userTasks), user.ID)
+// sendUserDailyUpdate sends a user yesterday's report for their projects. It returns false
+// without an error when there's nothing to send them.
+func sendUserDailyUpdate(prefs DailyUpdatePreferences, userProjectMap map[string][]string, allTasksWithTime []TaskInfo,
+	reportOptions ReportOptions, startTime, endTime time.Time) (bool, error) {
+	logger := GetGlobalLogger()
 
-		// Group by project and send
-		filteredTasksGroupedByProject := groupTasksByProject(userTasks)
-		sendTasksGroupedByProjectToUser(user.ID, filteredTasksGroupedByProject)
+	// Users who haven't picked projects in the App Home get no update, never the whole workspace
+	if len(userProjectMap[prefs.UserID]) == 0 {
+		logger.Debugf("User %s has no project assignments, skipping daily update", prefs.UserID)
+		return false, nil
+	}
+
+	// Filter tasks for this user based on their project assignments
+	userTasks := filterTasksForUser(prefs.UserID, userProjectMap, allTasksWithTime)
+	if len(userTasks) == 0 {
+		logger.Infof("No tasks found for user %s in the specified period", prefs.UserID)
+		return false, nil
+	}
 
-		// Small delay between users to avoid rate limiting
-		time.Sleep(250 * time.Millisecond)
+	report := NewReport("", groupTasksByProject(userTasks))
+	report.Query = &ReportQuery{Period: "yesterday", Start: startTime, End: endTime}
+	if prefs.OnlyOverThreshold && !hasTaskOverThreshold(report, prefs.Threshold) {
+		logger.Infof("No tasks over %.0f%% for user %s, skipping daily update", prefs.Threshold, prefs.UserID)
+		return false, nil
+	}
 
-		logger.Infof("Completed daily update for user %s", user.ID)
+	// Summaries count tasks over the user's own threshold
+	userOptions := reportOptions
+	userOptions.Threshold = prefs.Threshold
+	if err := sendDigestToUser(prefs.UserID, DIGEST_DAILY_UPDATE, report.WithOptions(userOptions)); err != nil {
+		return false, err
 	}
+	return true, nil
+}
+
+// dispatchSyncFailedWebhook publishes a sync failure to outbound webhooks
+func dispatchSyncFailedWebhook(jobName string, err error) {
+	DispatchWebhookEvent(WEBHOOK_EVENT_SYNC_FAILED, syncFailedWebhookData(jobName, err))
+}
 
-	logger.Infof("Completed optimized daily updates for all %d users", len(users))
+func syncFailedWebhookData(jobName string, err error) map[string]interface{} {
+	return map[string]interface{}{
+		"job":   jobName,
+		"error": err.Error(),
+	}
 }
 
-func addCronJob(scheduler *cron.Cron, envVar, defaultSchedule, jobName string, logger *Logger, cmd func()) {
+// addCronJob schedules a job, logging its error and recording its runs, failures and duration
+// as metrics and in cron_job_status. Wrappers such as cron.SkipIfStillRunning apply to this job only.
+func addCronJob(scheduler *cron.Cron, envVar, defaultSchedule, jobName string, logger *Logger, cmd func() error, wrappers ...cron.JobWrapper) {
 	schedule := os.Getenv(envVar)
 	if schedule == "" {
 		schedule = defaultSchedule
 	}
-	_, err := scheduler.AddFunc(schedule, func() {
+	_, err := scheduler.AddJob(schedule, cron.NewChain(wrappers...).Then(cron.FuncJob(func() {
 		logger.Debugf("Running scheduled %s", jobName)
-		cmd()
-	})
+		start := time.Now()
+		err := cmd()
+		cronJobDuration.ObserveSince(start, jobName)
+		cronJobRuns.Inc(jobName)
+		if err != nil {
+			cronJobFailures.Inc(jobName)
+			logger.Errorf("Scheduled %s failed: %v", jobName, err)
+		}
+		if err := recordCronJobRun(jobName, start, err); err != nil {
+			logger.Warnf("Failed to record %s run: %v", jobName, err)
+		}
+	})))
 	if err != nil {
 		logger.Fatalf("Critical error: Failed to schedule %s cron job: %v", jobName, err)
 	}
 }
 
+// cronLogger passes the cron library's messages, e.g. skipped runs, to our logger
+type cronLogger struct {
+	logger *Logger
+}
+
+func (l cronLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.Infof("cron: %s %v", msg, keysAndValues)
+}
+
+func (l cronLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.logger.Errorf("cron: %s %v: %v", msg, keysAndValues, err)
+}
+
 func showHelp() {
 	fmt.Println("Usage: observe-yor-estimates [command]")
 	fmt.Println("\nAvailable commands:")
 	fmt.Println("  full-sync                - Full sync of all tasks and time entries")
+	fmt.Println("  webhooks [list]          - List outbound webhook subscriptions")
+	fmt.Println("  webhooks add <url> <events|*> [secret] - Subscribe a URL to events")
+	fmt.Println("  webhooks remove <id>     - Remove a webhook subscription")
+	fmt.Println("  webhooks deliveries [--failed] - Show the latest webhook deliveries")
+	fmt.Println("  api-tokens [list]        - List REST API tokens")
+	fmt.Println("  api-tokens add <name>    - Create a REST API token, printed only once")
+	fmt.Println("  api-tokens revoke <id>   - Revoke a REST API token")
+	fmt.Println("  export --from <date> [--to <date>] [--project <names>] [--format csv|xlsx] [--output <file>]")
+	fmt.Println("                           - Write a report spreadsheet with one row per task")
+	fmt.Println("  email-digest [list]      - List weekly email digest subscriptions")
+	fmt.Println("  email-digest add <email> <project name> - Email a project's weekly digest to an address")
+	fmt.Println("  email-digest remove <id> - Remove an email digest subscription")
+	fmt.Println("  email-digest send-now    - Send the weekly email digests immediately")
+	fmt.Println("  email-digest send-test <email> - Send a sample report to check SMTP settings")
 	fmt.Println("  --test-command=\"/oye ...\"  - Run a test slash command locally and DM the result to 'Szymon'")
 }
 
@@ -247,41 +471,23 @@ func showHelp() {
 func RunTestCommand(logger *Logger, fullCommand string, slackUserName string) error {
 	logger.Infof("Running test command: %s", fullCommand)
 
-	// Normalize like the HTTP path
-	commandText := strings.ToLower(strings.TrimSpace(fullCommand))
-	commandText = strings.Replace(commandText, "/oye", "", 1)
-
-	// Parse inputs using the same helpers as HTTP path
-	projectName, err := confirmProject(commandText)
+	// Parse with the same grammar as the HTTP path
+	command, err := ParseOYECommand(fullCommand)
 	if err != nil {
-		return fmt.Errorf("failed to confirm project: %w", err)
+		return fmt.Errorf("failed to parse command: %w", err)
 	}
 
-	percentage, err := confirmPercentage(commandText)
+	projectFilter, err := confirmProjects(command)
 	if err != nil {
-		return fmt.Errorf("failed to confirm percentage: %w", err)
+		return fmt.Errorf("failed to confirm projects: %w", err)
 	}
 
-	// Stricter validation mirroring HTTP handler behavior
-	fields := strings.Fields(commandText)
-	firstWord := ""
-	if len(fields) > 0 {
-		firstWord = fields[0]
-	}
-	if firstWord == "project" && projectName == "" {
-		return fmt.Errorf("missing project name. Use: `/oye project [project name] for [period]` or `/oye project [project name] over [percentage] for [period]`")
-	}
-	if firstWord == "over" && percentage == "" {
-		return fmt.Errorf("missing percentage value. Use: `/oye over [percentage] for [period]` or `/oye project [project name] over [percentage] for [period]`")
-	}
-
-	startTime, endTime, err := confirmPeriod(commandText)
-	if err != nil {
-		return fmt.Errorf("failed to confirm period: %w", err)
-	}
+	percentage := command.Percentage
+	startTime, endTime := command.Start, command.End
 
 	// Prepare data like the async path
-	filteredTasks := getFilteredTasksWithTimeout(startTime, endTime, []string{projectName}, percentage)
+	filteredTasks := getFilteredTasksWithTimeout(startTime, endTime, projectFilter.Include, percentage)
+	filteredTasks = filterTasksByProjectNames(filteredTasks, nil, projectFilter.Exclude)
 	if len(filteredTasks) == 0 {
 		logger.Info("No tasks found for test command")
 		return nil
@@ -302,7 +508,7 @@ func RunTestCommand(logger *Logger, fullCommand string, slackUserName string) er
 	}
 
 	// Send via DM path
-	sendTasksGroupedByProjectToUser(userID, grouped)
+	sendReportToUser(userID, NewReport(reportTitle(command), grouped).WithOptions(command.Options))
 	return nil
 }
 
@@ -340,8 +546,8 @@ func filterTasksForUser(userID string, userProjectMap map[string][]string, allTa
 	// Get user's project names (case insensitive)
 	userProjects, exists := userProjectMap[userID]
 	if !exists || len(userProjects) == 0 {
-		// No project assignments for this user: send all tasks
-		return allTasks
+		// No project assignments for this user: nothing is theirs
+		return []TaskInfo{}
 	}
 
 	// Convert to lowercase for comparison
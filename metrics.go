@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Histogram buckets in seconds
+var (
+	requestDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	jobDurationBuckets     = []float64{0.1, 0.5, 1, 5, 15, 30, 60, 120, 300, 600}
+)
+
+var (
+	cronJobRuns = newMetricCounter("oye_cron_job_runs_total",
+		"Scheduled job runs.", "job")
+	cronJobFailures = newMetricCounter("oye_cron_job_failures_total",
+		"Scheduled job runs that returned an error.", "job")
+	cronJobDuration = newMetricHistogram("oye_cron_job_duration_seconds",
+		"How long scheduled jobs run.", jobDurationBuckets, "job")
+	httpClientDuration = newMetricHistogram("oye_http_client_request_duration_seconds",
+		"Latency of each outbound HTTP attempt, such as TimeCamp API calls.", requestDurationBuckets, "target")
+	httpClientRetries = newMetricCounter("oye_http_client_retries_total",
+		"Outbound HTTP attempts retried after an error or retryable status.", "target")
+	slackRequests = newMetricCounter("oye_slack_requests_total",
+		"Slack Web API calls by outcome: ok, error or ratelimited.", "method", "result")
+	slashCommandDuration = newMetricHistogram("oye_slash_command_duration_seconds",
+		"Time to answer /oye slash commands.", requestDurationBuckets)
+)
+
+// metricFamily is one metric name with all its label combinations
+type metricFamily interface {
+	writeTo(buf *bytes.Buffer)
+}
+
+var (
+	metricRegistryMu sync.Mutex
+	metricRegistry   []metricFamily
+)
+
+func registerMetric(family metricFamily) {
+	metricRegistryMu.Lock()
+	defer metricRegistryMu.Unlock()
+	metricRegistry = append(metricRegistry, family)
+}
+
+// metricLabels renders label names and values as {name="value",...}, empty without labels
+func metricLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeMetricLabel(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeMetricLabel(value string) string {
+	return metricLabelEscaper.Replace(value)
+}
+
+func formatMetricValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// metricKey joins label values into a map key
+func metricKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+func writeMetricHeader(buf *bytes.Buffer, name, help, kind string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// metricCounter is a counter with a fixed set of label names
+type metricCounter struct {
+	name, help string
+	labels     []string
+	mu         sync.Mutex
+	values     map[string]float64
+	labelSets  map[string][]string
+}
+
+func newMetricCounter(name, help string, labels ...string) *metricCounter {
+	counter := &metricCounter{name: name, help: help, labels: labels, values: map[string]float64{}, labelSets: map[string][]string{}}
+	registerMetric(counter)
+	return counter
+}
+
+// Inc adds one for the given label values, in the order the labels were declared
+func (c *metricCounter) Inc(values ...string) {
+	key := metricKey(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key]++
+	c.labelSets[key] = values
+}
+
+func (c *metricCounter) writeTo(buf *bytes.Buffer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	writeMetricHeader(buf, c.name, c.help, "counter")
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(buf, "%s%s %s\n", c.name, metricLabels(c.labels, c.labelSets[key]), formatMetricValue(c.values[key]))
+	}
+}
+
+// metricHistogram counts observations into cumulative buckets per label combination
+type metricHistogram struct {
+	name, help string
+	buckets    []float64
+	labels     []string
+	mu         sync.Mutex
+	series     map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // per bucket, not cumulative
+	count       uint64
+	sum         float64
+}
+
+func newMetricHistogram(name, help string, buckets []float64, labels ...string) *metricHistogram {
+	histogram := &metricHistogram{name: name, help: help, buckets: buckets, labels: labels, series: map[string]*histogramSeries{}}
+	registerMetric(histogram)
+	return histogram
+}
+
+// Observe records a value for the given label values
+func (h *metricHistogram) Observe(value float64, values ...string) {
+	key := metricKey(values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{labelValues: values, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			series.counts[i]++
+			break
+		}
+	}
+	series.count++
+	series.sum += value
+}
+
+// ObserveSince records the time passed since start in seconds
+func (h *metricHistogram) ObserveSince(start time.Time, values ...string) {
+	h.Observe(time.Since(start).Seconds(), values...)
+}
+
+func (h *metricHistogram) writeTo(buf *bytes.Buffer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	writeMetricHeader(buf, h.name, h.help, "histogram")
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	bucketLabels := append(append([]string(nil), h.labels...), "le")
+	for _, key := range keys {
+		series := h.series[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += series.counts[i]
+			values := append(append([]string(nil), series.labelValues...), formatMetricValue(bound))
+			fmt.Fprintf(buf, "%s_bucket%s %d\n", h.name, metricLabels(bucketLabels, values), cumulative)
+		}
+		values := append(append([]string(nil), series.labelValues...), "+Inf")
+		fmt.Fprintf(buf, "%s_bucket%s %d\n", h.name, metricLabels(bucketLabels, values), series.count)
+		labels := metricLabels(h.labels, series.labelValues)
+		fmt.Fprintf(buf, "%s_sum%s %s\n", h.name, labels, formatMetricValue(series.sum))
+		fmt.Fprintf(buf, "%s_count%s %d\n", h.name, labels, series.count)
+	}
+}
+
+// writeDatabaseMetrics adds gauges read from the database at scrape time
+func writeDatabaseMetrics(buf *bytes.Buffer) {
+	logger := GetGlobalLogger()
+	db, err := GetDB()
+	if err != nil {
+		logger.Warnf("Skipping database metrics: %v", err)
+		return
+	}
+
+	if count, err := GetOrphanedTimeEntriesCount(db); err != nil {
+		logger.Warnf("Skipping orphaned time entries metric: %v", err)
+	} else {
+		writeMetricHeader(buf, "oye_orphaned_time_entries", "Time entries waiting for their task to be synced.", "gauge")
+		fmt.Fprintf(buf, "oye_orphaned_time_entries %d\n", count)
+	}
+
+	projects, err := GetAllProjects(db)
+	if err != nil {
+		logger.Warnf("Skipping tasks over threshold metric: %v", err)
+		return
+	}
+	dashboards, err := loadProjectDashboards(db, projects, time.Now())
+	if err != nil {
+		logger.Warnf("Skipping tasks over threshold metric: %v", err)
+		return
+	}
+	writeMetricHeader(buf, "oye_project_tasks_over_threshold",
+		"Estimated tasks with time this month at or over 100% of their estimate.", "gauge")
+	for _, dashboard := range dashboards {
+		fmt.Fprintf(buf, "oye_project_tasks_over_threshold%s %d\n",
+			metricLabels([]string{"project"}, []string{dashboard.Name}), dashboard.OverBudget)
+	}
+}
+
+// handleMetrics serves all metrics in the Prometheus text exposition format
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	metricRegistryMu.Lock()
+	families := append([]metricFamily(nil), metricRegistry...)
+	metricRegistryMu.Unlock()
+	for _, family := range families {
+		family.writeTo(&buf)
+	}
+	writeDatabaseMetrics(&buf)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// instrumentSlashCommand records how long a slash command takes to answer
+func instrumentSlashCommand(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer slashCommandDuration.ObserveSince(time.Now())
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newMetricsServer returns the server for /metrics on METRICS_PORT, or nil when the port isn't set.
+// The endpoint is unauthenticated, its own port keeps it off the public listener.
+func newMetricsServer() *http.Server {
+	port := getEnvString("METRICS_PORT", "")
+	if port == "" {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", handleMetrics)
+	return &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+}
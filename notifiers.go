@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Notifier delivers a task report over one delivery channel
+type Notifier interface {
+	// Name identifies the channel in logs
+	Name() string
+	// Notify renders the report for the channel and delivers it to the recipient
+	Notify(recipient string, report Report) error
+}
+
+// SlackNotifier posts reports to Slack in one of the delivery modes:
+// thread (header post with the report in its thread), share (report in the channel)
+// or private (ephemeral messages through a slash command's response_url).
+// The recipient is a channel ID or, for direct messages, a user ID.
+type SlackNotifier struct {
+	Mode        string // SLACK_DELIVERY_THREAD when empty
+	ResponseURL string // required for SLACK_DELIVERY_PRIVATE
+	RequestedBy string // user ID credited in shared reports, optional
+	ThreadTS    string // existing thread the report is posted into, optional
+	Digest      string // recurring digest kind; re-runs for the same period update the earlier message, optional
+
+	// Set by Notify: the channel and message the report's thread hangs off, empty for private delivery
+	PostedChannel string
+	PostedTS      string
+}
+
+// NewSlackNotifier creates a Slack notifier that posts reports in a thread
+func NewSlackNotifier() *SlackNotifier {
+	return &SlackNotifier{Mode: SLACK_DELIVERY_THREAD}
+}
+
+// NewSlackCommandNotifier creates a Slack notifier answering a slash command in the given mode
+func NewSlackCommandNotifier(mode string, req *SlackCommandRequest) *SlackNotifier {
+	return &SlackNotifier{Mode: mode, ResponseURL: req.ResponseURL, RequestedBy: req.UserID, ThreadTS: req.ThreadTS, Digest: req.Digest}
+}
+
+func (n *SlackNotifier) Name() string {
+	return "slack"
+}
+
+func (n *SlackNotifier) Notify(recipient string, report Report) error {
+	switch n.Mode {
+	case SLACK_DELIVERY_PRIVATE:
+		return n.notifyPrivate(report)
+	case SLACK_DELIVERY_SHARE:
+		return n.notifyShare(recipient, report)
+	default:
+		if n.Digest != "" && n.ThreadTS == "" {
+			return n.notifyDigest(recipient, report)
+		}
+		return n.notifyThread(recipient, report)
+	}
+}
+
+// headerBlocks returns the summary header shown above or instead of the report
+func (n *SlackNotifier) headerBlocks(report Report) []Block {
+	blocks := []Block{SectionBlock("*" + report.HeaderText() + "*")}
+	if n.RequestedBy != "" {
+		blocks = append(blocks, ContextBlock(fmt.Sprintf("Requested by <@%s>", n.RequestedBy)))
+	}
+	return blocks
+}
+
+// notifyThread posts the report header and sends the report as threaded replies.
+// With ThreadTS set, the header is a reply too and the report continues in that thread.
+func (n *SlackNotifier) notifyThread(recipient string, report Report) error {
+	if recipient == "" {
+		return fmt.Errorf("no Slack channel or user provided")
+	}
+
+	if n.ThreadTS != "" {
+		if err := sendSlackMessage(recipient, n.headerBlocks(report), n.ThreadTS); err != nil {
+			return fmt.Errorf("failed to post report header in thread: %w", err)
+		}
+		n.PostedChannel, n.PostedTS = recipient, n.ThreadTS
+		return n.sendThreadReplies(recipient, n.ThreadTS, combineProjectsIntoMessages(report))
+	}
+
+	// Post the thread anchor via bot API and use returned ts to avoid races
+	slackClient := NewSlackAPIClient()
+	initResp, err := slackClient.sendSlackAPIRequestWithResponse("chat.postMessage", SlackMessage{
+		Channel: recipient,
+		Text:    report.HeaderText(),
+		Blocks:  n.headerBlocks(report),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to post initial thread message: %w", err)
+	}
+	n.PostedChannel, n.PostedTS = initResp.Channel, initResp.Timestamp
+
+	return n.sendThreadReplies(recipient, initResp.Timestamp, combineProjectsIntoMessages(report))
+}
+
+// notifyShare posts the header and as much of the report as fits in one channel message,
+// continuing in its thread when the report is larger
+func (n *SlackNotifier) notifyShare(recipient string, report Report) error {
+	if recipient == "" {
+		return fmt.Errorf("no Slack channel provided")
+	}
+
+	messages := combineProjectsIntoMessages(report)
+	blocks := n.headerBlocks(report)
+	if len(messages) > 0 && len(blocks)+len(messages[0])+1 <= MAX_SLACK_BLOCKS {
+		blocks = append(blocks, DividerBlock())
+		blocks = append(blocks, messages[0]...)
+		messages = messages[1:]
+	}
+	if len(messages) > 0 {
+		blocks = append(blocks, ContextBlock("Continued in thread 👇").WithBlockID(REPORT_CONTINUED_BLOCK_ID))
+	}
+
+	slackClient := NewSlackAPIClient()
+	postResp, err := slackClient.sendSlackAPIRequestWithResponse("chat.postMessage", SlackMessage{
+		Channel: recipient,
+		Text:    report.HeaderText(),
+		Blocks:  blocks,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to post shared report: %w", err)
+	}
+	n.PostedChannel, n.PostedTS = postResp.Channel, postResp.Timestamp
+
+	return n.sendThreadReplies(recipient, postResp.Timestamp, messages)
+}
+
+// sendThreadReplies sends block messages as replies in a thread
+func (n *SlackNotifier) sendThreadReplies(recipient, threadTimestamp string, messages [][]Block) error {
+	logger := GetGlobalLogger()
+
+	failed := 0
+	for i, messageBlocks := range messages {
+		logger.Infof("Sending combined message %d/%d to %s with %d blocks", i+1, len(messages), recipient, len(messageBlocks))
+		if err := sendSlackMessage(recipient, messageBlocks, threadTimestamp); err != nil {
+			logger.Errorf("Failed to send combined message %d to %s: %v", i+1, recipient, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d of %d messages", failed, len(messages))
+	}
+	return nil
+}
+
+// notifyPrivate sends the report as ephemeral messages through the slash command's response_url.
+// Slack limits a response_url to a few posts, so oversized reports are cut short with a hint.
+func (n *SlackNotifier) notifyPrivate(report Report) error {
+	if n.ResponseURL == "" {
+		return fmt.Errorf("no response_url provided for private delivery")
+	}
+
+	messages := combineProjectsIntoMessages(report)
+	header := n.headerBlocks(report)
+	if len(messages) > 0 && len(header)+len(messages[0]) <= MAX_SLACK_BLOCKS {
+		messages[0] = append(header, messages[0]...)
+	} else {
+		messages = append([][]Block{header}, messages...)
+	}
+
+	if len(messages) > SLACK_RESPONSE_URL_MAX_POSTS {
+		omitted := len(messages) - (SLACK_RESPONSE_URL_MAX_POSTS - 1)
+		messages = messages[:SLACK_RESPONSE_URL_MAX_POSTS-1]
+		messages = append(messages, []Block{SectionBlock(fmt.Sprintf(
+			"⚠️ %d more messages were left out. Narrow the report down with `top 10` or `summary`, or use `share` to post all of it.", omitted))})
+	}
+
+	for i, messageBlocks := range messages {
+		err := postSlackResponse(n.ResponseURL, SlackCommandResponse{
+			ResponseType: "ephemeral",
+			Text:         report.HeaderText(),
+			Blocks:       messageBlocks,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send private message %d/%d: %w", i+1, len(messages), err)
+		}
+	}
+	return nil
+}
+
+// chatWebhookNotifiersFromEnv returns the Mattermost and Teams notifiers that have a webhook configured
+func chatWebhookNotifiersFromEnv() []Notifier {
+	var notifiers []Notifier
+	if url := os.Getenv("MATTERMOST_WEBHOOK_URL"); url != "" {
+		notifiers = append(notifiers, NewMattermostNotifier(url, os.Getenv("MATTERMOST_CHANNEL")))
+	}
+	if url := os.Getenv("TEAMS_WEBHOOK_URL"); url != "" {
+		notifiers = append(notifiers, NewTeamsNotifier(url))
+	}
+	return notifiers
+}
+
+// broadcastReportToChatWebhooks sends a report to every configured Mattermost and Teams webhook
+func broadcastReportToChatWebhooks(report Report) {
+	logger := GetGlobalLogger()
+	for _, notifier := range chatWebhookNotifiersFromEnv() {
+		if err := notifier.Notify("", report); err != nil {
+			logger.Errorf("Failed to send report via %s: %v", notifier.Name(), err)
+			continue
+		}
+		logger.Infof("Sent report '%s' via %s", report.Title, notifier.Name())
+	}
+}
+
+// postSlackResponse posts a message to a Slack response_url after checking it against Slack's limits
+func postSlackResponse(url string, response SlackCommandResponse) error {
+	if err := response.Validate(); err != nil {
+		return fmt.Errorf("invalid Slack response: %w", err)
+	}
+	return postJSONToWebhook(url, response)
+}
+
+// postJSONToWebhook posts a JSON payload to an incoming webhook URL with retries
+func postJSONToWebhook(url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	request, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	response, err := DoHTTPWithRetry(client, request, webhookRetryConfig())
+	if err != nil {
+		return err
+	}
+	defer CloseWithErrorLog(response.Body, "webhook response body")
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("webhook returned status %d: %s", response.StatusCode, strings.TrimSpace(string(responseBody)))
+	}
+	return nil
+}
@@ -0,0 +1,658 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// The /oye command language:
+//
+//	command  = clause { clause }
+//	clause   = "project" names | "all" [ "except" names ] | "over" percent | "for" period | range
+//	         | "sort" [ "by" ] ( "time" | "percent" | "name" ) | "top" number | "summary"
+//	         | "private" | "thread" | "share" | "export" ( "csv" | "xlsx" ) | "chart"
+//	names    = name { "," name }
+//	name     = quoted string | words (ending before a keyword that starts a valid clause)
+//	percent  = number [ "%" ]
+//	period   = "today" | "yesterday" | ("this" | "last") ("week" | "month" | "quarter" | "year")
+//	         | "last" number ("day" | "days") | "week" number [ year ] | range
+//	range    = "since" (weekday | date | "yesterday") | "from" date "to" date
+//	date     = YYYY-MM-DD
+//
+// Keywords are matched case-insensitively, everything else keeps its original case.
+// Names may be projects or saved project groups; they are resolved after parsing.
+
+// OYECommand is a parsed /oye report command
+type OYECommand struct {
+	Projects         []string // empty means all projects
+	ExcludedProjects []string
+	AllProjects      bool // "all" was given explicitly
+	Percentage       string
+	Period           string // the period as written, for logging and headers
+	Start            time.Time
+	End              time.Time
+	Options          ReportOptions
+	Delivery         string // SLACK_DELIVERY_* mode, empty when not given
+	Export           string // EXPORT_FORMAT_* of a spreadsheet export, empty for a Slack report
+	Chart            bool   // upload burn-up charts along with the report
+}
+
+// oyeToken is a single token of a command with its byte offset in the input
+type oyeToken struct {
+	Text   string
+	Quoted bool
+	Pos    int
+}
+
+// lower returns the token text for keyword comparison
+func (t oyeToken) lower() string {
+	if t.Quoted {
+		return ""
+	}
+	return strings.ToLower(t.Text)
+}
+
+// OYEParseError describes a parse failure and the token that caused it
+type OYEParseError struct {
+	Input   string
+	Pos     int
+	Token   string
+	Message string
+}
+
+func (e *OYEParseError) Error() string {
+	if e.Token == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (at \"%s\")", e.Message, e.Token)
+}
+
+// Pointer renders the input with a caret under the offending token, for display in Slack
+func (e *OYEParseError) Pointer() string {
+	width := len([]rune(e.Token))
+	if width == 0 {
+		width = 1
+	}
+	prefix := len([]rune(e.Input[:e.Pos]))
+	return e.Input + "\n" + strings.Repeat(" ", prefix) + strings.Repeat("^", width)
+}
+
+// formatOYEError formats an error for an ephemeral Slack response, pointing at the token for parse errors
+func formatOYEError(err error) string {
+	if parseErr, ok := err.(*OYEParseError); ok {
+		return fmt.Sprintf("%s\n```%s```", parseErr.Error(), parseErr.Pointer())
+	}
+	return err.Error()
+}
+
+// oyeClauseKeywords are the words that start a clause
+var oyeClauseKeywords = map[string]bool{
+	"project": true,
+	"all":     true,
+	"over":    true,
+	"for":     true,
+	"from":    true,
+	"since":   true,
+	"sort":    true,
+	"top":     true,
+	"summary": true,
+	"private": true,
+	"thread":  true,
+	"share":   true,
+	"export":  true,
+	"chart":   true,
+}
+
+// isOYECommandStart reports whether the text begins with a clause keyword
+func isOYECommandStart(text string) bool {
+	tokens, err := tokenizeOYECommand(text)
+	if err != nil {
+		// Let the parser report the tokenizer error
+		return true
+	}
+	return len(tokens) > 0 && oyeClauseKeywords[tokens[0].lower()]
+}
+
+// tokenizeOYECommand splits a command into words, commas and quoted strings.
+// Both straight and Slack's curly quotes delimit quoted strings.
+func tokenizeOYECommand(input string) ([]oyeToken, error) {
+	var tokens []oyeToken
+	runes := []rune(input)
+	offsets := make([]int, len(runes)+1)
+	for i, offset := 0, 0; i < len(runes); i++ {
+		offsets[i] = offset
+		offset += len(string(runes[i]))
+		offsets[i+1] = offset
+	}
+
+	closingQuote := map[rune]rune{'"': '"', '“': '”', '\'': '\'', '‘': '’'}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if unicode.IsSpace(r) {
+			i++
+			continue
+		}
+
+		if closing, ok := closingQuote[r]; ok {
+			start := i
+			i++
+			for i < len(runes) && runes[i] != closing && !(closing == '”' && runes[i] == '"') {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &OYEParseError{Input: input, Pos: offsets[start], Token: string(runes[start:]), Message: "unterminated quoted name"}
+			}
+			tokens = append(tokens, oyeToken{Text: string(runes[start+1 : i]), Quoted: true, Pos: offsets[start]})
+			i++
+			continue
+		}
+
+		if r == ',' {
+			tokens = append(tokens, oyeToken{Text: ",", Pos: offsets[i]})
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != ',' {
+			i++
+		}
+		tokens = append(tokens, oyeToken{Text: string(runes[start:i]), Pos: offsets[start]})
+	}
+
+	return tokens, nil
+}
+
+// oyeParser walks the token list of one command
+type oyeParser struct {
+	input  string
+	tokens []oyeToken
+	now    time.Time
+}
+
+func (p *oyeParser) errorAt(index int, format string, args ...interface{}) *OYEParseError {
+	if index >= len(p.tokens) {
+		return &OYEParseError{Input: p.input, Pos: len(p.input), Message: fmt.Sprintf(format, args...)}
+	}
+	token := p.tokens[index]
+	text := token.Text
+	if token.Quoted {
+		text = `"` + text + `"`
+	}
+	return &OYEParseError{Input: p.input, Pos: token.Pos, Token: text, Message: fmt.Sprintf(format, args...)}
+}
+
+// ParseOYECommand parses the text of an /oye report command
+func ParseOYECommand(text string) (*OYECommand, error) {
+	return parseOYECommandAt(text, time.Now())
+}
+
+// parseOYECommandAt parses a command with relative periods resolved against now
+func parseOYECommandAt(text string, now time.Time) (*OYECommand, error) {
+	text = strings.TrimSpace(text)
+	if len(text) >= 4 && strings.EqualFold(text[:4], "/oye") {
+		text = strings.TrimSpace(text[4:])
+	}
+
+	tokens, err := tokenizeOYECommand(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &oyeParser{input: text, tokens: tokens, now: now}
+	if len(tokens) == 0 {
+		return nil, p.errorAt(0, "empty command, try `/oye for yesterday`")
+	}
+
+	command := &OYECommand{}
+	if err := p.parseClauses(0, command); err != nil {
+		return nil, err
+	}
+
+	if command.Period == "" {
+		return nil, p.errorAt(len(tokens), "missing period, add e.g. `for yesterday` or `from 2026-09-01 to 2026-09-15`")
+	}
+
+	// Summaries count tasks over the requested threshold
+	if command.Percentage != "" {
+		command.Options.Threshold, _ = strconv.ParseFloat(command.Percentage, 64)
+	}
+
+	return command, nil
+}
+
+// parseReportOptions parses text made only of `sort by`, `top` and `summary` clauses,
+// as used for configuring scheduled reports
+func parseReportOptions(text string) (ReportOptions, error) {
+	tokens, err := tokenizeOYECommand(strings.TrimSpace(text))
+	if err != nil {
+		return ReportOptions{}, err
+	}
+
+	p := &oyeParser{input: strings.TrimSpace(text), tokens: tokens, now: time.Now()}
+	for i, token := range tokens {
+		switch token.lower() {
+		case "project", "all", "over", "for", "from", "since", "private", "thread", "share", "export", "chart":
+			return ReportOptions{}, p.errorAt(i, "only `sort by`, `top` and `summary` are allowed here")
+		}
+	}
+
+	command := &OYECommand{}
+	if err := p.parseClauses(0, command); err != nil {
+		return ReportOptions{}, err
+	}
+	return command.Options, nil
+}
+
+// parseClauses parses clauses from index to the end of the input into command
+func (p *oyeParser) parseClauses(index int, command *OYECommand) error {
+	for index < len(p.tokens) {
+		keyword := p.tokens[index].lower()
+		var err error
+
+		switch keyword {
+		case "project":
+			if len(command.Projects) > 0 || command.AllProjects {
+				return p.errorAt(index, "projects given twice")
+			}
+			return p.parseNameList(index+1, "project", command, func(c *OYECommand, names []string) {
+				c.Projects = names
+			})
+		case "all":
+			if len(command.Projects) > 0 || command.AllProjects {
+				return p.errorAt(index, "projects given twice")
+			}
+			command.AllProjects = true
+			if index+1 < len(p.tokens) && p.tokens[index+1].lower() == "except" {
+				return p.parseNameList(index+2, "except", command, func(c *OYECommand, names []string) {
+					c.ExcludedProjects = names
+				})
+			}
+			index++
+		case "over":
+			if command.Percentage != "" {
+				return p.errorAt(index, "percentage given twice")
+			}
+			index, err = p.parsePercentage(index+1, command)
+		case "for":
+			if command.Period != "" {
+				return p.errorAt(index, "period given twice")
+			}
+			index, err = p.parsePeriod(index+1, command)
+		case "from", "since":
+			if command.Period != "" {
+				return p.errorAt(index, "period given twice")
+			}
+			index, err = p.parsePeriod(index, command)
+		case "sort":
+			if command.Options.SortBy != "" {
+				return p.errorAt(index, "sort order given twice")
+			}
+			index, err = p.parseSort(index+1, command)
+		case "top":
+			if command.Options.Top != 0 {
+				return p.errorAt(index, "`top` given twice")
+			}
+			index, err = p.parseTop(index+1, command)
+		case "summary":
+			if command.Options.Summary {
+				return p.errorAt(index, "`summary` given twice")
+			}
+			command.Options.Summary = true
+			index++
+		case "private", "thread", "share":
+			if command.Delivery != "" {
+				return p.errorAt(index, "`private`, `thread` and `share` can't be combined")
+			}
+			command.Delivery = keyword
+			index++
+		case "export":
+			if command.Export != "" {
+				return p.errorAt(index, "`export` given twice")
+			}
+			if index+1 >= len(p.tokens) || (p.tokens[index+1].lower() != EXPORT_FORMAT_CSV && p.tokens[index+1].lower() != EXPORT_FORMAT_XLSX) {
+				return p.errorAt(index+1, "expected `csv` or `xlsx` after `export`")
+			}
+			if command.Chart {
+				return p.errorAt(index, "`export` and `chart` can't be combined")
+			}
+			command.Export = p.tokens[index+1].lower()
+			index += 2
+		case "chart":
+			if command.Chart {
+				return p.errorAt(index, "`chart` given twice")
+			}
+			if command.Export != "" {
+				return p.errorAt(index, "`export` and `chart` can't be combined")
+			}
+			command.Chart = true
+			index++
+		default:
+			return p.errorAt(index, "unexpected word, expected `project`, `all`, `over`, `for`, `from`, `since`, `sort by`, `top`, `summary`, `private`, `thread`, `share`, `export` or `chart`")
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseNameList parses a comma separated list of project or group names and the clauses after it.
+// Unquoted names end at the first clause keyword after which the rest of the command parses,
+// so names like "Plan for Growth" work without quotes.
+func (p *oyeParser) parseNameList(index int, keyword string, command *OYECommand, assign func(*OYECommand, []string)) error {
+	if index >= len(p.tokens) || oyeClauseKeywords[p.tokens[index].lower()] {
+		return p.errorAt(index, "missing project name after `%s`", keyword)
+	}
+
+	// When no split parses, report the error that got furthest into the command
+	var bestErr *OYEParseError
+	for end := index + 1; end <= len(p.tokens); end++ {
+		if end < len(p.tokens) && !oyeClauseKeywords[p.tokens[end].lower()] {
+			continue
+		}
+
+		names, err := p.splitNames(index, end)
+		if err == nil {
+			candidate := *command
+			assign(&candidate, names)
+			err = p.parseClauses(end, &candidate)
+			if err == nil {
+				// A name swallowing the rest of the command leaves no period, so the
+				// earlier failed split is the better explanation of what went wrong
+				if end == len(p.tokens) && candidate.Period == "" && bestErr != nil {
+					return bestErr
+				}
+				*command = candidate
+				return nil
+			}
+		}
+		if parseErr, ok := err.(*OYEParseError); ok && (bestErr == nil || parseErr.Pos > bestErr.Pos) {
+			bestErr = parseErr
+		}
+	}
+
+	return bestErr
+}
+
+// splitNames splits tokens[from:to] at commas into names
+func (p *oyeParser) splitNames(from, to int) ([]string, error) {
+	var names []string
+	segmentStart := from
+	for i := from; i <= to; i++ {
+		if i < to && (p.tokens[i].Quoted || p.tokens[i].Text != ",") {
+			continue
+		}
+		if i == segmentStart {
+			return nil, p.errorAt(i, "missing project name")
+		}
+		name := strings.TrimSpace(p.joinTokens(segmentStart, i))
+		if name == "" {
+			return nil, p.errorAt(segmentStart, "project name is empty")
+		}
+		names = append(names, name)
+		segmentStart = i + 1
+	}
+	return names, nil
+}
+
+// joinTokens joins the original text of tokens[from:to]
+func (p *oyeParser) joinTokens(from, to int) string {
+	words := make([]string, 0, to-from)
+	for _, token := range p.tokens[from:to] {
+		words = append(words, token.Text)
+	}
+	return strings.Join(words, " ")
+}
+
+// parsePercentage parses the threshold after `over`
+func (p *oyeParser) parsePercentage(index int, command *OYECommand) (int, error) {
+	if index >= len(p.tokens) {
+		return index, p.errorAt(index, "missing percentage after `over`")
+	}
+
+	value := strings.TrimSuffix(p.tokens[index].Text, "%")
+	next := index + 1
+	// Allow "over 80 %"
+	if next < len(p.tokens) && p.tokens[next].Text == "%" {
+		next++
+	}
+
+	percentage, err := strconv.ParseFloat(value, 64)
+	if err != nil || p.tokens[index].Quoted {
+		return index, p.errorAt(index, "expected a percentage like `80` or `80%%`")
+	}
+	if percentage <= 0 {
+		return index, p.errorAt(index, "percentage must be greater than 0")
+	}
+
+	command.Percentage = value
+	return next, nil
+}
+
+// parseSort parses the sort key after `sort`, with an optional `by`
+func (p *oyeParser) parseSort(index int, command *OYECommand) (int, error) {
+	if index < len(p.tokens) && p.tokens[index].lower() == "by" {
+		index++
+	}
+	if index >= len(p.tokens) {
+		return index, p.errorAt(index, "missing sort order, use `time`, `percent` or `name`")
+	}
+
+	switch p.tokens[index].lower() {
+	case "time":
+		command.Options.SortBy = REPORT_SORT_TIME
+	case "percent", "percentage", "%":
+		command.Options.SortBy = REPORT_SORT_PERCENT
+	case "name":
+		command.Options.SortBy = REPORT_SORT_NAME
+	default:
+		return index, p.errorAt(index, "unknown sort order, use `time`, `percent` or `name`")
+	}
+	return index + 1, nil
+}
+
+// parseTop parses the count after `top`
+func (p *oyeParser) parseTop(index int, command *OYECommand) (int, error) {
+	if index >= len(p.tokens) {
+		return index, p.errorAt(index, "missing number after `top`")
+	}
+
+	count, err := strconv.Atoi(p.tokens[index].Text)
+	if err != nil || p.tokens[index].Quoted {
+		return index, p.errorAt(index, "expected a number like `top 10`")
+	}
+	if count <= 0 {
+		return index, p.errorAt(index, "`top` needs a number greater than 0")
+	}
+
+	command.Options.Top = count
+	return index + 1, nil
+}
+
+// parsePeriod parses a period starting at index and resolves it to a time range
+func (p *oyeParser) parsePeriod(index int, command *OYECommand) (int, error) {
+	if index >= len(p.tokens) {
+		return index, p.errorAt(index, "missing period, e.g. `yesterday`, `last week` or `from 2026-09-01 to 2026-09-15`")
+	}
+
+	now := p.now
+	today := startOfDay(now)
+	start := index
+	var from, to time.Time
+
+	switch p.tokens[index].lower() {
+	case "today":
+		from, to = today, today
+		index++
+	case "yesterday":
+		from = today.AddDate(0, 0, -1)
+		to = from
+		index++
+	case "this", "last":
+		relative := p.tokens[index].lower()
+		if index+1 >= len(p.tokens) {
+			return index, p.errorAt(index+1, "expected `week`, `month`, `quarter`, `year` or a number of days after `%s`", relative)
+		}
+
+		// "last N days"
+		if relative == "last" {
+			if days, err := strconv.Atoi(p.tokens[index+1].Text); err == nil {
+				if days <= 0 {
+					return index, p.errorAt(index+1, "number of days must be positive")
+				}
+				if index+2 >= len(p.tokens) || (p.tokens[index+2].lower() != "days" && p.tokens[index+2].lower() != "day") {
+					return index, p.errorAt(index+2, "expected `days` after `last %d`", days)
+				}
+				from, to = today.AddDate(0, 0, -days), today
+				index += 3
+				break
+			}
+		}
+
+		offset := 0
+		if relative == "last" {
+			offset = -1
+		}
+
+		switch p.tokens[index+1].lower() {
+		case "week":
+			monday := startOfWeek(today).AddDate(0, 0, 7*offset)
+			from, to = monday, monday.AddDate(0, 0, 6)
+		case "month":
+			first := time.Date(today.Year(), today.Month()+time.Month(offset), 1, 0, 0, 0, 0, today.Location())
+			from, to = first, first.AddDate(0, 1, -1)
+		case "quarter":
+			quarterMonth := time.Month((int(today.Month())-1)/3*3 + 1)
+			first := time.Date(today.Year(), quarterMonth+time.Month(3*offset), 1, 0, 0, 0, 0, today.Location())
+			from, to = first, first.AddDate(0, 3, -1)
+		case "year":
+			first := time.Date(today.Year()+offset, time.January, 1, 0, 0, 0, 0, today.Location())
+			from, to = first, first.AddDate(1, 0, -1)
+		default:
+			return index, p.errorAt(index+1, "unknown period, expected `week`, `month`, `quarter` or `year`")
+		}
+		index += 2
+	case "week":
+		if index+1 >= len(p.tokens) {
+			return index, p.errorAt(index+1, "missing week number after `week`")
+		}
+		week, err := strconv.Atoi(p.tokens[index+1].Text)
+		if err != nil {
+			return index, p.errorAt(index+1, "expected an ISO week number like `week 38`")
+		}
+		year, _ := today.ISOWeek()
+		next := index + 2
+		if next < len(p.tokens) {
+			if y, err := strconv.Atoi(p.tokens[next].Text); err == nil && y >= 1000 && y <= 9999 {
+				year = y
+				next++
+			}
+		}
+		monday, ok := isoWeekStart(year, week, today.Location())
+		if !ok {
+			return index, p.errorAt(index+1, "week %d does not exist in %d", week, year)
+		}
+		from, to = monday, monday.AddDate(0, 0, 6)
+		index = next
+	case "since":
+		if index+1 >= len(p.tokens) {
+			return index, p.errorAt(index+1, "expected a weekday or a date like 2026-09-01 after `since`")
+		}
+		anchor := p.tokens[index+1]
+		if weekday, ok := parseWeekday(anchor.lower()); ok {
+			daysBack := (int(today.Weekday()) - int(weekday) + 7) % 7
+			from = today.AddDate(0, 0, -daysBack)
+		} else if anchor.lower() == "yesterday" {
+			from = today.AddDate(0, 0, -1)
+		} else if date, err := time.ParseInLocation("2006-01-02", anchor.Text, today.Location()); err == nil {
+			from = date
+		} else {
+			return index, p.errorAt(index+1, "expected a weekday or a date like 2026-09-01")
+		}
+		if from.After(today) {
+			return index, p.errorAt(index+1, "date is in the future")
+		}
+		to = today
+		index += 2
+	case "from":
+		if index+1 >= len(p.tokens) {
+			return index, p.errorAt(index+1, "expected a date like 2026-09-01 after `from`")
+		}
+		date, err := time.ParseInLocation("2006-01-02", p.tokens[index+1].Text, today.Location())
+		if err != nil {
+			return index, p.errorAt(index+1, "expected a date like 2026-09-01")
+		}
+		from = date
+		if index+2 >= len(p.tokens) || p.tokens[index+2].lower() != "to" {
+			return index, p.errorAt(index+2, "expected `to` after the start date")
+		}
+		if index+3 >= len(p.tokens) {
+			return index, p.errorAt(index+3, "expected an end date after `to`")
+		}
+		date, err = time.ParseInLocation("2006-01-02", p.tokens[index+3].Text, today.Location())
+		if err != nil {
+			return index, p.errorAt(index+3, "expected a date like 2026-09-15")
+		}
+		if date.Before(from) {
+			return index, p.errorAt(index+3, "end date is before the start date")
+		}
+		to = date
+		index += 4
+	default:
+		return index, p.errorAt(index, "unknown period, try `today`, `yesterday`, `this week`, `last month`, `last 7 days`, `week 38`, `since monday` or `from 2026-09-01 to 2026-09-15`")
+	}
+
+	command.Period = p.joinTokens(start, index)
+	command.Start = from
+	command.End = endOfDay(to)
+	return index, nil
+}
+
+// startOfDay returns midnight of the given day
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// endOfDay returns the last nanosecond of the given day
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 999999999, t.Location())
+}
+
+// startOfWeek returns the Monday of the week containing t
+func startOfWeek(t time.Time) time.Time {
+	weekday := int(t.Weekday())
+	if weekday == 0 { // Sunday
+		weekday = 7
+	}
+	return startOfDay(t).AddDate(0, 0, -(weekday - 1))
+}
+
+// isoWeekStart returns the Monday of ISO week `week` of `year`
+func isoWeekStart(year, week int, location *time.Location) (time.Time, bool) {
+	if week < 1 || week > 53 {
+		return time.Time{}, false
+	}
+	// January 4th is always in week 1
+	monday := startOfWeek(time.Date(year, time.January, 4, 0, 0, 0, 0, location)).AddDate(0, 0, 7*(week-1))
+	if y, w := monday.ISOWeek(); y != year || w != week {
+		return time.Time{}, false
+	}
+	return monday, true
+}
+
+// parseWeekday parses an English weekday name or its three-letter abbreviation
+func parseWeekday(word string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if word == name || word == name[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProjectGroup is a saved, named set of projects (e.g. "client-work") usable in /oye filters
+type ProjectGroup struct {
+	ID        int
+	Name      string
+	CreatedBy string
+	CreatedAt time.Time
+	Projects  []Project
+}
+
+// GetProjectGroups returns all project groups with their projects, ordered by name
+func GetProjectGroups(db *sql.DB) ([]ProjectGroup, error) {
+	query := `
+		SELECT g.id, g.name, COALESCE(g.created_by, ''), g.created_at,
+		       p.id, p.name, p.timecamp_task_id, p.created_at, p.updated_at
+		FROM project_groups g
+		LEFT JOIN project_group_members m ON m.group_id = g.id
+		LEFT JOIN projects p ON p.id = m.project_id
+		ORDER BY LOWER(g.name), p.name
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []ProjectGroup
+	for rows.Next() {
+		var group ProjectGroup
+		var projectID, timecampTaskID sql.NullInt64
+		var projectName sql.NullString
+		var projectCreatedAt, projectUpdatedAt sql.NullTime
+		if err := rows.Scan(&group.ID, &group.Name, &group.CreatedBy, &group.CreatedAt,
+			&projectID, &projectName, &timecampTaskID, &projectCreatedAt, &projectUpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan project group row: %w", err)
+		}
+
+		if len(groups) == 0 || groups[len(groups)-1].ID != group.ID {
+			groups = append(groups, group)
+		}
+		if projectID.Valid {
+			current := &groups[len(groups)-1]
+			current.Projects = append(current.Projects, Project{
+				ID:             int(projectID.Int64),
+				Name:           projectName.String,
+				TimeCampTaskID: int(timecampTaskID.Int64),
+				CreatedAt:      projectCreatedAt.Time,
+				UpdatedAt:      projectUpdatedAt.Time,
+			})
+		}
+	}
+
+	return groups, rows.Err()
+}
+
+// GetProjectGroup returns a project group with its projects, nil when it doesn't exist
+func GetProjectGroup(db *sql.DB, groupID int) (*ProjectGroup, error) {
+	groups, err := GetProjectGroups(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if groups[i].ID == groupID {
+			return &groups[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// canDeleteProjectGroup reports whether a user may delete a shared group:
+// the user who created it, or an OYE admin
+func canDeleteProjectGroup(userID string, group ProjectGroup) bool {
+	return isOYEAdmin(userID) || (group.CreatedBy != "" && group.CreatedBy == userID)
+}
+
+// SaveProjectGroup creates a group, or renames and replaces the projects of group groupID
+func SaveProjectGroup(db *sql.DB, groupID int, name, createdBy string, projectIDs []int) (int, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, fmt.Errorf("group name is empty")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Names are unique regardless of case
+	var existingID int
+	err = tx.QueryRow(`SELECT id FROM project_groups WHERE LOWER(name) = LOWER($1)`, name).Scan(&existingID)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to look up project group: %w", err)
+	}
+	if existingID != 0 && existingID != groupID {
+		return 0, fmt.Errorf("a project group named '%s' already exists", name)
+	}
+
+	if groupID == 0 {
+		err = tx.QueryRow(`INSERT INTO project_groups (name, created_by) VALUES ($1, $2) RETURNING id`, name, createdBy).Scan(&groupID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create project group: %w", err)
+		}
+	} else {
+		result, err := tx.Exec(`UPDATE project_groups SET name = $1 WHERE id = $2`, name, groupID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to rename project group: %w", err)
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return 0, fmt.Errorf("project group %d not found", groupID)
+		}
+		if _, err := tx.Exec(`DELETE FROM project_group_members WHERE group_id = $1`, groupID); err != nil {
+			return 0, fmt.Errorf("failed to clear project group members: %w", err)
+		}
+	}
+
+	for _, projectID := range projectIDs {
+		_, err := tx.Exec(`INSERT INTO project_group_members (group_id, project_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, groupID, projectID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add project %d to group: %w", projectID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit project group: %w", err)
+	}
+	return groupID, nil
+}
+
+// DeleteProjectGroup removes a project group and its memberships
+func DeleteProjectGroup(db *sql.DB, groupID int) error {
+	if _, err := db.Exec(`DELETE FROM project_groups WHERE id = $1`, groupID); err != nil {
+		return fmt.Errorf("failed to delete project group %d: %w", groupID, err)
+	}
+	return nil
+}
+
+// resolveProjectNames expands project and group names from a command into exact project names.
+// Group names win over project names; project names use the same fuzzy matching as elsewhere.
+func resolveProjectNames(db *sql.DB, names []string) ([]string, error) {
+	groups, err := GetProjectGroups(db)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var resolved []string
+	add := func(projectName string) {
+		if !seen[strings.ToLower(projectName)] {
+			seen[strings.ToLower(projectName)] = true
+			resolved = append(resolved, projectName)
+		}
+	}
+
+	for _, name := range names {
+		var group *ProjectGroup
+		for i := range groups {
+			if strings.EqualFold(groups[i].Name, name) {
+				group = &groups[i]
+				break
+			}
+		}
+		if group != nil {
+			if len(group.Projects) == 0 {
+				return nil, fmt.Errorf("project group '%s' has no projects", group.Name)
+			}
+			for _, project := range group.Projects {
+				add(project.Name)
+			}
+			continue
+		}
+
+		projects, err := FindProjectsByName(db, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(projects) == 0 {
+			return nil, fmt.Errorf("no project or project group matches '%s'", name)
+		}
+		// Best match first
+		add(projects[0].Name)
+	}
+
+	return resolved, nil
+}
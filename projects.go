@@ -59,6 +59,28 @@ func GetProjectByName(db *sql.DB, name string) (*Project, error) {
 	return &project, nil
 }
 
+// GetProjectByID returns a project by its ID, nil if there is none
+func GetProjectByID(db *sql.DB, id int) (*Project, error) {
+	query := `
+		SELECT id, name, timecamp_task_id, created_at, updated_at 
+		FROM projects 
+		WHERE id = $1
+	`
+
+	var project Project
+	err := db.QueryRow(query, id).Scan(&project.ID, &project.Name,
+		&project.TimeCampTaskID, &project.CreatedAt, &project.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project by id: %w", err)
+	}
+
+	return &project, nil
+}
+
 // FindProjectsByName returns projects that match the given name (fuzzy matching)
 func FindProjectsByName(db *sql.DB, name string) ([]Project, error) {
 	// Try exact match first
@@ -176,3 +198,14 @@ func GetProjectTaskIDs(db *sql.DB, projectTaskID int) ([]int, error) {
 
 	return taskIDs, nil
 }
+
+// GetProjectForTask resolves the project a task belongs to by walking the task hierarchy
+// Returns nil if the task is not under any known project
+func GetProjectForTask(db *sql.DB, allTasks map[int]Task, taskID int) (*Project, error) {
+	projectName := getProjectNameForTask(taskID, allTasks)
+	if projectName == "" {
+		return nil, nil
+	}
+
+	return GetProjectByName(db, projectName)
+}
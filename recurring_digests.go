@@ -0,0 +1,255 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DIGEST_DAILY_UPDATE is the digest kind of the daily update direct messages.
+// Report subscriptions use "subscription:<id>".
+const DIGEST_DAILY_UPDATE = "daily_update"
+
+// RecurringDigest is the message a recurring digest was posted as for one period
+type RecurringDigest struct {
+	Recipient   string // user or channel ID the digest was sent to
+	Digest      string
+	Period      string
+	Channel     string // channel ID returned by Slack, for direct messages the DM's, needed for chat.update
+	MessageTS   string
+	ReplyOutbox []int64 // slack_outbox IDs of the thread replies
+	UpdatedAt   time.Time
+}
+
+// TaskAlertThread is the direct message thread collecting all alerts about a task for one user
+type TaskAlertThread struct {
+	UserID    string
+	TaskID    int
+	Channel   string
+	MessageTS string
+}
+
+// digestPeriod identifies a report's period, e.g. "2026-10-17" or "2026-10-01..2026-10-31"
+func digestPeriod(query *ReportQuery) string {
+	start, end := query.Start.Format("2006-01-02"), query.End.Format("2006-01-02")
+	if start == end {
+		return start
+	}
+	return start + ".." + end
+}
+
+// GetRecurringDigest returns the message a digest was posted as for a period, nil if there is none
+func GetRecurringDigest(db *sql.DB, recipient, digest, period string) (*RecurringDigest, error) {
+	record := RecurringDigest{Recipient: recipient, Digest: digest, Period: period}
+	var replies pq.Int64Array
+	err := db.QueryRow(`
+		SELECT channel, message_ts, reply_outbox_ids, updated_at
+		FROM recurring_digests
+		WHERE recipient = $1 AND digest = $2 AND period = $3`,
+		recipient, digest, period).Scan(&record.Channel, &record.MessageTS, &replies, &record.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s digest of %s: %w", digest, recipient, err)
+	}
+	record.ReplyOutbox = replies
+	return &record, nil
+}
+
+// SaveRecurringDigest records the message and replies a digest was posted as
+func SaveRecurringDigest(db *sql.DB, record RecurringDigest) error {
+	_, err := db.Exec(`
+		INSERT INTO recurring_digests (recipient, digest, period, channel, message_ts, reply_outbox_ids)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (recipient, digest, period) DO UPDATE SET
+			channel = EXCLUDED.channel,
+			message_ts = EXCLUDED.message_ts,
+			reply_outbox_ids = EXCLUDED.reply_outbox_ids,
+			updated_at = CURRENT_TIMESTAMP`,
+		record.Recipient, record.Digest, record.Period, record.Channel, record.MessageTS, pq.Int64Array(record.ReplyOutbox))
+	if err != nil {
+		return fmt.Errorf("failed to save %s digest of %s: %w", record.Digest, record.Recipient, err)
+	}
+	return nil
+}
+
+// GetTaskAlertThread returns a user's alert thread for a task, nil if there is none yet
+func GetTaskAlertThread(db *sql.DB, userID string, taskID int) (*TaskAlertThread, error) {
+	thread := TaskAlertThread{UserID: userID, TaskID: taskID}
+	err := db.QueryRow(`SELECT channel, message_ts FROM task_alert_threads WHERE slack_user_id = $1 AND task_id = $2`,
+		userID, taskID).Scan(&thread.Channel, &thread.MessageTS)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert thread of task %d for %s: %w", taskID, userID, err)
+	}
+	return &thread, nil
+}
+
+// SaveTaskAlertThread records the message starting a task's alert thread
+func SaveTaskAlertThread(db *sql.DB, thread TaskAlertThread) error {
+	_, err := db.Exec(`
+		INSERT INTO task_alert_threads (slack_user_id, task_id, channel, message_ts)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (slack_user_id, task_id) DO UPDATE SET
+			channel = EXCLUDED.channel,
+			message_ts = EXCLUDED.message_ts,
+			updated_at = CURRENT_TIMESTAMP`,
+		thread.UserID, thread.TaskID, thread.Channel, thread.MessageTS)
+	if err != nil {
+		return fmt.Errorf("failed to save alert thread of task %d for %s: %w", thread.TaskID, thread.UserID, err)
+	}
+	return nil
+}
+
+// touchTaskAlertThread marks a thread as used, keeping it from cleanup
+func touchTaskAlertThread(db *sql.DB, userID string, taskID int) error {
+	_, err := db.Exec(`UPDATE task_alert_threads SET updated_at = CURRENT_TIMESTAMP WHERE slack_user_id = $1 AND task_id = $2`,
+		userID, taskID)
+	return err
+}
+
+// cleanupDigestMessages forgets digests not re-run for 45 days and alert threads quiet for three months.
+// Only the records go, the messages stay in Slack; a later alert simply starts a new thread.
+func cleanupDigestMessages(db *sql.DB) (int64, error) {
+	digests, err := db.Exec(`DELETE FROM recurring_digests WHERE updated_at < NOW() - INTERVAL '45 days'`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up recurring digests: %w", err)
+	}
+	threads, err := db.Exec(`DELETE FROM task_alert_threads WHERE updated_at < NOW() - INTERVAL '90 days'`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up task alert threads: %w", err)
+	}
+	removedDigests, _ := digests.RowsAffected()
+	removedThreads, _ := threads.RowsAffected()
+	return removedDigests + removedThreads, nil
+}
+
+// queueThreadReplies queues report messages as replies in a thread and returns their outbox IDs
+func queueThreadReplies(db *sql.DB, channel, threadTS string, messages [][]Block) ([]int64, error) {
+	ids := make([]int64, 0, len(messages))
+	for i, blocks := range messages {
+		id, err := EnqueueSlackMessage(db, SlackMessage{Channel: channel, ThreadTS: threadTS, Blocks: blocks})
+		if err != nil {
+			return ids, fmt.Errorf("failed to queue reply %d/%d: %w", i+1, len(messages), err)
+		}
+		ids = append(ids, int64(id))
+	}
+	return ids, nil
+}
+
+// notifyDigest posts a recurring digest like notifyThread the first time it runs for a period.
+// Re-runs update that message in place and replace its thread replies.
+func (n *SlackNotifier) notifyDigest(recipient string, report Report) error {
+	if recipient == "" {
+		return fmt.Errorf("no Slack channel or user provided")
+	}
+	if report.Query == nil {
+		return n.notifyThread(recipient, report)
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	period := digestPeriod(report.Query)
+	existing, err := GetRecurringDigest(db, recipient, n.Digest, period)
+	if err != nil {
+		return err
+	}
+
+	slackClient := NewSlackAPIClient()
+	record := RecurringDigest{Recipient: recipient, Digest: n.Digest, Period: period}
+	if existing == nil {
+		resp, err := slackClient.sendSlackAPIRequestWithResponse("chat.postMessage", SlackMessage{
+			Channel: recipient,
+			Text:    report.HeaderText(),
+			Blocks:  n.headerBlocks(report),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post digest message: %w", err)
+		}
+		record.Channel, record.MessageTS = resp.Channel, resp.Timestamp
+	} else {
+		blocks := append(n.headerBlocks(report), ContextBlock(fmt.Sprintf("%s Updated at %s", EMOJI_GEAR, time.Now().Format("15:04"))))
+		err := slackClient.sendSlackAPIRequest("chat.update", SlackMessage{
+			Channel: existing.Channel,
+			TS:      existing.MessageTS,
+			Text:    report.HeaderText(),
+			Blocks:  blocks,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update digest message: %w", err)
+		}
+		if err := retractOutboxMessages(db, existing.ReplyOutbox); err != nil {
+			GetGlobalLogger().Warnf("Failed to remove the earlier replies of %s's %s digest: %v", recipient, n.Digest, err)
+		}
+		record.Channel, record.MessageTS = existing.Channel, existing.MessageTS
+	}
+
+	n.PostedChannel, n.PostedTS = record.Channel, record.MessageTS
+	record.ReplyOutbox, err = queueThreadReplies(db, record.Channel, record.MessageTS, combineProjectsIntoMessages(report))
+	if saveErr := SaveRecurringDigest(db, record); saveErr != nil {
+		GetGlobalLogger().Errorf("Digest for %s was sent but not recorded, a re-run will post it again: %v", recipient, saveErr)
+	}
+	return err
+}
+
+// sendTaskAlertsToUser delivers threshold, escalation and missing estimate alerts to a user by direct message.
+// Every task has one ongoing thread: the first alert starts it, later ones update its header and reply in it.
+func sendTaskAlertsToUser(db *sql.DB, userID string, alerts []ThresholdAlert) {
+	logger := GetGlobalLogger()
+	slackClient := NewSlackAPIClient()
+	notifier := NewSlackNotifier()
+
+	for _, alert := range alerts {
+		report := NewReport(fmt.Sprintf("%s %s", EMOJI_CLIPBOARD, alert.Name), groupTasksByProject(convertAlertsToTaskInfos([]ThresholdAlert{alert})))
+		messages := combineProjectsIntoMessages(report)
+
+		thread, err := GetTaskAlertThread(db, userID, alert.TaskID)
+		if err != nil {
+			logger.Errorf("Failed to look up alert thread, posting a new one: %v", err)
+		}
+
+		if thread != nil {
+			err := slackClient.sendSlackAPIRequest("chat.update", SlackMessage{
+				Channel: thread.Channel,
+				TS:      thread.MessageTS,
+				Text:    report.HeaderText(),
+				Blocks:  notifier.headerBlocks(report),
+			})
+			if err == nil {
+				if _, err := queueThreadReplies(db, thread.Channel, thread.MessageTS, messages); err != nil {
+					logger.Errorf("Failed to send alert for task %d to %s: %v", alert.TaskID, userID, err)
+				}
+				if err := touchTaskAlertThread(db, userID, alert.TaskID); err != nil {
+					logger.Warnf("Failed to touch alert thread of task %d for %s: %v", alert.TaskID, userID, err)
+				}
+				continue
+			}
+			// The thread's message may have been deleted, start a new one
+			logger.Warnf("Failed to update alert thread of task %d for %s, starting a new one: %v", alert.TaskID, userID, err)
+		}
+
+		resp, err := slackClient.sendSlackAPIRequestWithResponse("chat.postMessage", SlackMessage{
+			Channel: userID,
+			Text:    report.HeaderText(),
+			Blocks:  notifier.headerBlocks(report),
+		})
+		if err != nil {
+			logger.Errorf("Failed to start alert thread of task %d for %s: %v", alert.TaskID, userID, err)
+			continue
+		}
+		if err := SaveTaskAlertThread(db, TaskAlertThread{UserID: userID, TaskID: alert.TaskID, Channel: resp.Channel, MessageTS: resp.Timestamp}); err != nil {
+			logger.Errorf("Alert thread of task %d for %s was started but not recorded: %v", alert.TaskID, userID, err)
+		}
+		if _, err := queueThreadReplies(db, resp.Channel, resp.Timestamp, messages); err != nil {
+			logger.Errorf("Failed to send alert for task %d to %s: %v", alert.TaskID, userID, err)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report is a channel-independent task report; each notifier renders it for its own platform
+type Report struct {
+	Title    string
+	Projects []ReportProject
+	// Summary renders one line per project instead of the task list
+	Summary bool
+	// Threshold is the usage percentage counted as "over" in summaries
+	Threshold float64
+	// Query describes how the report was produced so interactive renderers can re-run it, optional
+	Query *ReportQuery
+}
+
+// ReportQuery holds the parameters needed to re-run a report
+type ReportQuery struct {
+	Period     string
+	Start      time.Time
+	End        time.Time
+	Percentage string
+	SortBy     string
+	Top        int
+	Summary    bool
+}
+
+// Options returns the report options the query was run with, so re-runs keep top N and summary mode
+func (q *ReportQuery) Options() ReportOptions {
+	options := ReportOptions{SortBy: q.SortBy, Top: q.Top, Summary: q.Summary}
+	if q.Percentage != "" {
+		options.Threshold, _ = strconv.ParseFloat(q.Percentage, 64)
+	}
+	return options
+}
+
+// ReportProject is one project section of a report
+type ReportProject struct {
+	Name  string
+	Tasks []ReportTask
+}
+
+// ReportTask is a single task line of a report
+type ReportTask struct {
+	ID         int
+	Name       string
+	TimeSpent  string
+	TotalTime  string
+	Estimation string
+	Comments   []string
+	Seconds    int     // time spent in the report period
+	Percentage float64 // usage of the estimation, when the task has one
+	Estimated  bool
+}
+
+// ReportOptions control ordering and size of a report
+type ReportOptions struct {
+	SortBy    string // REPORT_SORT_NAME (default), REPORT_SORT_TIME or REPORT_SORT_PERCENT
+	Top       int    // keep only the first N tasks, or projects in summary mode; 0 keeps all
+	Summary   bool
+	Threshold float64 // 0 means DEFAULT_SUMMARY_THRESHOLD
+}
+
+// ProjectSummary is the one-line summary of a project
+type ProjectSummary struct {
+	Name          string
+	TotalSeconds  int
+	TaskCount     int
+	OverThreshold int
+	Worst         *ReportTask // highest usage among estimated tasks, nil if none are estimated
+}
+
+// NewReport builds a report from tasks grouped by project, with projects in name order
+func NewReport(title string, projectGroups map[string][]TaskInfo) Report {
+	report := Report{Title: title}
+
+	projectNames := make([]string, 0, len(projectGroups))
+	for name := range projectGroups {
+		projectNames = append(projectNames, name)
+	}
+	sort.Strings(projectNames)
+
+	for _, projectName := range projectNames {
+		project := ReportProject{Name: projectName}
+		for _, task := range projectGroups[projectName] {
+			project.Tasks = append(project.Tasks, ReportTask{
+				ID:         task.TaskID,
+				Name:       task.Name,
+				TimeSpent:  task.CurrentTime,
+				TotalTime:  task.TotalDuration,
+				Estimation: task.EstimationInfo.Text,
+				Comments:   task.Comments,
+				Seconds:    task.CurrentSeconds,
+				Percentage: task.EstimationInfo.Percentage,
+				Estimated:  task.EstimationInfo.ErrorMessage == "" && task.EstimationInfo.Text != "",
+			})
+		}
+		report.Projects = append(report.Projects, project)
+	}
+
+	return report
+}
+
+// HeaderText returns the title followed by the report totals,
+// e.g. "📊 Update for today: 5h 0m across 7 tasks in 2 projects"
+func (r Report) HeaderText() string {
+	title := r.Title
+	if title == "" {
+		title = EMOJI_CHART + " Update"
+	}
+
+	totalSeconds, taskCount := 0, 0
+	for _, project := range r.Projects {
+		totalSeconds += project.totalSeconds()
+		taskCount += len(project.Tasks)
+	}
+
+	taskWord, projectWord := "tasks", "projects"
+	if taskCount == 1 {
+		taskWord = "task"
+	}
+	if len(r.Projects) == 1 {
+		projectWord = "project"
+	}
+	return fmt.Sprintf("%s: %s across %d %s in %d %s", title, formatDuration(totalSeconds), taskCount, taskWord, len(r.Projects), projectWord)
+}
+
+// IsEmpty reports whether the report has no tasks to show
+func (r Report) IsEmpty() bool {
+	return len(r.Projects) == 0
+}
+
+// taskSummaryLine returns the time and estimation line shared by the text renderers
+func (t ReportTask) taskSummaryLine() string {
+	line := "Time spent: " + t.TimeSpent + " | Total time: " + t.TotalTime
+	if t.Estimation != "" {
+		line += " | " + t.Estimation
+	}
+	return line
+}
+
+// WithOptions returns the report sorted, trimmed and marked for summary output according to options
+func (r Report) WithOptions(options ReportOptions) Report {
+	sortBy := options.SortBy
+	if sortBy == "" {
+		sortBy = REPORT_SORT_NAME
+		// "top 10" on its own means the ten biggest
+		if options.Top > 0 {
+			sortBy = REPORT_SORT_TIME
+		}
+	}
+
+	result := Report{
+		Title:     r.Title,
+		Summary:   options.Summary,
+		Threshold: options.Threshold,
+		Query:     r.Query,
+	}
+	if result.Query != nil {
+		query := *result.Query
+		query.SortBy = sortBy
+		query.Top = options.Top
+		query.Summary = options.Summary
+		result.Query = &query
+	}
+	if result.Threshold <= 0 {
+		result.Threshold = DEFAULT_SUMMARY_THRESHOLD
+	}
+
+	for _, project := range r.Projects {
+		tasks := append([]ReportTask(nil), project.Tasks...)
+		sort.SliceStable(tasks, func(i, j int) bool { return taskLess(tasks[i], tasks[j], sortBy) })
+		result.Projects = append(result.Projects, ReportProject{Name: project.Name, Tasks: tasks})
+	}
+
+	if options.Top > 0 && !options.Summary {
+		result.Projects = keepTopTasks(result.Projects, options.Top, sortBy)
+	}
+
+	sort.SliceStable(result.Projects, func(i, j int) bool {
+		return projectLess(result.Projects[i], result.Projects[j], sortBy)
+	})
+
+	if options.Top > 0 && options.Summary && len(result.Projects) > options.Top {
+		result.Projects = result.Projects[:options.Top]
+	}
+
+	return result
+}
+
+// taskLess orders tasks by the sort key, falling back to name
+func taskLess(a, b ReportTask, sortBy string) bool {
+	switch sortBy {
+	case REPORT_SORT_TIME:
+		if a.Seconds != b.Seconds {
+			return a.Seconds > b.Seconds
+		}
+	case REPORT_SORT_PERCENT:
+		// Tasks without an estimation go last
+		if a.Estimated != b.Estimated {
+			return a.Estimated
+		}
+		if a.Percentage != b.Percentage {
+			return a.Percentage > b.Percentage
+		}
+	}
+	return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+}
+
+// projectLess orders projects by their total time or worst task, falling back to name
+func projectLess(a, b ReportProject, sortBy string) bool {
+	switch sortBy {
+	case REPORT_SORT_TIME:
+		if a.totalSeconds() != b.totalSeconds() {
+			return a.totalSeconds() > b.totalSeconds()
+		}
+	case REPORT_SORT_PERCENT:
+		worstA, worstB := a.worstTask(), b.worstTask()
+		if (worstA != nil) != (worstB != nil) {
+			return worstA != nil
+		}
+		if worstA != nil && worstA.Percentage != worstB.Percentage {
+			return worstA.Percentage > worstB.Percentage
+		}
+	}
+	return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+}
+
+// keepTopTasks keeps the first n tasks across all projects and drops projects left empty
+func keepTopTasks(projects []ReportProject, n int, sortBy string) []ReportProject {
+	type rankedTask struct {
+		project int
+		task    ReportTask
+	}
+	var ranked []rankedTask
+	for i, project := range projects {
+		for _, task := range project.Tasks {
+			ranked = append(ranked, rankedTask{project: i, task: task})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return taskLess(ranked[i].task, ranked[j].task, sortBy) })
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+
+	kept := make([]ReportProject, len(projects))
+	for _, entry := range ranked {
+		kept[entry.project].Name = projects[entry.project].Name
+		kept[entry.project].Tasks = append(kept[entry.project].Tasks, entry.task)
+	}
+
+	var result []ReportProject
+	for _, project := range kept {
+		if len(project.Tasks) > 0 {
+			result = append(result, project)
+		}
+	}
+	return result
+}
+
+// totalSeconds returns the time spent on all tasks of the project in the report period
+func (p ReportProject) totalSeconds() int {
+	total := 0
+	for _, task := range p.Tasks {
+		total += task.Seconds
+	}
+	return total
+}
+
+// worstTask returns the estimated task with the highest usage, or nil if no task is estimated
+func (p ReportProject) worstTask() *ReportTask {
+	var worst *ReportTask
+	for i := range p.Tasks {
+		task := &p.Tasks[i]
+		if task.Estimated && (worst == nil || task.Percentage > worst.Percentage) {
+			worst = task
+		}
+	}
+	return worst
+}
+
+// ProjectSummaries returns one summary per project, in report order
+func (r Report) ProjectSummaries() []ProjectSummary {
+	threshold := r.Threshold
+	if threshold <= 0 {
+		threshold = DEFAULT_SUMMARY_THRESHOLD
+	}
+
+	summaries := make([]ProjectSummary, 0, len(r.Projects))
+	for _, project := range r.Projects {
+		summary := ProjectSummary{
+			Name:         project.Name,
+			TotalSeconds: project.totalSeconds(),
+			TaskCount:    len(project.Tasks),
+			Worst:        project.worstTask(),
+		}
+		for _, task := range project.Tasks {
+			if task.Estimated && task.Percentage >= threshold {
+				summary.OverThreshold++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// summaryLine renders a project summary; bold wraps text in the platform's bold markup
+func (r Report) summaryLine(summary ProjectSummary, bold func(string) string) string {
+	threshold := r.Threshold
+	if threshold <= 0 {
+		threshold = DEFAULT_SUMMARY_THRESHOLD
+	}
+
+	taskWord := "tasks"
+	if summary.TaskCount == 1 {
+		taskWord = "task"
+	}
+	line := fmt.Sprintf("%s: %s across %d %s | %d over %.0f%%",
+		bold(summary.Name), formatDuration(summary.TotalSeconds), summary.TaskCount, taskWord, summary.OverThreshold, threshold)
+	if summary.Worst != nil {
+		line += fmt.Sprintf(" | worst: %s (%.0f%%)", summary.Worst.Name, summary.Worst.Percentage)
+	}
+	return line
+}
+
+// SummaryText renders a project summary without markup, for templates
+func (r Report) SummaryText(summary ProjectSummary) string {
+	return r.summaryLine(summary, func(s string) string { return s })
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// reportActionValue is the button value of report message actions. It carries the
+// report parameters so the report can be re-run without any server-side state.
+type reportActionValue struct {
+	Project    string `json:"project"`
+	Period     string `json:"period"`
+	Start      string `json:"start"`
+	End        string `json:"end"`
+	Percentage string `json:"percentage,omitempty"`
+	SortBy     string `json:"sort,omitempty"`
+	Top        int    `json:"top,omitempty"`
+	Summary    bool   `json:"summary,omitempty"`
+}
+
+// encodeReportActionValue encodes the parameters of a project's report section,
+// returning "" when they don't fit in a button value
+func encodeReportActionValue(projectName string, query *ReportQuery) string {
+	encoded, err := json.Marshal(reportActionValue{
+		Project:    projectName,
+		Period:     query.Period,
+		Start:      query.Start.Format("2006-01-02"),
+		End:        query.End.Format("2006-01-02"),
+		Percentage: query.Percentage,
+		SortBy:     query.SortBy,
+		Top:        query.Top,
+		Summary:    query.Summary,
+	})
+	if err != nil || len(encoded) > MAX_BUTTON_VALUE_CHARS {
+		return ""
+	}
+	return string(encoded)
+}
+
+// decodeReportActionValue decodes a report action value into the project name and query
+func decodeReportActionValue(value string) (string, *ReportQuery, error) {
+	var decoded reportActionValue
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return "", nil, fmt.Errorf("invalid report action value: %w", err)
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", decoded.Start, time.Local)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid report start date '%s': %w", decoded.Start, err)
+	}
+	end, err := time.ParseInLocation("2006-01-02", decoded.End, time.Local)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid report end date '%s': %w", decoded.End, err)
+	}
+
+	return decoded.Project, &ReportQuery{
+		Period:     decoded.Period,
+		Start:      start,
+		End:        endOfDay(end),
+		Percentage: decoded.Percentage,
+		SortBy:     decoded.SortBy,
+		Top:        decoded.Top,
+		Summary:    decoded.Summary,
+	}, nil
+}
+
+// reportBlockID builds a block ID from a prefix and a project name within Slack's 255 character limit
+func reportBlockID(prefix, projectName string) string {
+	return truncateUTF8(prefix+projectName, MAX_BLOCK_ID_CHARS)
+}
+
+// createProjectActionsBlock creates the Refresh, Show comments and Per-person breakdown controls of a project
+func createProjectActionsBlock(project ReportProject, value string) Block {
+	elements := []*Element{
+		NewButton(REPORT_ACTION_REFRESH, "🔄 Refresh", value),
+		NewButton(REPORT_ACTION_COMMENTS, "💬 Show comments", value),
+	}
+
+	var options []Option
+	for _, task := range project.Tasks {
+		if task.ID == 0 || len(options) >= MAX_STATIC_SELECT_OPTIONS {
+			continue
+		}
+		name := task.Name
+		if len([]rune(name)) > MAX_OPTION_TEXT_CHARS {
+			name = string([]rune(name)[:MAX_OPTION_TEXT_CHARS-3]) + "..."
+		}
+		options = append(options, NewOption(name, strconv.Itoa(task.ID)))
+	}
+	if len(options) > 0 {
+		elements = append(elements, NewSelect("static_select", REPORT_ACTION_BREAKDOWN, "👥 Per-person breakdown", options...))
+	}
+
+	return ActionsBlock(elements...).WithBlockID(reportBlockID(REPORT_ACTIONS_BLOCK_PREFIX, project.Name))
+}
+
+// HandleReportAction handles the buttons and menus on report messages
+func HandleReportAction(payload SlackInteractivePayload, action SlackAction) {
+	logger := GetGlobalLogger()
+
+	var err error
+	switch action.ActionID {
+	case REPORT_ACTION_REFRESH:
+		err = refreshReportMessage(payload, action.Value)
+	case REPORT_ACTION_COMMENTS:
+		err = showReportComments(payload, action.Value)
+	case REPORT_ACTION_BREAKDOWN:
+		err = showTaskBreakdown(payload, action)
+	default:
+		err = fmt.Errorf("unknown report action %s", action.ActionID)
+	}
+
+	if err != nil {
+		logger.Errorf("Failed to handle report action %s for user %s: %v", action.ActionID, payload.User.ID, err)
+		if notifyErr := postReportEphemeral(payload, fmt.Sprintf("%s Sorry, that didn't work: %v", EMOJI_CRITICAL, err), nil); notifyErr != nil {
+			logger.Errorf("Failed to tell user %s about the failed report action: %v", payload.User.ID, notifyErr)
+		}
+	}
+}
+
+// refreshReportMessage re-runs the report for the projects shown in a message and updates it in place.
+// Blocks before the first project (the report header) and the thread continuation note are kept.
+func refreshReportMessage(payload SlackInteractivePayload, value string) error {
+	projectName, query, err := decodeReportActionValue(value)
+	if err != nil {
+		return err
+	}
+
+	var leading, trailing []Block
+	var projectNames []string
+	for _, block := range payload.Message.Blocks {
+		blockID := block.BlockID
+		switch {
+		case strings.HasPrefix(blockID, REPORT_PROJECT_BLOCK_PREFIX):
+			projectNames = append(projectNames, strings.TrimPrefix(blockID, REPORT_PROJECT_BLOCK_PREFIX))
+		case blockID == REPORT_CONTINUED_BLOCK_ID:
+			trailing = append(trailing, block)
+		case len(projectNames) == 0 && blockID != REPORT_REFRESHED_BLOCK_ID:
+			leading = append(leading, block)
+		}
+	}
+	if len(projectNames) == 0 {
+		projectNames = []string{projectName}
+	}
+
+	tasks := getFilteredTasksWithTimeout(query.Start, query.End, projectNames, query.Percentage)
+	tasks = addCommentsToTasksWithTimeout(tasks, query.Start, query.End)
+	report := NewReport("", groupTasksByProject(tasks))
+	report.Query = query
+	report = report.WithOptions(query.Options())
+
+	var projectBlocks []Block
+	messages := combineProjectsIntoMessages(report)
+	if len(messages) > 0 {
+		projectBlocks = messages[0]
+	}
+
+	// Leave room for the notes below
+	available := MAX_SLACK_BLOCKS - len(leading) - len(trailing) - 2
+	truncated := len(messages) > 1
+	if len(projectBlocks) > available {
+		projectBlocks = projectBlocks[:available]
+		truncated = true
+	}
+
+	blocks := append([]Block{}, leading...)
+	blocks = append(blocks, projectBlocks...)
+	if report.IsEmpty() {
+		blocks = append(blocks, SectionBlock(fmt.Sprintf("No tracked time left for %s.", strings.Join(projectNames, ", "))))
+	} else if truncated {
+		blocks = append(blocks, SectionBlock("⚠️ The refreshed report no longer fits in this message, run the command again for all of it."))
+	}
+	blocks = append(blocks, ContextBlock(fmt.Sprintf("🔄 Refreshed by <@%s> at %s", payload.User.ID, time.Now().Format("15:04"))).
+		WithBlockID(REPORT_REFRESHED_BLOCK_ID))
+	blocks = append(blocks, trailing...)
+
+	text := fmt.Sprintf("Refreshed report for %s", strings.Join(projectNames, ", "))
+
+	// Ephemeral messages can only be replaced through the response_url
+	if payload.Container.IsEphemeral {
+		return postSlackResponse(payload.ResponseURL, SlackCommandResponse{
+			ReplaceOriginal: true,
+			Text:            text,
+			Blocks:          blocks,
+		})
+	}
+
+	return NewSlackAPIClient().sendSlackAPIRequest("chat.update", SlackMessage{
+		Channel: payload.Channel.ID,
+		TS:      payload.Message.Timestamp,
+		Text:    text,
+		Blocks:  blocks,
+	})
+}
+
+// showReportComments sends the requester the full, untruncated comments of a project's tasks
+func showReportComments(payload SlackInteractivePayload, value string) error {
+	projectName, query, err := decodeReportActionValue(value)
+	if err != nil {
+		return err
+	}
+
+	tasks := getFilteredTasksWithTimeout(query.Start, query.End, []string{projectName}, query.Percentage)
+	tasks = addCommentsToTasksWithTimeout(tasks, query.Start, query.End)
+
+	var sections []string
+	for _, task := range tasks {
+		var sb strings.Builder
+		for _, comment := range task.Comments {
+			if comment != "" {
+				sb.WriteString(fmt.Sprintf("\n• %s", comment))
+			}
+		}
+		if sb.Len() > 0 {
+			sections = append(sections, fmt.Sprintf("*%s*%s", task.Name, sb.String()))
+		}
+	}
+
+	title := fmt.Sprintf("💬 Comments for *%s* (%s)", projectName, query.Period)
+	if len(sections) == 0 {
+		return postReportEphemeral(payload, title+"\nNo comments were logged in this period.", nil)
+	}
+
+	blocks := []Block{SectionBlock(title)}
+	for _, section := range sections {
+		// Section text is limited to 3000 characters
+		for len(section) > 0 && len(blocks) < MAX_SLACK_BLOCKS {
+			chunk := section
+			if len(chunk) > MAX_MESSAGE_CHARS_BUFFER {
+				chunk = truncateUTF8(chunk, MAX_MESSAGE_CHARS_BUFFER)
+			}
+			blocks = append(blocks, SectionBlock(chunk))
+			section = section[len(chunk):]
+		}
+	}
+
+	return postReportEphemeral(payload, title, blocks)
+}
+
+// taskUserTime is the time one TimeCamp user spent on a task
+type taskUserTime struct {
+	UserID   int
+	UserName string
+	Seconds  int
+	Days     int
+}
+
+// getTaskTimeByUser returns the time per TimeCamp user on a task in a period, largest first
+func getTaskTimeByUser(db *sql.DB, taskID int, start, end time.Time) ([]taskUserTime, error) {
+	rows, err := db.Query(`
+		SELECT te.user_id, COALESCE(NULLIF(u.display_name, ''), u.username, ''), SUM(te.duration), COUNT(DISTINCT te.date)
+		FROM time_entries te
+		LEFT JOIN users u ON u.user_id = te.user_id
+		WHERE te.task_id = $1 AND te.date >= $2 AND te.date <= $3
+		GROUP BY te.user_id, u.display_name, u.username
+		ORDER BY SUM(te.duration) DESC`,
+		taskID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time per user for task %d: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	var result []taskUserTime
+	for rows.Next() {
+		var entry taskUserTime
+		if err := rows.Scan(&entry.UserID, &entry.UserName, &entry.Seconds, &entry.Days); err != nil {
+			return nil, fmt.Errorf("failed to scan time per user: %w", err)
+		}
+		if entry.UserName == "" {
+			entry.UserName = fmt.Sprintf("TimeCamp user %d", entry.UserID)
+		}
+		result = append(result, entry)
+	}
+	return result, rows.Err()
+}
+
+// showTaskBreakdown sends the requester the time per person on the task picked from a project's menu
+func showTaskBreakdown(payload SlackInteractivePayload, action SlackAction) error {
+	taskID, err := strconv.Atoi(action.SelectedOption.Value)
+	if err != nil {
+		return fmt.Errorf("invalid task ID '%s'", action.SelectedOption.Value)
+	}
+
+	// The menu has no value of its own, the report parameters live on the Refresh button next to it
+	value := findReportActionValue(payload.Message.Blocks, action.BlockID)
+	if value == "" {
+		return fmt.Errorf("report parameters not found in message")
+	}
+	_, query, err := decodeReportActionValue(value)
+	if err != nil {
+		return err
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	var taskName string
+	if err := db.QueryRow(`SELECT name FROM tasks WHERE task_id = $1`, taskID).Scan(&taskName); err != nil {
+		return fmt.Errorf("failed to look up task %d: %w", taskID, err)
+	}
+
+	entries, err := getTaskTimeByUser(db, taskID, query.Start, query.End)
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for _, entry := range entries {
+		total += entry.Seconds
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("👥 *%s* (%s)", taskName, query.Period))
+	if total == 0 {
+		sb.WriteString("\nNo time was tracked on this task in this period.")
+	}
+	for _, entry := range entries {
+		dayWord := "days"
+		if entry.Days == 1 {
+			dayWord = "day"
+		}
+		sb.WriteString(fmt.Sprintf("\n• %s: %s on %d %s (%.0f%%)",
+			entry.UserName, formatDuration(entry.Seconds), entry.Days, dayWord, float64(entry.Seconds)*100/float64(total)))
+	}
+
+	return postReportEphemeral(payload, truncateUTF8(sb.String(), MAX_MESSAGE_CHARS_BUFFER), nil)
+}
+
+// findReportActionValue returns the Refresh button value of an actions block in a message
+func findReportActionValue(blocks []Block, blockID string) string {
+	for _, block := range blocks {
+		if block.BlockID != blockID {
+			continue
+		}
+		for _, element := range block.Elements {
+			if button, ok := element.(*Element); ok && button.ActionID == REPORT_ACTION_REFRESH {
+				return button.Value
+			}
+		}
+	}
+	return ""
+}
+
+// truncateUTF8 shortens text to at most maxBytes without splitting a character
+func truncateUTF8(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	for maxBytes > 0 && !utf8.RuneStart(text[maxBytes]) {
+		maxBytes--
+	}
+	return text[:maxBytes]
+}
+
+// postReportEphemeral shows a message only to the user who used a report action,
+// in the same thread as the report message
+func postReportEphemeral(payload SlackInteractivePayload, text string, blocks []Block) error {
+	if blocks == nil {
+		blocks = []Block{SectionBlock(text)}
+	}
+
+	// Ephemeral reports live outside the channel history, so answer through their response_url
+	if payload.Container.IsEphemeral && payload.ResponseURL != "" {
+		return postSlackResponse(payload.ResponseURL, SlackCommandResponse{
+			ResponseType: "ephemeral",
+			Text:         text,
+			Blocks:       blocks,
+		})
+	}
+
+	return NewSlackAPIClient().sendSlackAPIRequest("chat.postEphemeral", SlackMessage{
+		Channel:  payload.Channel.ID,
+		User:     payload.User.ID,
+		ThreadTS: payload.Message.ThreadTimestamp,
+		Text:     text,
+		Blocks:   blocks,
+	})
+}
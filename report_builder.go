@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Report builder modal, opened by a bare /oye or the global shortcut. Submitting it
+// builds the same OYECommand as the text command and runs it through runOYEReport.
+
+const (
+	REPORT_BUILDER_CALLBACK_ID = "report_builder_modal"
+	REPORT_BUILDER_SHORTCUT_ID = "oye_report_builder"
+	reportBuilderCustomPeriod  = "custom"
+)
+
+// reportBuilderPeriods are the periods offered by the modal, in menu order
+var reportBuilderPeriods = []string{
+	"today", "yesterday",
+	"this week", "last week",
+	"this month", "last month",
+	"this quarter", "last quarter",
+	"this year", "last year",
+	"last 7 days", "last 30 days",
+}
+
+// OpenReportBuilderModal opens the report builder; channelID preselects where the report goes
+func OpenReportBuilderModal(triggerID, channelID string) error {
+	if triggerID == "" {
+		return fmt.Errorf("no trigger_id to open the report builder with")
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	allProjects, err := GetAllProjects(db)
+	if err != nil {
+		return fmt.Errorf("failed to get all projects: %w", err)
+	}
+
+	projectSelect := NewSelect("multi_static_select", "projects_value", "All projects")
+	addProjectSelectOptions(projectSelect, allProjects)
+
+	var periodOptions []Option
+	for _, period := range reportBuilderPeriods {
+		periodOptions = append(periodOptions, NewOption(strings.ToUpper(period[:1])+period[1:], period))
+	}
+	periodOptions = append(periodOptions, NewOption("Custom range (pick dates below)", reportBuilderCustomPeriod))
+	periodSelect := NewSelect("static_select", "period_value", "", periodOptions...)
+	periodSelect.InitialOption = &periodOptions[1] // yesterday
+
+	deliveryOptions := []Option{
+		NewOption("Summary post with details in a thread", SLACK_DELIVERY_THREAD),
+		NewOption("Share in the channel", SLACK_DELIVERY_SHARE),
+		NewOption("Only visible to me", SLACK_DELIVERY_PRIVATE),
+	}
+	deliveryRadio := NewSelect("radio_buttons", "delivery_value", "", deliveryOptions...)
+	deliveryRadio.InitialOption = &deliveryOptions[0]
+
+	channelSelect := NewSelect("conversations_select", "channel_value", "")
+	channelSelect.ResponseURLEnabled = true // needed for private delivery
+	channelSelect.Filter = &ConversationFilter{ExcludeBotUsers: true}
+	if channelID != "" {
+		channelSelect.InitialConversation = channelID
+	} else {
+		channelSelect.DefaultToCurrentConversation = true
+	}
+
+	var blocks []Block
+	if len(allProjects) > 0 {
+		blocks = append(blocks, InputBlock("projects", "Projects", projectSelect).AsOptional().
+			WithHint("Leave empty for all projects"))
+	}
+	blocks = append(blocks,
+		InputBlock("period", "Period", periodSelect),
+		InputBlock("custom_from", "From (custom range)", NewPicker("datepicker", "custom_from_value")).AsOptional(),
+		InputBlock("custom_to", "To (custom range)", NewPicker("datepicker", "custom_to_value")).AsOptional(),
+		InputBlock("threshold", "Only tasks over (% of estimate)", NewPlainTextInput("threshold_value", "e.g. 80")).AsOptional(),
+		InputBlock("delivery", "Output", deliveryRadio),
+		InputBlock("channel", "Post to", channelSelect),
+	)
+
+	modal := NewModal(REPORT_BUILDER_CALLBACK_ID, "Build a Report", "Run report", blocks...)
+
+	GetGlobalLogger().Infof("Opening report builder modal with trigger_id: %s", triggerID)
+	return NewSlackAPIClient().sendSlackAPIRequest("views.open", ViewRequest{TriggerID: triggerID, View: modal})
+}
+
+// reportBuilderCommandText turns the period and threshold fields into /oye command text,
+// so the modal goes through the same grammar as typed commands
+func reportBuilderCommandText(period, from, to, threshold string) (string, error) {
+	var text string
+	if period == reportBuilderCustomPeriod {
+		if from == "" {
+			return "", &ModalValidationError{BlockID: "custom_from", Message: "Pick a start date for the custom range"}
+		}
+		if to == "" {
+			return "", &ModalValidationError{BlockID: "custom_to", Message: "Pick an end date for the custom range"}
+		}
+		text = fmt.Sprintf("from %s to %s", from, to)
+	} else {
+		text = "for " + period
+	}
+
+	if threshold != "" {
+		text += " over " + strings.TrimSuffix(threshold, "%")
+	}
+	return text, nil
+}
+
+// HandleReportBuilderSubmission validates the report builder and runs the report in the background
+func HandleReportBuilderSubmission(payload SlackInteractivePayload) error {
+	logger := GetGlobalLogger()
+	values := payload.View.State.Values
+
+	period := values["period"]["period_value"].SelectedOption.Value
+	threshold := strings.TrimSpace(values["threshold"]["threshold_value"].Value)
+	text, err := reportBuilderCommandText(period,
+		values["custom_from"]["custom_from_value"].SelectedDate,
+		values["custom_to"]["custom_to_value"].SelectedDate,
+		threshold)
+	if err != nil {
+		return err
+	}
+
+	command, err := ParseOYECommand(text)
+	if err != nil {
+		// Point at the threshold when the error is in the `over` part, otherwise at the period
+		blockID := "period"
+		if period == reportBuilderCustomPeriod {
+			blockID = "custom_to"
+		}
+		if parseErr, ok := err.(*OYEParseError); ok && threshold != "" && parseErr.Pos >= strings.Index(text, " over ") {
+			blockID = "threshold"
+		}
+		return &ModalValidationError{BlockID: blockID, Message: err.Error()}
+	}
+	command.Delivery = values["delivery"]["delivery_value"].SelectedOption.Value
+
+	// Projects come from the menu, so their names are exact
+	var projectFilter ProjectFilter
+	if selected := values["projects"]["projects_value"].SelectedOptions; len(selected) > 0 {
+		db, err := GetDB()
+		if err != nil {
+			return fmt.Errorf("failed to get database connection: %w", err)
+		}
+		allProjects, err := GetAllProjects(db)
+		if err != nil {
+			return fmt.Errorf("failed to get all projects: %w", err)
+		}
+		selectedIDs := make(map[string]bool, len(selected))
+		for _, option := range selected {
+			selectedIDs[option.Value] = true
+		}
+		for _, project := range allProjects {
+			if selectedIDs[fmt.Sprint(project.ID)] {
+				projectFilter.Include = append(projectFilter.Include, project.Name)
+				command.Projects = append(command.Projects, project.Name)
+			}
+		}
+	}
+
+	req := &SlackCommandRequest{
+		UserID:    payload.User.ID,
+		UserName:  payload.User.Name,
+		ChannelID: values["channel"]["channel_value"].SelectedConversation,
+	}
+	for _, responseURL := range payload.ResponseURLs {
+		if responseURL.ChannelID == req.ChannelID {
+			req.ResponseURL = responseURL.ResponseURL
+		}
+	}
+	if req.ChannelID == "" {
+		return &ModalValidationError{BlockID: "channel", Message: "Pick where the report should go"}
+	}
+	if command.Delivery == SLACK_DELIVERY_PRIVATE && req.ResponseURL == "" {
+		return &ModalValidationError{BlockID: "delivery", Message: "Private reports aren't available for this conversation, pick another output"}
+	}
+
+	logger.Infof("Report builder submitted by %s: '%s' for %d projects, %s to %s", payload.User.ID, text, len(projectFilter.Include), command.Delivery, req.ChannelID)
+	go runOYEReport(req, command, projectFilter)
+	return nil
+}
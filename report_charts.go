@@ -0,0 +1,216 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Defaults for how many charts a report gets, Slack takes at most SLACK_MAX_FILES_PER_MESSAGE
+const (
+	defaultChartMaxProjects     = 3
+	defaultChartTasksPerProject = 2
+)
+
+// getTrackedSecondsByDate returns the time tracked on tasks per task and date, up to and including until
+func getTrackedSecondsByDate(db *sql.DB, taskIDs []int64, until time.Time) (map[int]map[string]int, error) {
+	rows, err := db.Query(`
+		SELECT task_id, date, COALESCE(SUM(duration), 0)
+		FROM time_entries
+		WHERE task_id = ANY($1) AND date <= $2
+		GROUP BY task_id, date`,
+		pq.Array(taskIDs), until.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time per date: %w", err)
+	}
+	defer rows.Close()
+
+	secondsByDate := make(map[int]map[string]int)
+	for rows.Next() {
+		var taskID, seconds int
+		var date string
+		if err := rows.Scan(&taskID, &date, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan time per date: %w", err)
+		}
+		if secondsByDate[taskID] == nil {
+			secondsByDate[taskID] = make(map[string]int)
+		}
+		secondsByDate[taskID][date] = seconds
+	}
+	return secondsByDate, rows.Err()
+}
+
+// buildBurnChart adds up the time of tasks from their first entry to each day of the period,
+// against the sum of their estimates. The period stops at today.
+func buildBurnChart(title string, tasks []TaskInfo, secondsByDate map[int]map[string]int, start, end time.Time) BurnChart {
+	if today := time.Now(); end.After(today) {
+		end = today
+	}
+	first := start.Format("2006-01-02")
+
+	chart := BurnChart{Title: title}
+	var baseline int
+	var optimistic, pessimistic float64
+	perDay := make(map[string]int)
+	for _, task := range tasks {
+		for date, seconds := range secondsByDate[task.TaskID] {
+			if date < first {
+				baseline += seconds
+			} else {
+				perDay[date] += seconds
+			}
+		}
+		optimistic += task.EstimationInfo.Optimistic
+		pessimistic += task.EstimationInfo.Pessimistic
+	}
+
+	total := baseline
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		total += perDay[date]
+		chart.Days = append(chart.Days, date)
+		chart.Cumulative = append(chart.Cumulative, float64(total)/3600)
+	}
+
+	chart.References = append(chart.References, ChartReference{Label: "Estimate", Hours: pessimistic, Color: chartPessimist})
+	if optimistic != pessimistic {
+		chart.References = append(chart.References, ChartReference{Label: "Optimistic", Hours: optimistic, Color: chartOptimist, Dashed: true})
+	}
+	return chart
+}
+
+// chartFilename turns a chart title into a file name, e.g. "ACME: Login page" into acme-login-page.png
+func chartFilename(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(title))
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
+	}
+	name = strings.Trim(name, "-")
+	if name == "" {
+		name = "chart"
+	}
+	return "oye-" + name + ".png"
+}
+
+// buildReportCharts renders a chart of each project's estimated tasks and of the tasks
+// furthest into their estimates. Projects are taken by name, up to CHART_MAX_PROJECTS,
+// with CHART_TASKS_PER_PROJECT task charts each.
+func buildReportCharts(db *sql.DB, groups map[string][]TaskInfo, start, end time.Time) ([]SlackFileUpload, error) {
+	maxProjects := getEnvInt("CHART_MAX_PROJECTS", defaultChartMaxProjects)
+	tasksPerProject := getEnvInt("CHART_TASKS_PER_PROJECT", defaultChartTasksPerProject)
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		if name != "Other" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	// Only tasks with a valid estimate have something to burn against
+	estimated := make(map[string][]TaskInfo)
+	var taskIDs []int64
+	for _, name := range names {
+		for _, task := range groups[name] {
+			if task.EstimationInfo.ErrorMessage == "" && task.EstimationInfo.Pessimistic > 0 {
+				estimated[name] = append(estimated[name], task)
+				taskIDs = append(taskIDs, int64(task.TaskID))
+			}
+		}
+	}
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+
+	secondsByDate, err := getTrackedSecondsByDate(db, taskIDs, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var charts []BurnChart
+	projects := 0
+	for _, name := range names {
+		tasks := estimated[name]
+		if len(tasks) == 0 || projects == maxProjects {
+			continue
+		}
+		projects++
+		charts = append(charts, buildBurnChart(name+": estimated tasks", tasks, secondsByDate, start, end))
+
+		byUsage := append([]TaskInfo(nil), tasks...)
+		sort.SliceStable(byUsage, func(i, j int) bool {
+			return byUsage[i].EstimationInfo.Percentage > byUsage[j].EstimationInfo.Percentage
+		})
+		for i := 0; i < len(byUsage) && i < tasksPerProject; i++ {
+			charts = append(charts, buildBurnChart(name+": "+byUsage[i].Name, byUsage[i:i+1], secondsByDate, start, end))
+		}
+	}
+	if len(charts) > SLACK_MAX_FILES_PER_MESSAGE {
+		charts = charts[:SLACK_MAX_FILES_PER_MESSAGE]
+	}
+
+	uploads := make([]SlackFileUpload, 0, len(charts))
+	for _, chart := range charts {
+		content, err := chart.RenderPNG()
+		if err != nil {
+			return nil, fmt.Errorf("failed to render chart %q: %w", chart.Title, err)
+		}
+		uploads = append(uploads, SlackFileUpload{Filename: chartFilename(chart.Title), Title: chart.Title, Content: content})
+	}
+	return uploads, nil
+}
+
+// runOYECharts uploads burn-up charts for a report next to it: into the report's thread when
+// it was posted at reportTS, otherwise into the command's channel or thread, or with `private`
+// into the requester's direct messages
+func runOYECharts(req *SlackCommandRequest, command *OYECommand, groups map[string][]TaskInfo, reportChannel, reportTS string) {
+	logger := GetGlobalLogger()
+
+	db, err := GetDB()
+	if err != nil {
+		logger.Errorf("Failed to get database connection for charts: %v", err)
+		replyToRequester(req, "Sorry, the charts couldn't be built.")
+		return
+	}
+	uploads, err := buildReportCharts(db, groups, command.Start, command.End)
+	if err != nil {
+		logger.Errorf("Failed to build charts: %v", err)
+		replyToRequester(req, "Sorry, the charts couldn't be built.")
+		return
+	}
+	if len(uploads) == 0 {
+		replyToRequester(req, "No charts to draw, none of the reported tasks has a valid estimate.")
+		return
+	}
+
+	slackClient := NewSlackAPIClient()
+	channelID, threadTS := reportChannel, reportTS
+	if reportTS == "" {
+		channelID, threadTS, err = reportUploadTarget(slackClient, req, command)
+	}
+	if err != nil {
+		logger.Errorf("Failed to open direct message for charts: %v", err)
+		replyToRequester(req, "Sorry, I couldn't send you the charts as a direct message.")
+		return
+	}
+
+	comment := fmt.Sprintf("📈 Burn-up charts for %s", command.Period)
+	if req.UserID != "" && command.Delivery != SLACK_DELIVERY_PRIVATE {
+		comment += fmt.Sprintf(", requested by <@%s>", req.UserID)
+	}
+	if err := slackClient.uploadSlackFiles(channelID, threadTS, comment, uploads); err != nil {
+		logger.Errorf("Failed to upload charts to %s: %v", channelID, err)
+		replyToRequester(req, "Sorry, the charts couldn't be uploaded. Make sure OYE is a member of this channel, or add `private` to get them as a direct message.")
+		return
+	}
+	logger.Infof("Uploaded %d charts to %s", len(uploads), channelID)
+}
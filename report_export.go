@@ -0,0 +1,442 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ExportRow is one task line of a spreadsheet export
+type ExportRow struct {
+	Project         string
+	Task            string
+	Estimate        string // hours, "2-4" for a range, empty without a valid estimate
+	PeriodSeconds   int
+	TotalSeconds    int
+	BillableSeconds int // part of PeriodSeconds tracked as billable
+	Percentage      float64
+	Estimated       bool
+	Comments        []string
+}
+
+// exportColumns are the header of every export
+var exportColumns = []string{
+	"Project", "Task", "Estimate (h)", "Period time (h)", "Total time (h)",
+	"Usage %", "Billable (h)", "Non-billable (h)", "Comments",
+}
+
+// exportCell is a spreadsheet cell, numbers stay numbers in XLSX
+type exportCell struct {
+	Text     string
+	Number   float64
+	IsNumber bool
+}
+
+func textCell(text string) exportCell {
+	return exportCell{Text: text}
+}
+
+func numberCell(value float64) exportCell {
+	return exportCell{Text: strconv.FormatFloat(value, 'f', 2, 64), Number: value, IsNumber: true}
+}
+
+func hoursCell(seconds int) exportCell {
+	return numberCell(float64(seconds) / 3600)
+}
+
+// cells returns the row in exportColumns order
+func (r ExportRow) cells() []exportCell {
+	estimate, usage := textCell(r.Estimate), textCell("")
+	if hours, err := strconv.ParseFloat(r.Estimate, 64); err == nil {
+		estimate = numberCell(hours)
+	}
+	if r.Estimated {
+		usage = numberCell(r.Percentage)
+	}
+	return []exportCell{
+		textCell(r.Project),
+		textCell(r.Task),
+		estimate,
+		hoursCell(r.PeriodSeconds),
+		hoursCell(r.TotalSeconds),
+		usage,
+		hoursCell(r.BillableSeconds),
+		hoursCell(r.PeriodSeconds - r.BillableSeconds),
+		textCell(strings.Join(r.Comments, "; ")),
+	}
+}
+
+// exportEstimate formats a task's estimate in hours, empty when it has none
+func exportEstimate(estimation EstimationInfo) string {
+	if estimation.ErrorMessage != "" || estimation.Text == "" {
+		return ""
+	}
+	if estimation.HasRange {
+		return formatFloat(estimation.Optimistic) + "-" + formatFloat(estimation.Pessimistic)
+	}
+	return formatFloat(estimation.Pessimistic)
+}
+
+// getBillableSeconds sums the billable time of tasks in a period
+func getBillableSeconds(db *sql.DB, taskIDs []int64, startTime, endTime time.Time) (map[int]int, error) {
+	rows, err := db.Query(`
+		SELECT task_id, COALESCE(SUM(duration), 0)
+		FROM time_entries
+		WHERE task_id = ANY($1) AND date >= $2 AND date <= $3 AND billable = 1
+		GROUP BY task_id`,
+		pq.Array(taskIDs), startTime.Format("2006-01-02"), endTime.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query billable time: %w", err)
+	}
+	defer rows.Close()
+
+	billable := make(map[int]int)
+	for rows.Next() {
+		var taskID, seconds int
+		if err := rows.Scan(&taskID, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan billable time: %w", err)
+		}
+		billable[taskID] = seconds
+	}
+	return billable, rows.Err()
+}
+
+// buildExportRows runs a command through the report pipeline and returns one row per task,
+// in the order the report would list them
+func buildExportRows(command *OYECommand, projectFilter ProjectFilter) ([]ExportRow, error) {
+	tasks := getFilteredTasksWithTimeout(command.Start, command.End, projectFilter.Include, command.Percentage)
+	tasks = filterTasksByProjectNames(tasks, nil, projectFilter.Exclude)
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+	tasks = addCommentsToTasksWithTimeout(tasks, command.Start, command.End)
+
+	db, err := GetDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	taskIDs := make([]int64, len(tasks))
+	taskInfos := make(map[int]TaskInfo, len(tasks))
+	for i, task := range tasks {
+		taskIDs[i] = int64(task.TaskID)
+		taskInfos[task.TaskID] = task
+	}
+	billable, err := getBillableSeconds(db, taskIDs, command.Start, command.End)
+	if err != nil {
+		return nil, err
+	}
+
+	report := NewReport("", groupTasksByProject(tasks)).WithOptions(command.Options)
+	var rows []ExportRow
+	for _, project := range report.Projects {
+		for _, task := range project.Tasks {
+			info := taskInfos[task.ID]
+			rows = append(rows, ExportRow{
+				Project:         project.Name,
+				Task:            task.Name,
+				Estimate:        exportEstimate(info.EstimationInfo),
+				PeriodSeconds:   info.CurrentSeconds,
+				TotalSeconds:    info.TotalSeconds,
+				BillableSeconds: billable[task.ID],
+				Percentage:      task.Percentage,
+				Estimated:       task.Estimated,
+				Comments:        task.Comments,
+			})
+		}
+	}
+	return rows, nil
+}
+
+// exportFilename names an export after its period, e.g. oye-report-2026-09-01-to-2026-09-30.xlsx
+func exportFilename(command *OYECommand) string {
+	return fmt.Sprintf("oye-report-%s-to-%s.%s", command.Start.Format("2006-01-02"), command.End.Format("2006-01-02"), command.Export)
+}
+
+// renderExport writes rows in the command's export format
+func renderExport(format string, rows []ExportRow) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case EXPORT_FORMAT_CSV:
+		err = writeExportCSV(&buf, rows)
+	case EXPORT_FORMAT_XLSX:
+		err = writeExportXLSX(&buf, rows)
+	default:
+		return nil, fmt.Errorf("unknown export format %q, use csv or xlsx", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s export: %w", format, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeExportCSV writes rows as CSV with a header line
+func writeExportCSV(w io.Writer, rows []ExportRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportColumns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, 0, len(exportColumns))
+		for _, cell := range row.cells() {
+			text := cell.Text
+			// Spreadsheet apps run text starting with these as formulas
+			if !cell.IsNumber && text != "" && strings.ContainsRune("=+-@", rune(text[0])) {
+				text = "'" + text
+			}
+			record = append(record, text)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// XLSX cell styles defined in xlsxStyles
+const (
+	xlsxStyleHeader = 1
+	xlsxStyleHours  = 2
+)
+
+// xlsxMaxCellChars is the most text a spreadsheet cell holds
+const xlsxMaxCellChars = 32767
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="Report" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles defines the default style, a bold header and numbers with two decimals
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`</styleSheet>`
+
+// writeExportXLSX writes rows as a single sheet workbook with a frozen header row
+func writeExportXLSX(w io.Writer, rows []ExportRow) error {
+	archive := zip.NewWriter(w)
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeXLSXSheet(sheet, rows); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// writeXLSXSheet writes the worksheet XML, text as inline strings
+func writeXLSXSheet(w io.Writer, rows []ExportRow) error {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	buf.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	buf.WriteString(`<sheetData>`)
+
+	header := make([]exportCell, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = textCell(column)
+	}
+	writeXLSXRow(&buf, 1, header, xlsxStyleHeader)
+	for i, row := range rows {
+		writeXLSXRow(&buf, i+2, row.cells(), 0)
+	}
+
+	buf.WriteString(`</sheetData></worksheet>`)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeXLSXRow writes one row; style applies to text cells, numbers always get two decimals
+func writeXLSXRow(buf *bytes.Buffer, number int, cells []exportCell, style int) {
+	fmt.Fprintf(buf, `<row r="%d">`, number)
+	for i, cell := range cells {
+		ref := fmt.Sprintf("%c%d", 'A'+i, number)
+		if cell.IsNumber {
+			fmt.Fprintf(buf, `<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleHours, strconv.FormatFloat(cell.Number, 'f', -1, 64))
+			continue
+		}
+		if cell.Text == "" {
+			continue
+		}
+		styleAttr := ""
+		if style != 0 {
+			styleAttr = fmt.Sprintf(` s="%d"`, style)
+		}
+		fmt.Fprintf(buf, `<c r="%s" t="inlineStr"%s><is><t xml:space="preserve">`, ref, styleAttr)
+		xml.EscapeText(buf, []byte(truncateUTF8(cell.Text, xlsxMaxCellChars)))
+		buf.WriteString(`</t></is></c>`)
+	}
+	buf.WriteString(`</row>`)
+}
+
+// reportUploadTarget returns where files for a command go: its channel or thread,
+// or with `private` the requester's direct messages. Subscriptions sent to a user
+// carry a user ID, which uploads can't target, so those open the direct message too.
+func reportUploadTarget(slackClient *SlackAPIClient, req *SlackCommandRequest, command *OYECommand) (string, string, error) {
+	userID := req.UserID
+	if strings.HasPrefix(req.ChannelID, "U") || strings.HasPrefix(req.ChannelID, "W") {
+		userID = req.ChannelID
+	} else if command.Delivery != SLACK_DELIVERY_PRIVATE {
+		return req.ChannelID, req.ThreadTS, nil
+	}
+	channelID, err := slackClient.openDirectMessage(userID)
+	return channelID, "", err
+}
+
+// runOYEExport builds a command's spreadsheet and uploads it to Slack: into the command's
+// channel or thread, or with `private` into the requester's direct messages
+func runOYEExport(req *SlackCommandRequest, command *OYECommand, projectFilter ProjectFilter) {
+	logger := GetGlobalLogger()
+	logger.Infof("Starting %s export for %s", command.Export, command.Period)
+
+	rows, err := buildExportRows(command, projectFilter)
+	if err != nil {
+		logger.Errorf("Failed to build export: %v", err)
+		replyToRequester(req, "Sorry, the export couldn't be built.")
+		return
+	}
+	if len(rows) == 0 {
+		replyToRequester(req, fmt.Sprintf("No tracked time found for %s.", command.Period))
+		return
+	}
+
+	content, err := renderExport(command.Export, rows)
+	if err != nil {
+		logger.Errorf("Failed to render export: %v", err)
+		replyToRequester(req, "Sorry, the export couldn't be built.")
+		return
+	}
+
+	slackClient := NewSlackAPIClient()
+	channelID, threadTS, err := reportUploadTarget(slackClient, req, command)
+	if err != nil {
+		logger.Errorf("Failed to open direct message for export: %v", err)
+		replyToRequester(req, "Sorry, I couldn't send you the export as a direct message.")
+		return
+	}
+
+	title := strings.TrimPrefix(reportTitle(command), EMOJI_CHART+" ")
+	comment := fmt.Sprintf("%s %s export: %d tasks", EMOJI_CHART, strings.ToUpper(command.Export), len(rows))
+	if req.UserID != "" && command.Delivery != SLACK_DELIVERY_PRIVATE {
+		comment += fmt.Sprintf(", requested by <@%s>", req.UserID)
+	}
+	if err := slackClient.uploadSlackFile(channelID, threadTS, exportFilename(command), title, comment, content); err != nil {
+		logger.Errorf("Failed to upload export to %s: %v", channelID, err)
+		replyToRequester(req, "Sorry, the export couldn't be uploaded. Make sure OYE is a member of this channel, or add `private` to get it as a direct message.")
+		return
+	}
+	logger.Infof("Uploaded %s export with %d rows to %s", command.Export, len(rows), channelID)
+}
+
+// handleExportCliCommand writes a report export to a local file:
+// export --from 2026-09-01 [--to 2026-09-30] [--project name,name] [--format csv|xlsx] [--output file]
+func handleExportCliCommand(args []string, logger *Logger) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	from := flags.String("from", "", "first day, YYYY-MM-DD")
+	to := flags.String("to", time.Now().Format("2006-01-02"), "last day, YYYY-MM-DD")
+	projects := flags.String("project", "", "comma separated project or project group names, all projects when empty")
+	format := flags.String("format", EXPORT_FORMAT_CSV, "csv or xlsx")
+	output := flags.String("output", "", "file to write, named after the period when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" {
+		return fmt.Errorf("usage: export --from YYYY-MM-DD [--to YYYY-MM-DD] [--project name,name] [--format csv|xlsx] [--output file]")
+	}
+
+	command, err := ParseOYECommand(fmt.Sprintf("from %s to %s export %s", *from, *to, *format))
+	if err != nil {
+		return fmt.Errorf("invalid export options: %s", formatOYEError(err))
+	}
+	for _, name := range strings.Split(*projects, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			command.Projects = append(command.Projects, name)
+		}
+	}
+	projectFilter, err := confirmProjects(command)
+	if err != nil {
+		return err
+	}
+
+	rows, err := buildExportRows(command, projectFilter)
+	if err != nil {
+		return err
+	}
+	content, err := renderExport(command.Export, rows)
+	if err != nil {
+		return err
+	}
+
+	path := *output
+	if path == "" {
+		path = exportFilename(command)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	logger.Infof("Wrote %d tasks to %s", len(rows), path)
+	fmt.Println(path)
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MattermostNotifier posts reports to a Mattermost incoming webhook using
+// Slack-compatible message attachments, one attachment per project.
+// The recipient overrides the webhook's default channel when set.
+type MattermostNotifier struct {
+	WebhookURL string
+	Channel    string
+}
+
+// mattermostPost is the incoming webhook payload
+type mattermostPost struct {
+	Channel     string                 `json:"channel,omitempty"`
+	Username    string                 `json:"username,omitempty"`
+	Text        string                 `json:"text,omitempty"`
+	Attachments []mattermostAttachment `json:"attachments,omitempty"`
+}
+
+type mattermostAttachment struct {
+	Fallback string `json:"fallback"`
+	Color    string `json:"color,omitempty"`
+	Title    string `json:"title"`
+	Text     string `json:"text"`
+}
+
+// NewMattermostNotifier creates a Mattermost notifier for an incoming webhook
+func NewMattermostNotifier(webhookURL, channel string) *MattermostNotifier {
+	return &MattermostNotifier{WebhookURL: webhookURL, Channel: channel}
+}
+
+func (n *MattermostNotifier) Name() string {
+	return "mattermost"
+}
+
+func (n *MattermostNotifier) Notify(recipient string, report Report) error {
+	channel := n.Channel
+	if recipient != "" {
+		channel = recipient
+	}
+
+	posts := buildMattermostPosts(report)
+	for i, post := range posts {
+		post.Channel = channel
+		post.Username = "OYE"
+		if err := postJSONToWebhook(n.WebhookURL, post); err != nil {
+			return fmt.Errorf("failed to send Mattermost post %d/%d: %w", i+1, len(posts), err)
+		}
+	}
+	return nil
+}
+
+// buildMattermostTaskText renders one task as Mattermost markdown
+func buildMattermostTaskText(task ReportTask) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("**%s**\n%s\n", task.Name, task.taskSummaryLine()))
+	for _, comment := range task.Comments {
+		if comment != "" {
+			sb.WriteString(fmt.Sprintf("- %s\n", comment))
+		}
+	}
+	return sb.String()
+}
+
+// buildMattermostPosts packs the report into as few posts as fit within MAX_MATTERMOST_POST_CHARS,
+// splitting a project across posts when it is too large on its own
+func buildMattermostPosts(report Report) []mattermostPost {
+	title := report.Title
+	if title == "" {
+		title = EMOJI_CHART + " Update"
+	}
+
+	var posts []mattermostPost
+	current := mattermostPost{Text: title}
+	currentChars := len(title)
+
+	flushPost := func() {
+		posts = append(posts, current)
+		current = mattermostPost{Text: title + " (continued)"}
+		currentChars = len(current.Text)
+	}
+
+	if report.Summary {
+		for _, summary := range report.ProjectSummaries() {
+			line := "\n" + report.summaryLine(summary, func(s string) string { return "**" + s + "**" })
+			if currentChars+len(line) > MAX_MATTERMOST_POST_CHARS {
+				flushPost()
+			}
+			current.Text += line
+			currentChars += len(line)
+		}
+		return append(posts, current)
+	}
+
+	for _, project := range report.Projects {
+		attachment := mattermostAttachment{
+			Fallback: project.Name,
+			Color:    "#1d9bd1",
+			Title:    fmt.Sprintf("%s %s", EMOJI_FOLDER, project.Name),
+		}
+		attachmentChars := len(attachment.Title)
+
+		for _, task := range project.Tasks {
+			taskText := buildMattermostTaskText(task)
+			if currentChars+attachmentChars+len(taskText) > MAX_MATTERMOST_POST_CHARS &&
+				(attachment.Text != "" || len(current.Attachments) > 0) {
+				if attachment.Text != "" {
+					current.Attachments = append(current.Attachments, attachment)
+					attachment.Title = fmt.Sprintf("%s %s (continued)", EMOJI_FOLDER, project.Name)
+					attachment.Text = ""
+					attachmentChars = len(attachment.Title)
+				}
+				flushPost()
+			}
+			attachment.Text += taskText
+			attachmentChars += len(taskText)
+		}
+
+		current.Attachments = append(current.Attachments, attachment)
+		currentChars += attachmentChars
+	}
+
+	if len(report.Projects) == 0 {
+		current.Text += "\nNo time was tracked in this period."
+	}
+	posts = append(posts, current)
+
+	return posts
+}
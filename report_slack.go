@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+)
+
+// buildTaskMessage builds the formatted message text for a task
+func buildTaskMessage(task ReportTask) string {
+	taskText := fmt.Sprintf("*%s*", task.Name)
+
+	// Add time spent on the task and estimation info if available
+	taskText += "\n" + task.taskSummaryLine()
+
+	// Add comments as unordered list
+	if len(task.Comments) > 0 {
+		taskText += "\n"
+		for _, comment := range task.Comments {
+			if comment != "" {
+				// Limit comment length to avoid overwhelming
+				if len(comment) > 100 {
+					comment = comment[:97] + "..."
+				}
+				taskText += fmt.Sprintf("• %s\n", comment)
+			}
+		}
+	}
+
+	return taskText
+}
+
+// createTaskBlocks processes tasks and returns message blocks with chunking logic
+func createTaskBlocks(projectTasks []ReportTask) [][]Block {
+	logger := GetGlobalLogger()
+	var allChunks [][]Block
+	var currentChunk []Block
+	currentBlockCount := 0
+	currentCharCount := 0
+
+	for _, task := range projectTasks {
+		logger.Infof("Processing task %d (%s) with %d comments", task.ID, task.Name, len(task.Comments))
+
+		taskText := buildTaskMessage(task)
+
+		// Create task block, cutting tasks with very long comment lists to the section limit
+		taskBlock := SectionBlock(truncateUTF8(taskText, MAX_SECTION_TEXT_CHARS))
+		blockCharCount := taskBlock.TextLength()
+
+		// Check if adding this block would exceed limits
+		if currentBlockCount+1 > MAX_SLACK_BLOCKS || currentCharCount+blockCharCount > MAX_MESSAGE_CHARS_BUFFER {
+			// Save current chunk if we have any blocks
+			if len(currentChunk) > 0 {
+				allChunks = append(allChunks, currentChunk)
+				// Reset for next chunk
+				currentChunk = []Block{}
+				currentBlockCount = 0
+				currentCharCount = 0
+			}
+		}
+
+		// Add block to current batch
+		currentChunk = append(currentChunk, taskBlock)
+		currentBlockCount++
+		currentCharCount += blockCharCount
+	}
+
+	// Add remaining blocks if any
+	if len(currentChunk) > 0 {
+		allChunks = append(allChunks, currentChunk)
+	}
+
+	return allChunks
+}
+
+// createProjectHeaderBlock creates a project header block
+func createProjectHeaderBlock(projectName string) Block {
+	return SectionBlock(fmt.Sprintf("%s *%s*", EMOJI_FOLDER, projectName)).
+		WithBlockID(reportBlockID(REPORT_PROJECT_BLOCK_PREFIX, projectName))
+}
+
+// createProjectHeaderBlocks creates the project header and, for re-runnable reports, its action buttons
+func createProjectHeaderBlocks(project ReportProject, query *ReportQuery) []Block {
+	blocks := []Block{createProjectHeaderBlock(project.Name)}
+	if query == nil {
+		return blocks
+	}
+	if value := encodeReportActionValue(project.Name, query); value != "" {
+		blocks = append(blocks, createProjectActionsBlock(project, value))
+	}
+	return blocks
+}
+
+// combineSummaryIntoMessages renders a summary report as one section line per project
+func combineSummaryIntoMessages(report Report) [][]Block {
+	var allMessages [][]Block
+	var currentMessage []Block
+	currentCharCount := 0
+
+	for _, summary := range report.ProjectSummaries() {
+		line := fmt.Sprintf("%s %s", EMOJI_FOLDER, report.summaryLine(summary, func(s string) string { return "*" + s + "*" }))
+		block := SectionBlock(line)
+		blockCharCount := block.TextLength()
+
+		if len(currentMessage) > 0 &&
+			(len(currentMessage)+1 > MAX_SLACK_BLOCKS || currentCharCount+blockCharCount > MAX_MESSAGE_CHARS_BUFFER) {
+			allMessages = append(allMessages, currentMessage)
+			currentMessage = nil
+			currentCharCount = 0
+		}
+		currentMessage = append(currentMessage, block)
+		currentCharCount += blockCharCount
+	}
+
+	if len(currentMessage) > 0 {
+		allMessages = append(allMessages, currentMessage)
+	}
+	return allMessages
+}
+
+// combineProjectsIntoMessages packs multiple projects into as few messages as possible
+func combineProjectsIntoMessages(report Report) [][]Block {
+	logger := GetGlobalLogger()
+	if report.Summary {
+		return combineSummaryIntoMessages(report)
+	}
+
+	var allMessages [][]Block
+	var currentMessage []Block
+	currentBlockCount := 0
+	currentCharCount := 0
+
+	for _, project := range report.Projects {
+		projectName := project.Name
+
+		// Create project header
+		projectHeader := createProjectHeaderBlocks(project, report.Query)
+
+		// Create task blocks for this project
+		taskChunks := createTaskBlocks(project.Tasks)
+
+		// Calculate size of this project (header + all task blocks)
+		projectBlocks := append([]Block{}, projectHeader...)
+		for _, chunk := range taskChunks {
+			projectBlocks = append(projectBlocks, chunk...)
+		}
+
+		// Calculate total character count for this project
+		projectCharCount := blocksTextLength(projectBlocks)
+		projectBlockCount := len(projectBlocks)
+
+		// Check if this project can fit in the current message
+		if currentBlockCount+projectBlockCount <= MAX_SLACK_BLOCKS &&
+			currentCharCount+projectCharCount <= MAX_MESSAGE_CHARS_BUFFER {
+			// Add this project to current message
+			currentMessage = append(currentMessage, projectBlocks...)
+			currentBlockCount += projectBlockCount
+			currentCharCount += projectCharCount
+			logger.Infof("Added project '%s' to current message (blocks: %d, chars: %d)",
+				projectName, projectBlockCount, projectCharCount)
+		} else {
+			// Current message is full, save it and start new one
+			if len(currentMessage) > 0 {
+				allMessages = append(allMessages, currentMessage)
+				logger.Infof("Completed message with %d blocks, %d chars", currentBlockCount, currentCharCount)
+			}
+
+			// Check if this project alone exceeds limits (needs splitting)
+			if projectBlockCount > MAX_SLACK_BLOCKS || projectCharCount > MAX_MESSAGE_CHARS_BUFFER {
+				logger.Infof("Project '%s' is too large, splitting into multiple messages", projectName)
+				// Add header to first chunk
+				if len(taskChunks) > 0 {
+					firstChunk := append(append([]Block{}, projectHeader...), taskChunks[0]...)
+					allMessages = append(allMessages, firstChunk)
+
+					// Add remaining chunks as separate messages
+					for i := 1; i < len(taskChunks); i++ {
+						allMessages = append(allMessages, taskChunks[i])
+					}
+				} else {
+					// Just the header
+					allMessages = append(allMessages, projectHeader)
+				}
+
+				// Nothing is carried over into the next message
+				currentMessage = nil
+				currentBlockCount = 0
+				currentCharCount = 0
+			} else {
+				// Start new message with this project
+				currentMessage = projectBlocks
+				currentBlockCount = projectBlockCount
+				currentCharCount = projectCharCount
+				logger.Infof("Started new message with project '%s' (blocks: %d, chars: %d)",
+					projectName, projectBlockCount, projectCharCount)
+			}
+		}
+	}
+
+	// Add final message if it has content
+	if len(currentMessage) > 0 {
+		allMessages = append(allMessages, currentMessage)
+		logger.Infof("Completed final message with %d blocks, %d chars", currentBlockCount, currentCharCount)
+	}
+
+	logger.Infof("Combined %d projects into %d messages", len(report.Projects), len(allMessages))
+	return allMessages
+}
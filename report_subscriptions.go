@@ -0,0 +1,487 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ReportSubscription is a report command run on a schedule and posted to a channel or user
+type ReportSubscription struct {
+	ID                  int
+	CreatedBy           string // Slack user ID
+	TargetID            string // Slack channel ID, or user ID for direct messages
+	CronSchedule        string // standard 5-field cron spec
+	ScheduleDescription string // e.g. "every Monday at 09:00"
+	Command             string // report command text, e.g. "project acme for last week"
+	CreatedAt           time.Time
+}
+
+// GetReportSubscriptions returns all report subscriptions ordered by ID
+func GetReportSubscriptions(db *sql.DB) ([]ReportSubscription, error) {
+	rows, err := db.Query(`
+		SELECT id, created_by, target_id, cron_schedule, schedule_description, command, created_at
+		FROM report_subscriptions
+		ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query report subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []ReportSubscription
+	for rows.Next() {
+		var sub ReportSubscription
+		if err := rows.Scan(&sub.ID, &sub.CreatedBy, &sub.TargetID, &sub.CronSchedule, &sub.ScheduleDescription, &sub.Command, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan report subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions, rows.Err()
+}
+
+// CreateReportSubscription stores a subscription and returns its ID
+func CreateReportSubscription(db *sql.DB, sub ReportSubscription) (int, error) {
+	var id int
+	err := db.QueryRow(`
+		INSERT INTO report_subscriptions (created_by, target_id, cron_schedule, schedule_description, command)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`,
+		sub.CreatedBy, sub.TargetID, sub.CronSchedule, sub.ScheduleDescription, sub.Command).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create report subscription: %w", err)
+	}
+	return id, nil
+}
+
+// DeleteReportSubscription removes a subscription created by userID
+func DeleteReportSubscription(db *sql.DB, id int, userID string) error {
+	var createdBy string
+	err := db.QueryRow(`SELECT created_by FROM report_subscriptions WHERE id = $1`, id).Scan(&createdBy)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("subscription #%d doesn't exist", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up subscription #%d: %w", id, err)
+	}
+	if createdBy != userID {
+		return fmt.Errorf("subscription #%d belongs to <@%s>, only they can remove it", id, createdBy)
+	}
+
+	if _, err := db.Exec(`DELETE FROM report_subscriptions WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete subscription #%d: %w", id, err)
+	}
+	return nil
+}
+
+// ReportScheduler keeps one cron entry per report subscription and
+// adds or removes entries as subscriptions change, without a restart
+type ReportScheduler struct {
+	cron    *cron.Cron
+	mu      sync.Mutex
+	entries map[int]reportSchedulerEntry
+}
+
+type reportSchedulerEntry struct {
+	entryID  cron.EntryID
+	schedule string
+	command  string
+	target   string
+}
+
+// reportScheduler is the running scheduler; nil outside of server mode
+var reportScheduler *ReportScheduler
+
+// NewReportScheduler creates a scheduler registering its jobs on the given cron instance
+func NewReportScheduler(c *cron.Cron) *ReportScheduler {
+	return &ReportScheduler{cron: c, entries: make(map[int]reportSchedulerEntry)}
+}
+
+// Sync reconciles the cron entries with the subscriptions stored in the database
+func (s *ReportScheduler) Sync() error {
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	subscriptions, err := GetReportSubscriptions(db)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	logger := GetGlobalLogger()
+	current := make(map[int]bool, len(subscriptions))
+	for _, sub := range subscriptions {
+		current[sub.ID] = true
+
+		existing, ok := s.entries[sub.ID]
+		if ok && existing.schedule == sub.CronSchedule && existing.command == sub.Command && existing.target == sub.TargetID {
+			continue
+		}
+		if ok {
+			s.cron.Remove(existing.entryID)
+		}
+
+		subscription := sub
+		entryID, err := s.cron.AddFunc(sub.CronSchedule, func() {
+			runReportSubscription(subscription)
+		})
+		if err != nil {
+			logger.Errorf("Failed to schedule report subscription #%d (%s): %v", sub.ID, sub.CronSchedule, err)
+			delete(s.entries, sub.ID)
+			continue
+		}
+		s.entries[sub.ID] = reportSchedulerEntry{entryID: entryID, schedule: sub.CronSchedule, command: sub.Command, target: sub.TargetID}
+		logger.Infof("Scheduled report subscription #%d: %s", sub.ID, sub.ScheduleDescription)
+	}
+
+	for id, entry := range s.entries {
+		if !current[id] {
+			s.cron.Remove(entry.entryID)
+			delete(s.entries, id)
+			logger.Infof("Removed report subscription #%d from the schedule", id)
+		}
+	}
+	return nil
+}
+
+// syncReportScheduler applies subscription changes to the running scheduler, if there is one
+func syncReportScheduler() {
+	if reportScheduler == nil {
+		return
+	}
+	if err := reportScheduler.Sync(); err != nil {
+		GetGlobalLogger().Errorf("Failed to sync report subscriptions: %v", err)
+	}
+}
+
+// runReportSubscription runs a subscription's command and posts the report to its target
+func runReportSubscription(sub ReportSubscription) {
+	logger := GetGlobalLogger()
+	logger.Infof("Running report subscription #%d: %s", sub.ID, sub.Command)
+
+	command, err := ParseOYECommand(sub.Command)
+	if err != nil {
+		logger.Errorf("Report subscription #%d has an invalid command '%s': %v", sub.ID, sub.Command, err)
+		return
+	}
+
+	projectFilter, err := confirmProjects(command)
+	if err != nil {
+		logger.Errorf("Report subscription #%d: %v", sub.ID, err)
+		return
+	}
+
+	// Runs for a period already reported, like a daily "this week", update the earlier report
+	runOYEReport(&SlackCommandRequest{ChannelID: sub.TargetID, UserID: sub.CreatedBy, Digest: fmt.Sprintf("subscription:%d", sub.ID)}, command, projectFilter)
+}
+
+// subscriptionFrequency is the schedule part of a subscribe command
+type subscriptionFrequency struct {
+	CronSchedule  string
+	Description   string
+	DefaultPeriod string // used when the command names no period
+}
+
+var (
+	subscriptionTimePattern    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	subscriptionDayPattern     = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)?$`)
+	slackChannelMentionPattern = regexp.MustCompile(`^<#([CG][A-Z0-9]+)(\|[^>]*)?>$`)
+)
+
+// parseSubscriptionTime parses "9", "9:30", "09:30", "9am" or "5:30pm"
+func parseSubscriptionTime(text string) (int, int, bool) {
+	match := subscriptionTimePattern.FindStringSubmatch(strings.ToLower(text))
+	if match == nil {
+		return 0, 0, false
+	}
+	hour, _ := strconv.Atoi(match[1])
+	minute := 0
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+	switch match[3] {
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+// parseSubscriptionFrequency parses the schedule at the start of the tokens and returns the index after it:
+//
+//	"daily" | "weekdays" [ "at" ] [ time ]
+//	"weekly" [ "on" ] weekday [ "at" ] [ time ]
+//	"monthly" [ "on" ] day [ "at" ] [ time ]
+func (p *oyeParser) parseSubscriptionFrequency(index int) (subscriptionFrequency, int, error) {
+	var frequency subscriptionFrequency
+	if index >= len(p.tokens) {
+		return frequency, index, p.errorAt(index, "missing schedule, e.g. `daily at 9:00` or `weekly on monday 9:00`")
+	}
+
+	kind := p.tokens[index].lower()
+	index++
+
+	// Weekly and monthly subscriptions name the day first
+	dayField, weekdayField := "*", "*"
+	var dayDescription string
+	switch kind {
+	case "daily":
+		frequency.DefaultPeriod = "yesterday"
+		dayDescription = "every day"
+	case "weekdays":
+		frequency.DefaultPeriod = "yesterday"
+		weekdayField = "1-5"
+		dayDescription = "every weekday"
+	case "weekly", "monthly":
+		if index < len(p.tokens) && p.tokens[index].lower() == "on" {
+			index++
+		}
+		if index >= len(p.tokens) {
+			return frequency, index, p.errorAt(index, "missing day after `%s`", kind)
+		}
+		if kind == "weekly" {
+			weekday, ok := parseWeekday(p.tokens[index].lower())
+			if !ok {
+				return frequency, index, p.errorAt(index, "expected a weekday like `monday`")
+			}
+			frequency.DefaultPeriod = "last week"
+			weekdayField = strconv.Itoa(int(weekday))
+			dayDescription = "every " + weekday.String()
+		} else {
+			match := subscriptionDayPattern.FindStringSubmatch(p.tokens[index].lower())
+			day := 0
+			if match != nil {
+				day, _ = strconv.Atoi(match[1])
+			}
+			// Days after the 28th don't exist in every month
+			if day < 1 || day > 28 {
+				return frequency, index, p.errorAt(index, "expected a day of the month from 1 to 28")
+			}
+			frequency.DefaultPeriod = "last month"
+			dayField = strconv.Itoa(day)
+			dayDescription = fmt.Sprintf("on day %d of every month", day)
+		}
+		index++
+	default:
+		return frequency, index - 1, p.errorAt(index-1, "expected `daily`, `weekdays`, `weekly` or `monthly`")
+	}
+
+	hour, minute := 9, 0
+	if index < len(p.tokens) && p.tokens[index].lower() == "at" {
+		index++
+		if index >= len(p.tokens) {
+			return frequency, index, p.errorAt(index, "missing time after `at`")
+		}
+		var ok bool
+		if hour, minute, ok = parseSubscriptionTime(p.tokens[index].Text); !ok {
+			return frequency, index, p.errorAt(index, "expected a time like `9:00` or `17:30`")
+		}
+		index++
+	} else if index < len(p.tokens) {
+		if h, m, ok := parseSubscriptionTime(p.tokens[index].Text); ok && !p.tokens[index].Quoted {
+			hour, minute = h, m
+			index++
+		}
+	}
+
+	frequency.CronSchedule = fmt.Sprintf("%d %d %s * %s", minute, hour, dayField, weekdayField)
+	frequency.Description = fmt.Sprintf("%s at %02d:%02d", dayDescription, hour, minute)
+	return frequency, index, nil
+}
+
+// parseSubscribeCommand parses `subscribe <schedule> [report clauses] [to #channel|me|here]`
+// into a subscription for the requesting user and channel
+func parseSubscribeCommand(text string, req *SlackCommandRequest) (*ReportSubscription, error) {
+	tokens, err := tokenizeOYECommand(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &oyeParser{input: text, tokens: tokens, now: time.Now()}
+	if len(tokens) == 0 || tokens[0].lower() != "subscribe" {
+		return nil, p.errorAt(0, "expected `subscribe`")
+	}
+
+	frequency, index, err := p.parseSubscriptionFrequency(1)
+	if err != nil {
+		return nil, err
+	}
+
+	// The target comes last so it doesn't get mixed up with project names
+	end := len(tokens)
+	target := req.ChannelID
+	if end-2 >= index && tokens[end-2].lower() == "to" {
+		targetToken := tokens[end-1]
+		switch {
+		case targetToken.lower() == "me":
+			target = req.UserID
+		case targetToken.lower() == "here":
+			target = req.ChannelID
+		case slackChannelMentionPattern.MatchString(targetToken.Text):
+			target = slackChannelMentionPattern.FindStringSubmatch(targetToken.Text)[1]
+		case strings.HasPrefix(targetToken.Text, "#"):
+			return nil, p.errorAt(end-1, "pick the channel from Slack's suggestions so it's sent as a link")
+		default:
+			return nil, p.errorAt(end-1, "expected a channel like `#team`, `me` or `here`")
+		}
+		end -= 2
+	}
+
+	reportText := strings.TrimSpace(p.joinTokensQuoted(index, end))
+	command := &OYECommand{}
+	reportParser := &oyeParser{input: text, tokens: tokens[:end], now: p.now}
+	if err := reportParser.parseClauses(index, command); err != nil {
+		return nil, err
+	}
+	if command.Delivery == SLACK_DELIVERY_PRIVATE {
+		return nil, p.errorAt(index, "scheduled reports can't be private, use `to me` for a direct message")
+	}
+	if command.Period == "" {
+		reportText = strings.TrimSpace(reportText + " for " + frequency.DefaultPeriod)
+	}
+
+	// Make sure the stored command runs
+	if _, err := ParseOYECommand(reportText); err != nil {
+		return nil, err
+	}
+
+	return &ReportSubscription{
+		CreatedBy:           req.UserID,
+		TargetID:            target,
+		CronSchedule:        frequency.CronSchedule,
+		ScheduleDescription: frequency.Description,
+		Command:             reportText,
+	}, nil
+}
+
+// joinTokensQuoted joins tokens[from:to] back into command text, re-quoting quoted names
+func (p *oyeParser) joinTokensQuoted(from, to int) string {
+	words := make([]string, 0, to-from)
+	for _, token := range p.tokens[from:to] {
+		if token.Quoted {
+			words = append(words, `"`+token.Text+`"`)
+		} else {
+			words = append(words, token.Text)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// isSubscriptionCommand reports whether the /oye text manages subscriptions
+func isSubscriptionCommand(text string) bool {
+	fields := strings.Fields(strings.ToLower(text))
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "subscribe", "subscriptions", "unsubscribe":
+		return true
+	}
+	return false
+}
+
+// formatSubscriptionTarget renders a subscription target as a Slack mention
+func formatSubscriptionTarget(targetID string) string {
+	if strings.HasPrefix(targetID, "U") || strings.HasPrefix(targetID, "W") {
+		return fmt.Sprintf("<@%s> (direct message)", targetID)
+	}
+	return fmt.Sprintf("<#%s>", targetID)
+}
+
+// nextSubscriptionRun returns when a cron schedule fires next
+func nextSubscriptionRun(cronSchedule string) string {
+	schedule, err := cron.ParseStandard(cronSchedule)
+	if err != nil {
+		return "unknown"
+	}
+	return schedule.Next(time.Now()).Format("Mon Jan 2 15:04")
+}
+
+// handleSubscriptionCommand handles `/oye subscribe ...`, `/oye subscriptions` and `/oye unsubscribe <id>`
+func handleSubscriptionCommand(w http.ResponseWriter, req *SlackCommandRequest, text string) {
+	logger := GetGlobalLogger()
+
+	db, err := GetDB()
+	if err != nil {
+		logger.Errorf("Failed to get database connection for subscriptions: %v", err)
+		sendImmediateResponse(w, "Sorry, subscriptions are unavailable right now.", "ephemeral")
+		return
+	}
+
+	fields := strings.Fields(text)
+	switch strings.ToLower(fields[0]) {
+	case "subscribe":
+		sub, err := parseSubscribeCommand(text, req)
+		if err != nil {
+			sendImmediateResponse(w, formatOYEError(err), "ephemeral")
+			return
+		}
+		if sub.ID, err = CreateReportSubscription(db, *sub); err != nil {
+			logger.Errorf("Failed to save subscription: %v", err)
+			sendImmediateResponse(w, "Sorry, the subscription couldn't be saved.", "ephemeral")
+			return
+		}
+		syncReportScheduler()
+
+		logger.Infof("User %s subscribed %s to '%s' (%s)", req.UserID, sub.TargetID, sub.Command, sub.CronSchedule)
+		sendImmediateResponse(w, fmt.Sprintf("✅ Subscription #%d: `%s` %s, posted to %s\nNext report: %s. Make sure OYE is a member of the channel.",
+			sub.ID, sub.Command, sub.ScheduleDescription, formatSubscriptionTarget(sub.TargetID), nextSubscriptionRun(sub.CronSchedule)), "ephemeral")
+
+	case "subscriptions":
+		subscriptions, err := GetReportSubscriptions(db)
+		if err != nil {
+			logger.Errorf("Failed to list subscriptions: %v", err)
+			sendImmediateResponse(w, "Sorry, subscriptions couldn't be loaded.", "ephemeral")
+			return
+		}
+
+		var lines []string
+		for _, sub := range subscriptions {
+			if sub.CreatedBy != req.UserID && sub.TargetID != req.ChannelID {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("• *#%d* `%s` %s → %s (by <@%s>, next %s)",
+				sub.ID, sub.Command, sub.ScheduleDescription, formatSubscriptionTarget(sub.TargetID), sub.CreatedBy, nextSubscriptionRun(sub.CronSchedule)))
+		}
+		if len(lines) == 0 {
+			sendImmediateResponse(w, "No subscriptions for you or this channel yet. Try `/oye subscribe weekly on monday 9:00 project acme to #acme-team`", "ephemeral")
+			return
+		}
+		sendImmediateResponse(w, "*📅 Report subscriptions*\n"+strings.Join(lines, "\n"), "ephemeral")
+
+	case "unsubscribe":
+		if len(fields) != 2 {
+			sendImmediateResponse(w, "Usage: `/oye unsubscribe <id>`, see `/oye subscriptions` for IDs", "ephemeral")
+			return
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(fields[1], "#"))
+		if err != nil {
+			sendImmediateResponse(w, fmt.Sprintf("`%s` isn't a subscription ID", fields[1]), "ephemeral")
+			return
+		}
+		if err := DeleteReportSubscription(db, id, req.UserID); err != nil {
+			sendImmediateResponse(w, err.Error(), "ephemeral")
+			return
+		}
+		syncReportScheduler()
+
+		logger.Infof("User %s removed subscription #%d", req.UserID, id)
+		sendImmediateResponse(w, fmt.Sprintf("🗑 Subscription #%d removed", id), "ephemeral")
+	}
+}
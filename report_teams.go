@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TeamsNotifier posts reports to a Microsoft Teams incoming webhook as Adaptive Cards.
+// Teams webhooks are bound to a single channel, so the recipient is ignored.
+type TeamsNotifier struct {
+	WebhookURL string
+}
+
+// NewTeamsNotifier creates a Teams notifier for an incoming webhook
+func NewTeamsNotifier(webhookURL string) *TeamsNotifier {
+	return &TeamsNotifier{WebhookURL: webhookURL}
+}
+
+func (n *TeamsNotifier) Name() string {
+	return "teams"
+}
+
+func (n *TeamsNotifier) Notify(recipient string, report Report) error {
+	cards := buildTeamsCards(report)
+	for i, card := range cards {
+		if err := postJSONToWebhook(n.WebhookURL, teamsMessage(card)); err != nil {
+			return fmt.Errorf("failed to send Teams card %d/%d: %w", i+1, len(cards), err)
+		}
+	}
+	return nil
+}
+
+// teamsMessage wraps an Adaptive Card body in the incoming webhook message envelope
+func teamsMessage(body []map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"msteams": map[string]interface{}{"width": "Full"},
+					"body":    body,
+				},
+			},
+		},
+	}
+}
+
+// teamsTextBlock creates an Adaptive Card TextBlock element
+func teamsTextBlock(text, size string, bold, separator bool) map[string]interface{} {
+	block := map[string]interface{}{
+		"type": "TextBlock",
+		"text": text,
+		"wrap": true,
+	}
+	if size != "" {
+		block["size"] = size
+	}
+	if bold {
+		block["weight"] = "Bolder"
+	}
+	if separator {
+		block["separator"] = true
+	}
+	return block
+}
+
+// buildTeamsTaskText renders one task as Adaptive Card markdown
+func buildTeamsTaskText(task ReportTask) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("**%s**\n\n%s", task.Name, task.taskSummaryLine()))
+	for _, comment := range task.Comments {
+		if comment != "" {
+			sb.WriteString(fmt.Sprintf("\n- %s", comment))
+		}
+	}
+	return sb.String()
+}
+
+// teamsElementSize returns the serialized size of a card element
+func teamsElementSize(element map[string]interface{}) int {
+	encoded, _ := json.Marshal(element)
+	return len(encoded)
+}
+
+// buildTeamsCards packs the report into Adaptive Card bodies that each stay under MAX_TEAMS_PAYLOAD_BYTES
+func buildTeamsCards(report Report) [][]map[string]interface{} {
+	title := report.Title
+	if title == "" {
+		title = EMOJI_CHART + " Update"
+	}
+
+	// Leave room for the message envelope around the card body
+	envelopeBytes := teamsElementSize(teamsMessage(nil))
+
+	var cards [][]map[string]interface{}
+	titleBlock := teamsTextBlock(title, "Large", true, false)
+	current := []map[string]interface{}{titleBlock}
+	currentBytes := envelopeBytes + teamsElementSize(titleBlock)
+
+	addElement := func(element map[string]interface{}) {
+		size := teamsElementSize(element) + 1 // separating comma
+		if currentBytes+size > MAX_TEAMS_PAYLOAD_BYTES && len(current) > 1 {
+			cards = append(cards, current)
+			continued := teamsTextBlock(title+" (continued)", "Large", true, false)
+			current = []map[string]interface{}{continued}
+			currentBytes = envelopeBytes + teamsElementSize(continued)
+		}
+		current = append(current, element)
+		currentBytes += size
+	}
+
+	if report.Summary {
+		for _, summary := range report.ProjectSummaries() {
+			addElement(teamsTextBlock(report.summaryLine(summary, func(s string) string { return "**" + s + "**" }), "", false, true))
+		}
+		return append(cards, current)
+	}
+
+	for _, project := range report.Projects {
+		addElement(teamsTextBlock(fmt.Sprintf("%s %s", EMOJI_FOLDER, project.Name), "Medium", true, true))
+		for _, task := range project.Tasks {
+			addElement(teamsTextBlock(buildTeamsTaskText(task), "", false, false))
+		}
+	}
+
+	if len(report.Projects) == 0 {
+		addElement(teamsTextBlock("No time was tracked in this period.", "", false, false))
+	}
+	cards = append(cards, current)
+
+	return cards
+}
@@ -12,8 +12,6 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"regexp"
-	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -21,11 +19,22 @@ import (
 
 func StartServer(logger *Logger) {
 	// Unified handler for all OYE commands (protected by Slack signature verification)
-	http.Handle("/slack/oye", slackSignatureMiddleware(http.HandlerFunc(handleUnifiedOYECommand)))
+	http.Handle("/slack/oye", instrumentSlashCommand(slackSignatureMiddleware(http.HandlerFunc(handleUnifiedOYECommand))))
 
 	// New App Home routes (protected by Slack signature verification)
 	http.Handle("/slack/events", slackSignatureMiddleware(http.HandlerFunc(HandleAppHome)))
 	http.Handle("/slack/interactive", slackSignatureMiddleware(http.HandlerFunc(HandleInteractiveComponents)))
+	http.Handle("/slack/options", slackSignatureMiddleware(http.HandlerFunc(HandleBlockSuggestions)))
+
+	// Health checks for load balancers and orchestrators
+	http.HandleFunc("GET /healthz", handleHealthz)
+	http.HandleFunc("GET /readyz", handleReadyz)
+
+	// REST API for internal dashboards (protected by API tokens)
+	registerAPIRoutes(http.DefaultServeMux)
+
+	// Web dashboard (protected by Slack sign-in sessions)
+	registerDashboardRoutes(http.DefaultServeMux)
 
 	server := &http.Server{
 		Addr:              ":8080",
@@ -36,6 +45,17 @@ func StartServer(logger *Logger) {
 		MaxHeaderBytes:    1 << 20, // 1 MiB
 	}
 
+	// Prometheus metrics on their own port
+	metricsServer := newMetricsServer()
+	if metricsServer != nil {
+		go func() {
+			logger.Infof("Metrics are served on %s/metrics", metricsServer.Addr)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Errorf("Could not start metrics server: %v", err)
+			}
+		}()
+	}
+
 	// Goroutine for graceful shutdown
 	go func() {
 		stop := make(chan os.Signal, 1)
@@ -44,6 +64,9 @@ func StartServer(logger *Logger) {
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
+		if metricsServer != nil {
+			metricsServer.Shutdown(ctx)
+		}
 		if err := server.Shutdown(ctx); err != nil {
 			logger.Fatalf("Server shutdown failed: %v", err)
 		}
@@ -95,11 +118,7 @@ func slackSignatureMiddleware(next http.Handler) http.Handler {
 		r.Body.Close()
 		r.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 
-		// Build base string and compute HMAC SHA256
-		base := "v0:" + timestamp + ":" + string(bodyBytes)
-		mac := hmac.New(sha256.New, []byte(signingSecret))
-		mac.Write([]byte(base))
-		expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+		expected := computeRequestSignature(signingSecret, timestamp, bodyBytes)
 
 		// Constant-time compare
 		if !hmac.Equal([]byte(expected), []byte(signature)) {
@@ -112,6 +131,15 @@ func slackSignatureMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// computeRequestSignature builds the Slack-style "v0=" HMAC SHA256 signature of a request body.
+// Used both to verify inbound Slack requests and to sign outbound webhooks.
+func computeRequestSignature(secret, timestamp string, body []byte) string {
+	base := "v0:" + timestamp + ":" + string(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(base))
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
 // handleUnifiedOYECommand handles the new unified /oye command
 func handleUnifiedOYECommand(responseWriter http.ResponseWriter, request *http.Request) {
 	logger := GetGlobalLogger()
@@ -136,503 +164,233 @@ func handleUnifiedOYECommand(responseWriter http.ResponseWriter, request *http.R
 
 	logger.Infof("Received /oye command from user %s: %s", req.UserName, req.Text)
 
-	commandText := strings.ToLower(strings.TrimSpace(req.Text))
-
-	//string replace /oye with ""
-	commandText = strings.Replace(commandText, "/oye", "", 1)
+	commandText := strings.TrimSpace(req.Text)
 
-	// Guard against empty command (e.g., user typed just /oye)
-	fields := strings.Fields(commandText)
-	if len(fields) == 0 {
-		sendUnifiedHelp(responseWriter)
+	// A bare /oye opens the report builder, falling back to help if the modal can't be opened
+	if commandText == "" {
+		if err := OpenReportBuilderModal(req.TriggerID, req.ChannelID); err != nil {
+			logger.Errorf("Failed to open report builder modal: %v", err)
+			sendUnifiedHelp(responseWriter)
+			return
+		}
+		responseWriter.WriteHeader(http.StatusOK)
 		return
 	}
 
-	//if the first word is not in the allowed commands, send help
-	firstWord := fields[0]
-	allowedCommands := []string{"project", "for", "over"}
-	if !slices.Contains(allowedCommands, firstWord) {
-		sendUnifiedHelp(responseWriter)
+	if isDashboardCommand(commandText) {
+		handleDashboardCommand(responseWriter, req)
 		return
 	}
 
-	projectName, err := confirmProject(commandText)
-	if err != nil {
-		logger.Errorf(err.Error())
-		sendImmediateResponse(responseWriter, err.Error(), "ephemeral")
-		return
-	}
-	// Stricter validation: if command starts with "project" but no project parsed, return clear error
-	if projectName == "" && firstWord == "project" {
-		sendImmediateResponse(responseWriter, "Missing project name. Use: `/oye project [project name] for [period]` or `/oye project [project name] over [percentage] for [period]`", "ephemeral")
+	if isStatusCommand(commandText) {
+		handleStatusCommand(responseWriter, req)
 		return
 	}
 
-	percentage, err := confirmPercentage(commandText)
-	if err != nil {
-		logger.Errorf(err.Error())
-		sendImmediateResponse(responseWriter, err.Error(), "ephemeral")
-		return
-	}
-	// Stricter validation: if command starts with "over" but no percentage parsed, return clear error
-	if percentage == "" && firstWord == "over" {
-		sendImmediateResponse(responseWriter, "Missing percentage value. Use: `/oye over [percentage] for [period]` or `/oye project [project name] over [percentage] for [period]`", "ephemeral")
+	if isOutboxCommand(commandText) {
+		handleOutboxCommand(responseWriter, req)
 		return
 	}
 
-	startTime, endTime, err := confirmPeriod(commandText)
-	if err != nil {
-		logger.Errorf(err.Error())
-		sendImmediateResponse(responseWriter, err.Error(), "ephemeral")
+	// Scheduled report subscriptions
+	if isSubscriptionCommand(commandText) {
+		handleSubscriptionCommand(responseWriter, req, commandText)
 		return
 	}
 
-	// Send immediate ephemeral ack to prevent timeout. We'll post the visible thread anchor via bot API.
-	initialMessage := map[string]interface{}{
-		"response_type": "ephemeral",
-		"text":          "Working on it… posting an update thread shortly",
-	}
-
-	initialPayloadBytes, err := json.Marshal(initialMessage)
-	if err != nil {
-		logger.Errorf("Failed to marshal initial message payload: %v", err)
-		http.Error(responseWriter, "Internal server error", http.StatusInternalServerError)
+	// Guard against unknown commands
+	if !isOYECommandStart(commandText) {
+		sendUnifiedHelp(responseWriter)
 		return
 	}
 
-	responseWriter.Header().Set("Content-Type", "application/json")
-	responseWriter.WriteHeader(http.StatusOK)
-	responseWriter.Write(initialPayloadBytes)
-
-	// Process data asynchronously in background
-	go func() {
-		logger.Infof("Starting background processing for /oye command")
-
-		filteredTasks := getFilteredTasksWithTimeout(startTime, endTime, []string{projectName}, percentage)
-		if len(filteredTasks) == 0 {
-			logger.Info("No tasks found in background processing")
-			return
-		}
-
-		filteredTasks = addCommentsToTasksWithTimeout(filteredTasks, startTime, endTime)
-		filteredTasksGroupedByProject := groupTasksByProject(filteredTasks)
-
-		sendTasksGroupedByProjectAsync(req, filteredTasksGroupedByProject)
-	}()
-}
-
-/* Gets the project name from the command text
- * If the project name is not found, returns an error
- * If the project name is found, but is not in the database, returns an error
- * If the project name is found, and is in the database, returns the project name and nil
- */
-func confirmProject(commandText string) (string, error) {
-	projectName := ""
-	projectNameRegex := regexp.MustCompile(`project (.*?) (for|over)`)
-
-	matches := projectNameRegex.FindStringSubmatch(commandText)
-	if len(matches) >= 1 {
-		projectName = strings.TrimSpace(matches[1])
-	} else {
-		return "", nil
-	}
-
-	if projectName == "" {
-		return "", fmt.Errorf("failed to parse project name from command")
-	}
-
-	db, err := GetDB()
+	command, err := ParseOYECommand(commandText)
 	if err != nil {
-		return "", fmt.Errorf("failed to get database: %v", err)
+		logger.Warnf("Failed to parse /oye command '%s': %v", commandText, err)
+		sendImmediateResponse(responseWriter, formatOYEError(err), "ephemeral")
+		return
 	}
 
-	_, err = FindProjectsByName(db, projectName)
+	projectFilter, err := confirmProjects(command)
 	if err != nil {
-		return "", err
+		logger.Errorf(err.Error())
+		sendImmediateResponse(responseWriter, err.Error(), "ephemeral")
+		return
 	}
 
-	return projectName, nil
-}
-
-/* Gets the percentage from the command text
- * If the percentage is not found, returns an error
- * If the percentage is found, returns the percentage and nil
- */
-func confirmPercentage(commandText string) (string, error) {
-	percentage := ""
-	percentageRegex := regexp.MustCompile(`over (.*?) for`)
+	logger.Infof("Period '%s' resolved to: %s to %s", command.Period, command.Start.Format("2006-01-02 15:04:05"), command.End.Format("2006-01-02 15:04:05"))
 
-	matches := percentageRegex.FindStringSubmatch(commandText)
-	if len(matches) >= 1 {
-		percentage = strings.TrimSpace(matches[1])
-	} else {
-		return "", nil
+	// Send immediate ephemeral ack to prevent timeout. The report itself is posted in the background.
+	ackText := "Working on it… posting an update thread shortly"
+	switch commandDelivery(command) {
+	case SLACK_DELIVERY_PRIVATE:
+		ackText = "Working on it… your private report will appear here shortly"
+	case SLACK_DELIVERY_SHARE:
+		ackText = "Working on it… sharing the report in this channel shortly"
 	}
-
-	if percentage == "" {
-		return "", fmt.Errorf("failed to parse percentage from command")
+	if command.Export != "" {
+		ackText = "Working on it… uploading the spreadsheet to this channel shortly"
+		if command.Delivery == SLACK_DELIVERY_PRIVATE {
+			ackText = "Working on it… the spreadsheet will arrive as a direct message shortly"
+		}
 	}
+	sendImmediateResponse(responseWriter, ackText, "ephemeral")
 
-	return percentage, nil
+	// Process data asynchronously in background
+	go runOYEReport(req, command, projectFilter)
 }
 
-/* Gets the period from the command text
- * If the period is not found, returns an error
- * If the period is found, checks if it is a valid period
- * If the period is found, and is valid, returns the period's start and end times and nil
- * If the period is found, and is invalid, returns an error
- */
-func confirmPeriod(commandText string) (time.Time, time.Time, error) {
-	logger := GetGlobalLogger()
-	period := ""
-	periodRegex := regexp.MustCompile(`for (.*)`)
-
-	matches := periodRegex.FindStringSubmatch(commandText)
-	if len(matches) >= 1 {
-		period = strings.TrimSpace(matches[1])
-	}
-
-	if period == "" {
-		return time.Time{}, time.Time{}, fmt.Errorf("failed to parse period from command")
-	}
-
-	logger.Infof("Parsing period: '%s'", period)
-	now := time.Now()
-	var startTime, endTime time.Time
-
-	// Check for "last x days" pattern
-	lastDaysRegex := regexp.MustCompile(`^last (\d+) days?$`)
-	if lastDaysRegex.MatchString(period) {
-		matches := lastDaysRegex.FindStringSubmatch(period)
-		if len(matches) > 1 {
-			days := matches[1]
-			// Convert to int to validate it's a positive number
-			if daysInt, err := strconv.Atoi(days); err != nil {
-				return time.Time{}, time.Time{}, fmt.Errorf("invalid number in 'last %s days': not a valid number", days)
-			} else if daysInt <= 0 {
-				return time.Time{}, time.Time{}, fmt.Errorf("invalid number in 'last %s days': must be a positive number", days)
-			} else {
-				// Start: x days ago at 0:00, End: today at 23:59
-				startTime = now.AddDate(0, 0, -daysInt).Truncate(24 * time.Hour)
-				endTime = time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 999999999, now.Location())
-				logger.Infof("Period '%s' resolved to: %s to %s", period, startTime.Format("2006-01-02 15:04:05"), endTime.Format("2006-01-02 15:04:05"))
-				return startTime, endTime, nil
-			}
-		}
+// commandDelivery returns the delivery mode of a command, posting in a thread by default
+func commandDelivery(command *OYECommand) string {
+	if command.Delivery == "" {
+		return SLACK_DELIVERY_THREAD
 	}
-
-	switch period {
-	case "today":
-		// Today 0:00 to today 23:59
-		startTime = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		endTime = time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 999999999, now.Location())
-	case "yesterday":
-		// Yesterday 0:00 to yesterday 23:59
-		yesterday := now.AddDate(0, 0, -1)
-		startTime = time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 0, 0, 0, 0, yesterday.Location())
-		endTime = time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 23, 59, 59, 999999999, yesterday.Location())
-	case "this week":
-		// Monday 0:00 to Sunday 23:59 of current week
-		weekday := int(now.Weekday())
-		if weekday == 0 { // Sunday
-			weekday = 7
-		}
-		monday := now.AddDate(0, 0, -(weekday - 1))
-		sunday := monday.AddDate(0, 0, 6)
-		startTime = time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, monday.Location())
-		endTime = time.Date(sunday.Year(), sunday.Month(), sunday.Day(), 23, 59, 59, 999999999, sunday.Location())
-	case "last week":
-		// Monday 0:00 to Sunday 23:59 of last week
-		weekday := int(now.Weekday())
-		if weekday == 0 { // Sunday
-			weekday = 7
-		}
-		lastMonday := now.AddDate(0, 0, -(weekday-1)-7)
-		lastSunday := lastMonday.AddDate(0, 0, 6)
-		startTime = time.Date(lastMonday.Year(), lastMonday.Month(), lastMonday.Day(), 0, 0, 0, 0, lastMonday.Location())
-		endTime = time.Date(lastSunday.Year(), lastSunday.Month(), lastSunday.Day(), 23, 59, 59, 999999999, lastSunday.Location())
-	case "this month":
-		// First day of month 0:00 to last day of month 23:59
-		firstDay := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-		lastDay := firstDay.AddDate(0, 1, -1)
-		startTime = firstDay
-		endTime = time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day(), 23, 59, 59, 999999999, lastDay.Location())
-	case "last month":
-		// First day of last month 0:00 to last day of last month 23:59
-		firstDayLastMonth := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
-		lastDayLastMonth := firstDayLastMonth.AddDate(0, 1, -1)
-		startTime = firstDayLastMonth
-		endTime = time.Date(lastDayLastMonth.Year(), lastDayLastMonth.Month(), lastDayLastMonth.Day(), 23, 59, 59, 999999999, lastDayLastMonth.Location())
-	default:
-		return time.Time{}, time.Time{}, fmt.Errorf("invalid period: %s", period)
-	}
-
-	logger.Infof("Period '%s' resolved to: %s to %s", period, startTime.Format("2006-01-02 15:04:05"), endTime.Format("2006-01-02 15:04:05"))
-	return startTime, endTime, nil
+	return command.Delivery
 }
 
-func sendTasksGroupedByProjectAsync(req *SlackCommandRequest, projectGroups map[string][]TaskInfo) {
+// runOYEReport runs a parsed report command and delivers the result to the requesting channel or user.
+// The slash command, the report builder modal and bot mentions all end up here.
+func runOYEReport(req *SlackCommandRequest, command *OYECommand, projectFilter ProjectFilter) {
 	logger := GetGlobalLogger()
-	logger.Infof("Starting sendTasksGroupedByProjectAsync with %d project groups", len(projectGroups))
+	logger.Infof("Starting background processing for /oye command")
 
-	if len(projectGroups) == 0 {
-		logger.Info("No tasks to send in async processing, returning early")
+	if command.Export != "" {
+		runOYEExport(req, command, projectFilter)
 		return
 	}
 
-	if req.ChannelID == "" {
-		logger.Error("No channel ID provided, cannot send threaded messages")
+	startTime, endTime := command.Start, command.End
+	filteredTasks := getFilteredTasksWithTimeout(startTime, endTime, projectFilter.Include, command.Percentage)
+	filteredTasks = filterTasksByProjectNames(filteredTasks, nil, projectFilter.Exclude)
+	if len(filteredTasks) == 0 {
+		logger.Info("No tasks found in background processing")
+		// Let the requester know instead of leaving the ack unanswered
+		replyToRequester(req, fmt.Sprintf("No tracked time found for %s.", command.Period))
 		return
 	}
 
-	logger.Infof("Using Slack Bot API for threaded messages in channel: %s", req.ChannelID)
+	filteredTasks = addCommentsToTasksWithTimeout(filteredTasks, startTime, endTime)
+	filteredTasksGroupedByProject := groupTasksByProject(filteredTasks)
 
-	// Post the thread anchor via bot API and use returned ts to avoid races
-	slackClient := NewSlackAPIClient()
-	initResp, initErr := slackClient.sendSlackAPIRequestWithResponse("chat.postMessage", map[string]interface{}{
-		"channel": req.ChannelID,
-		"text":    "\U0001F4CA Update in thread",
-	})
-	if initErr != nil {
-		logger.Errorf("Failed to post initial thread message: %v", initErr)
-		return
+	report := NewReport(reportTitle(command), filteredTasksGroupedByProject)
+	report.Query = &ReportQuery{Period: command.Period, Start: startTime, End: endTime, Percentage: command.Percentage}
+	report = report.WithOptions(command.Options)
+	reportChannel, reportTS := sendTasksGroupedByProjectAsync(req, report, commandDelivery(command))
+	if command.Chart {
+		runOYECharts(req, command, filteredTasksGroupedByProject, reportChannel, reportTS)
 	}
-	threadTimestamp := initResp.Timestamp
-
-	// Get threshold values from environment variables
-	midPoint, highPoint := getThresholdValues()
-	logger.Infof("Using thresholds - MID_POINT: %.1f, HIGH_POINT: %.1f", midPoint, highPoint)
-
-	// Combine all projects into optimized messages
-	combinedMessages := combineProjectsIntoMessages(projectGroups)
+}
 
-	// Send all combined messages as threaded replies
-	for i, messageBlocks := range combinedMessages {
-		logger.Infof("Sending combined message %d/%d with %d blocks", i+1, len(combinedMessages), len(messageBlocks))
-		if err := sendSlackMessage(req.ChannelID, messageBlocks, threadTimestamp); err != nil {
-			logger.Errorf("Failed to send combined message %d: %v", i+1, err)
-			continue
+// replyToRequester tells whoever ran a command about a problem: privately through the
+// slash command's response_url, or in the thread of a bot question. Scheduled runs have neither.
+func replyToRequester(req *SlackCommandRequest, text string) {
+	logger := GetGlobalLogger()
+	if req.ResponseURL != "" {
+		if err := postSlackResponse(req.ResponseURL, SlackCommandResponse{ResponseType: "ephemeral", Text: text}); err != nil {
+			logger.Errorf("Failed to send notice to %s: %v", req.UserID, err)
+		}
+	} else if req.ThreadTS != "" {
+		if err := replyInThread(req.ChannelID, req.ThreadTS, text); err != nil {
+			logger.Errorf("Failed to send notice to %s: %v", req.UserID, err)
 		}
-		// Small delay between messages
-		time.Sleep(150 * time.Millisecond)
 	}
-
-	logger.Info("Completed sendTasksGroupedByProjectAsync")
 }
 
-// buildTaskMessage builds the formatted message text for a task
-func buildTaskMessage(task TaskInfo) string {
-	taskText := fmt.Sprintf("*%s*", task.Name)
+// ProjectFilter holds the exact project names a command includes and excludes
+type ProjectFilter struct {
+	Include []string // empty means all projects
+	Exclude []string
+}
 
-	// Add time spent on the task
-	taskText += fmt.Sprintf("\nTime spent: %s | Total time: %s", task.CurrentTime, task.TotalDuration)
+/* Resolves the project and group names of a command to exact project names
+ * No project names means all projects
+ * If a name matches no project or group, or the lookup fails, returns an error
+ */
+func confirmProjects(command *OYECommand) (ProjectFilter, error) {
+	var filter ProjectFilter
+	if len(command.Projects) == 0 && len(command.ExcludedProjects) == 0 {
+		return filter, nil
+	}
 
-	// Add estimation info if available
-	if task.EstimationInfo.Text != "" {
-		taskText += fmt.Sprintf(" | %s", task.EstimationInfo.Text)
+	db, err := GetDB()
+	if err != nil {
+		return filter, fmt.Errorf("failed to get database: %v", err)
 	}
 
-	// Add comments as unordered list
-	if len(task.Comments) > 0 {
-		taskText += "\n"
-		for _, comment := range task.Comments {
-			if comment != "" {
-				// Limit comment length to avoid overwhelming
-				if len(comment) > 100 {
-					comment = comment[:97] + "..."
-				}
-				taskText += fmt.Sprintf("• %s\n", comment)
-			}
-		}
+	if filter.Include, err = resolveProjectNames(db, command.Projects); err != nil {
+		return filter, err
+	}
+	if filter.Exclude, err = resolveProjectNames(db, command.ExcludedProjects); err != nil {
+		return filter, err
 	}
 
-	return taskText
+	return filter, nil
 }
 
-// createTaskBlocks processes tasks and returns message blocks with chunking logic
-func createTaskBlocks(projectTasks []TaskInfo) [][]map[string]interface{} {
+/* Gets the period from the command text, e.g. "for last week"
+ * If the command does not parse or has no period, returns an error
+ * Otherwise returns the period's start and end times and nil
+ */
+func confirmPeriod(commandText string) (time.Time, time.Time, error) {
 	logger := GetGlobalLogger()
-	var allChunks [][]map[string]interface{}
-	var currentChunk []map[string]interface{}
-	currentBlockCount := 0
-	currentCharCount := 0
-
-	for _, task := range projectTasks {
-		logger.Infof("Processing task %d (%s) with %d comments", task.TaskID, task.Name, len(task.Comments))
-
-		taskText := buildTaskMessage(task)
-
-		// Create task block
-		taskBlock := map[string]interface{}{
-			"type": "section",
-			"text": map[string]interface{}{
-				"type": "mrkdwn",
-				"text": taskText,
-			},
-		}
 
-		// Estimate character count for this block
-		blockBytes, _ := json.Marshal(taskBlock)
-		blockCharCount := len(blockBytes)
-
-		// Check if adding this block would exceed limits
-		if currentBlockCount+1 > MAX_SLACK_BLOCKS || currentCharCount+blockCharCount > MAX_MESSAGE_CHARS_BUFFER {
-			// Save current chunk if we have any blocks
-			if len(currentChunk) > 0 {
-				allChunks = append(allChunks, currentChunk)
-				// Reset for next chunk
-				currentChunk = []map[string]interface{}{}
-				currentBlockCount = 0
-				currentCharCount = 0
-			}
-		}
-
-		// Add block to current batch
-		currentChunk = append(currentChunk, taskBlock)
-		currentBlockCount++
-		currentCharCount += blockCharCount
-	}
-
-	// Add remaining blocks if any
-	if len(currentChunk) > 0 {
-		allChunks = append(allChunks, currentChunk)
+	command, err := ParseOYECommand(commandText)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
 	}
 
-	return allChunks
+	logger.Infof("Period '%s' resolved to: %s to %s", command.Period, command.Start.Format("2006-01-02 15:04:05"), command.End.Format("2006-01-02 15:04:05"))
+	return command.Start, command.End, nil
 }
 
-// createProjectHeaderBlock creates a project header block
-func createProjectHeaderBlock(projectName string) map[string]interface{} {
-	return map[string]interface{}{
-		"type": "section",
-		"text": map[string]interface{}{
-			"type": "mrkdwn",
-			"text": fmt.Sprintf("%s *%s*", EMOJI_FOLDER, projectName),
-		},
+// reportTitle builds the report header title for a command, e.g. "📊 Update for last week (Oct 5 – Oct 11)"
+func reportTitle(command *OYECommand) string {
+	dates := command.Start.Format("Jan 2")
+	if command.End.Format("2006-01-02") != command.Start.Format("2006-01-02") {
+		dates += " – " + command.End.Format("Jan 2")
 	}
+	period := command.Period
+	// Ranges already read naturally: "since monday", "from 2026-09-01 to 2026-09-15"
+	if !strings.HasPrefix(period, "since ") && !strings.HasPrefix(period, "from ") {
+		period = "for " + period
+	}
+	return fmt.Sprintf("%s Update %s (%s)", EMOJI_CHART, period, dates)
 }
 
-// combineProjectsIntoMessages packs multiple projects into as few messages as possible
-func combineProjectsIntoMessages(projectGroups map[string][]TaskInfo) [][]map[string]interface{} {
+// sendTasksGroupedByProjectAsync posts a command's report and returns the channel and message
+// its thread hangs off, empty when nothing was posted or it was sent privately
+func sendTasksGroupedByProjectAsync(req *SlackCommandRequest, report Report, delivery string) (string, string) {
 	logger := GetGlobalLogger()
-	var allMessages [][]map[string]interface{}
-	var currentMessage []map[string]interface{}
-	currentBlockCount := 0
-	currentCharCount := 0
-
-	for projectName, projectTasks := range projectGroups {
-		// Create project header
-		projectHeader := createProjectHeaderBlock(projectName)
-
-		// Create task blocks for this project
-		taskChunks := createTaskBlocks(projectTasks)
-
-		// Calculate size of this project (header + all task blocks)
-		projectBlocks := []map[string]interface{}{projectHeader}
-		for _, chunk := range taskChunks {
-			projectBlocks = append(projectBlocks, chunk...)
-		}
+	logger.Infof("Starting sendTasksGroupedByProjectAsync with %d projects (%s)", len(report.Projects), delivery)
 
-		// Calculate total character count for this project
-		projectBytes, _ := json.Marshal(projectBlocks)
-		projectCharCount := len(projectBytes)
-		projectBlockCount := len(projectBlocks)
-
-		// Check if this project can fit in the current message
-		if currentBlockCount+projectBlockCount <= MAX_SLACK_BLOCKS &&
-			currentCharCount+projectCharCount <= MAX_MESSAGE_CHARS_BUFFER {
-			// Add this project to current message
-			currentMessage = append(currentMessage, projectBlocks...)
-			currentBlockCount += projectBlockCount
-			currentCharCount += projectCharCount
-			logger.Infof("Added project '%s' to current message (blocks: %d, chars: %d)",
-				projectName, projectBlockCount, projectCharCount)
-		} else {
-			// Current message is full, save it and start new one
-			if len(currentMessage) > 0 {
-				allMessages = append(allMessages, currentMessage)
-				logger.Infof("Completed message with %d blocks, %d chars", currentBlockCount, currentCharCount)
-			}
-
-			// Check if this project alone exceeds limits (needs splitting)
-			if projectBlockCount > MAX_SLACK_BLOCKS || projectCharCount > MAX_MESSAGE_CHARS_BUFFER {
-				logger.Infof("Project '%s' is too large, splitting into multiple messages", projectName)
-				// Add header to first chunk
-				if len(taskChunks) > 0 {
-					firstChunk := append([]map[string]interface{}{projectHeader}, taskChunks[0]...)
-					allMessages = append(allMessages, firstChunk)
-
-					// Add remaining chunks as separate messages
-					for i := 1; i < len(taskChunks); i++ {
-						allMessages = append(allMessages, taskChunks[i])
-					}
-				} else {
-					// Just the header
-					allMessages = append(allMessages, []map[string]interface{}{projectHeader})
-				}
-			} else {
-				// Start new message with this project
-				currentMessage = projectBlocks
-				currentBlockCount = projectBlockCount
-				currentCharCount = projectCharCount
-				logger.Infof("Started new message with project '%s' (blocks: %d, chars: %d)",
-					projectName, projectBlockCount, projectCharCount)
-			}
-		}
+	if report.IsEmpty() {
+		logger.Info("No tasks to send in async processing, returning early")
+		return "", ""
 	}
 
-	// Add final message if it has content
-	if len(currentMessage) > 0 {
-		allMessages = append(allMessages, currentMessage)
-		logger.Infof("Completed final message with %d blocks, %d chars", currentBlockCount, currentCharCount)
+	notifier := NewSlackCommandNotifier(delivery, req)
+	if err := notifier.Notify(req.ChannelID, report); err != nil {
+		logger.Errorf("Failed to send threaded update to channel %s: %v", req.ChannelID, err)
+		return notifier.PostedChannel, notifier.PostedTS
 	}
 
-	logger.Infof("Combined %d projects into %d messages", len(projectGroups), len(allMessages))
-	return allMessages
+	logger.Info("Completed sendTasksGroupedByProjectAsync")
+	return notifier.PostedChannel, notifier.PostedTS
 }
 
-// sendSlackMessage sends messages via Slack API (for follow-up messages) with rate limiting retry
-func sendSlackMessage(channel string, blocks []map[string]interface{}, threadTs string) error {
-	logger := GetGlobalLogger()
-	slackBotToken := os.Getenv("SLACK_BOT_TOKEN")
-	if slackBotToken == "" {
-		return fmt.Errorf("SLACK_BOT_TOKEN not configured")
-	}
-
-	payload := map[string]interface{}{
-		"channel": channel,
-		"blocks":  blocks,
-	}
-
-	if threadTs != "" {
-		payload["thread_ts"] = threadTs
-	}
-
-	payloadBytes, err := json.Marshal(payload)
+// sendSlackMessage queues a message in the Slack outbox, which posts it via chat.postMessage
+// in order with the other messages of its channel or thread
+func sendSlackMessage(channel string, blocks []Block, threadTs string) error {
+	db, err := GetDB()
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %v", err)
-	}
-
-	client := &http.Client{}
-
-	requestFunc := func() (*http.Response, error) {
-		req, _ := http.NewRequest("POST", "https://slack.com/api/chat.postMessage", strings.NewReader(string(payloadBytes)))
-		req.Header.Set("Authorization", "Bearer "+slackBotToken)
-		req.Header.Set("Content-Type", "application/json")
-		return client.Do(req)
+		return fmt.Errorf("failed to get database connection: %w", err)
 	}
 
-	resp, err := executeWithRetry(requestFunc, "send slack message")
+	id, err := EnqueueSlackMessage(db, SlackMessage{Channel: channel, ThreadTS: threadTs, Blocks: blocks})
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("response status: %d", resp.StatusCode)
-	}
-
-	logger.Info("Message sent successfully via Slack API")
+	GetGlobalLogger().Infof("Queued Slack message %d for %s", id, channel)
 	return nil
 }
 
@@ -655,132 +413,78 @@ func getThresholdValues() (float64, float64) {
 	return midPoint, highPoint
 }
 
-// executeWithRetry performs HTTP requests with rate limiting retry logic
-func executeWithRetry(requestFunc func() (*http.Response, error), operation string) (*http.Response, error) {
-	logger := GetGlobalLogger()
-	maxRetries := 5
-
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		resp, err := requestFunc()
-		if err != nil {
-			if attempt == maxRetries {
-				return nil, fmt.Errorf("failed %s after %d attempts: %v", operation, maxRetries+1, err)
-			}
-			time.Sleep(time.Second)
-			continue
-		}
-
-		if resp.StatusCode == 429 {
-			// Rate limited, honor Retry-After header and retry
-			if attempt == maxRetries {
-				return resp, fmt.Errorf("rate limited after %d attempts", maxRetries+1)
-			}
-
-			retryAfter := resp.Header.Get("Retry-After")
-			waitDuration := time.Second
-			if retryAfter != "" {
-				if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
-					waitDuration = time.Duration(seconds) * time.Second
-				} else if retryTime, err := http.ParseTime(retryAfter); err == nil {
-					now := time.Now()
-					if retryTime.After(now) {
-						waitDuration = retryTime.Sub(now)
-					}
-				}
-			}
-
-			logger.Warnf("Rate limited (429) during %s, waiting %v before retry %d/%d", operation, waitDuration, attempt+1, maxRetries)
-			resp.Body.Close()
-			time.Sleep(waitDuration)
-			continue
-		}
-
-		return resp, nil
-	}
-
-	return nil, fmt.Errorf("unexpected error in retry loop")
-}
-
 // Removed: getLatestMessageTimestamp - replaced by posting the thread anchor and using returned ts
 
-// sendTasksGroupedByProjectToUser sends personalized task updates to a specific user via direct message
-func sendTasksGroupedByProjectToUser(userID string, projectGroups map[string][]TaskInfo) {
-	logger := GetGlobalLogger()
-	logger.Infof("Starting sendTasksGroupedByProjectToUser for user %s with %d project groups", userID, len(projectGroups))
-
-	if len(projectGroups) == 0 {
-		logger.Infof("No tasks to send to user %s, returning early", userID)
-		return
+// sendReportToUser sends a prepared report to a user via direct message
+func sendReportToUser(userID string, report Report) {
+	if err := NewSlackNotifier().Notify(userID, report); err != nil {
+		GetGlobalLogger().Errorf("Failed to send direct message update to user %s: %v", userID, err)
 	}
+}
 
-	logger.Infof("Sending direct message to user %s", userID)
-
-	// Post initial message to create a thread anchor (same UX as slash command flow)
-	slackClient := NewSlackAPIClient()
-	initResp, initErr := slackClient.sendSlackAPIRequestWithResponse("chat.postMessage", map[string]interface{}{
-		"channel": userID,
-		"text":    "\U0001F4CA Update in thread",
-	})
-	if initErr != nil {
-		logger.Errorf("Failed to post initial thread message to user %s: %v", userID, initErr)
-		return
-	}
-	threadTs := initResp.Timestamp
-
-	// Get threshold values from environment variables
-	midPoint, highPoint := getThresholdValues()
-	logger.Infof("Using thresholds - MID_POINT: %.1f, HIGH_POINT: %.1f", midPoint, highPoint)
-
-	// Combine all projects into optimized messages
-	combinedMessages := combineProjectsIntoMessages(projectGroups)
-
-	// Send all combined messages as direct messages
-	for i, messageBlocks := range combinedMessages {
-		logger.Infof("Sending combined message %d/%d to user %s with %d blocks", i+1, len(combinedMessages), userID, len(messageBlocks))
-		if err := sendSlackMessage(userID, messageBlocks, threadTs); err != nil {
-			logger.Errorf("Failed to send combined message %d to user %s: %v", i+1, userID, err)
-			continue
-		}
-		// Small delay between messages
-		time.Sleep(150 * time.Millisecond)
+// sendDigestToUser sends a recurring digest to a user via direct message, updating the earlier one for the same period
+func sendDigestToUser(userID, digest string, report Report) error {
+	notifier := &SlackNotifier{Mode: SLACK_DELIVERY_THREAD, Digest: digest}
+	if err := notifier.Notify(userID, report); err != nil {
+		return fmt.Errorf("failed to send %s digest to user %s: %w", digest, userID, err)
 	}
-
-	logger.Infof("Completed sendTasksGroupedByProjectToUser for user %s", userID)
+	return nil
 }
 
 /* Displays help text for the OYE command */
 func sendUnifiedHelp(responseWriter http.ResponseWriter) {
 	helpText := "*🎯 OYE (Observe-Yor-Estimates) Commands*\n\n" +
 		"*Time Frame Options:*\n" +
+		"• `/oye` - Open the report builder\n" +
 		"• `/oye for [period]` - Update for specific time frame\n" +
 		"• `/oye project [project name] for [period]` - Update for specific project and time frame\n" +
 		"• `/oye over [percentage] for [period]` - Check for tasks over threshold\n" +
 		"• `/oye project [project name] over [percentage] for [period]` - Check for tasks over threshold for a specific project\n" +
+		"• `/oye project [name], [name] for [period]` - Update for several projects or project groups\n" +
+		"• `/oye all except [name] for [period]` - Update for all projects except some\n" +
+		"• `/oye for [period] sort by time|percent|name` - Order projects and tasks\n" +
+		"• `/oye for [period] top 10` - Only the 10 biggest tasks\n" +
+		"• `/oye for [period] summary` - One line per project: total time, task count, tasks over threshold and worst task\n" +
+		"• `/oye for [period] export csv|xlsx` - A spreadsheet with one row per task, add `private` to get it as a direct message\n" +
+		"• `/oye project [name] for [period] chart` - Add burn-up charts of each project and its fullest tasks against their estimates\n" +
+
+		"*Scheduled Reports:*\n" +
+		"• `/oye subscribe weekly on monday 9:00 project ACME to #acme-team` - Post a report on a schedule\n" +
+		"• Schedules: `daily`, `weekdays`, `weekly on [weekday]`, `monthly on [day]`, optionally `at [HH:MM]`\n" +
+		"• Targets: `to #channel`, `to me` or `to here` (default)\n" +
+		"• `/oye subscriptions` - List your and this channel's subscriptions\n" +
+		"• `/oye unsubscribe [id]` - Remove a subscription you created\n" +
+
+		"*Web Dashboard:*\n" +
+		"• `/oye dashboard` - Get a sign-in link for the web dashboard by direct message\n" +
+
+		"*Asking the Bot:*\n" +
+		"• Mention the bot or send it a direct message, it answers in the thread\n" +
+		"• `status of [project]`, `what's over budget`, `my time this week` or any report above without `/oye`\n" +
 
 		"*Available Periods:*\n" +
-		"• today\n" +
-		"• yesterday\n" +
-		"• last week\n" +
-		"• this week\n" +
-		"• last month\n" +
-		"• this month\n" +
+		"• today / yesterday\n" +
+		"• this week / last week\n" +
+		"• this month / last month\n" +
+		"• this quarter / last quarter\n" +
+		"• this year / last year\n" +
 		"• last x days\n" +
+		"• week 38 (ISO week, optionally with a year: week 1 2027)\n" +
+		"• since monday / since 2026-09-01\n" +
+		"• from 2026-09-01 to 2026-09-15\n" +
 
 		"*Tips:*\n" +
-		"• Updates are private by default (only you see them)\n" +
+		"• Updates are posted as a summary with the details in its thread\n" +
+		"• Add `private` to see an update yourself only, or `share` to post it straight into the channel\n" +
 		"• Project names with spaces are fine without quotes\n" +
+		"• Quote names that contain keywords: `/oye project \"Over the Top\" for this week`\n" +
 		"• Project names support fuzzy matching\n" +
-		"• Custom ranges: `/oye last 14 days` (1-60 days supported)\n" +
+		"• Saved project groups (managed in the OYE app home) work anywhere a project name does\n" +
+		"• Ranges work without `for`: `/oye project ACME since monday`\n" +
 		"• When you assign projects, automatic updates show only your projects\n" +
 		"• Click the OYE app in sidebar to see your project settings page"
 
-	response := SlackCommandResponse{
-		ResponseType: "ephemeral",
-		Text:         helpText,
-	}
-
-	responseWriter.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(responseWriter).Encode(response)
+	sendImmediateResponse(responseWriter, helpText, "ephemeral")
 }
 
 /* Parses the form data from a Slack slash command */
@@ -830,6 +534,10 @@ func sendImmediateResponse(w http.ResponseWriter, message string, responseType s
 		ResponseType: responseType,
 		Text:         message,
 	}
+	if err := response.Validate(); err != nil {
+		GetGlobalLogger().Errorf("Invalid slash command response: %v", err)
+		response.Text = "Sorry, the response was too large to show."
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
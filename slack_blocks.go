@@ -0,0 +1,715 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Typed Block Kit structures and builders. Every Slack message, view and
+// response is built from these types, and the payload types validate the
+// blocks against Slack's limits before anything is sent, so an oversized
+// report fails with the offending block instead of Slack's "invalid_blocks".
+//
+// Reference: https://api.slack.com/reference/block-kit
+
+// Text is a Block Kit text object
+type Text struct {
+	Type     string `json:"type"` // plain_text or mrkdwn
+	Text     string `json:"text"`
+	Emoji    bool   `json:"emoji,omitempty"`
+	Verbatim bool   `json:"verbatim,omitempty"`
+}
+
+// Option is an option of a select menu, overflow menu, checkbox or radio group
+type Option struct {
+	Text        *Text  `json:"text"`
+	Value       string `json:"value"`
+	Description *Text  `json:"description,omitempty"`
+}
+
+// OptionGroup is a labelled group of select menu options
+type OptionGroup struct {
+	Label   *Text    `json:"label"`
+	Options []Option `json:"options"`
+}
+
+// ConversationFilter limits the conversations offered by a conversations_select
+type ConversationFilter struct {
+	Include         []string `json:"include,omitempty"`
+	ExcludeBotUsers bool     `json:"exclude_bot_users,omitempty"`
+}
+
+// DispatchActionConfig sets when a plain_text_input sends a block action
+type DispatchActionConfig struct {
+	TriggerActionsOn []string `json:"trigger_actions_on"`
+}
+
+// Element is an interactive element: a button, menu, picker or input.
+// Only the fields of its type are set.
+type Element struct {
+	Type                         string                `json:"type"`
+	ActionID                     string                `json:"action_id,omitempty"`
+	Text                         *Text                 `json:"text,omitempty"`
+	Value                        string                `json:"value,omitempty"`
+	Style                        string                `json:"style,omitempty"` // primary or danger
+	URL                          string                `json:"url,omitempty"`
+	Placeholder                  *Text                 `json:"placeholder,omitempty"`
+	Options                      []Option              `json:"options,omitempty"`
+	OptionGroups                 []OptionGroup         `json:"option_groups,omitempty"`
+	InitialOption                *Option               `json:"initial_option,omitempty"`
+	InitialOptions               []Option              `json:"initial_options,omitempty"`
+	InitialValue                 string                `json:"initial_value,omitempty"`
+	InitialDate                  string                `json:"initial_date,omitempty"`
+	InitialTime                  string                `json:"initial_time,omitempty"`
+	InitialUser                  string                `json:"initial_user,omitempty"`
+	InitialConversation          string                `json:"initial_conversation,omitempty"`
+	DefaultToCurrentConversation bool                  `json:"default_to_current_conversation,omitempty"`
+	ResponseURLEnabled           bool                  `json:"response_url_enabled,omitempty"`
+	Filter                       *ConversationFilter   `json:"filter,omitempty"`
+	MinQueryLength               *int                  `json:"min_query_length,omitempty"`
+	Multiline                    bool                  `json:"multiline,omitempty"`
+	DispatchActionConfig         *DispatchActionConfig `json:"dispatch_action_config,omitempty"`
+}
+
+// RichTextStyle is the style of a rich text element: the list style of a
+// rich_text_list, or the text style flags of a text element
+type RichTextStyle struct {
+	List   string // bullet or ordered, for rich_text_list
+	Bold   bool
+	Italic bool
+	Strike bool
+	Code   bool
+}
+
+type richTextStyleFlags struct {
+	Bold   bool `json:"bold,omitempty"`
+	Italic bool `json:"italic,omitempty"`
+	Strike bool `json:"strike,omitempty"`
+	Code   bool `json:"code,omitempty"`
+}
+
+func (s RichTextStyle) MarshalJSON() ([]byte, error) {
+	if s.List != "" {
+		return json.Marshal(s.List)
+	}
+	return json.Marshal(richTextStyleFlags{Bold: s.Bold, Italic: s.Italic, Strike: s.Strike, Code: s.Code})
+}
+
+func (s *RichTextStyle) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &s.List)
+	}
+	var flags richTextStyleFlags
+	if err := json.Unmarshal(data, &flags); err != nil {
+		return err
+	}
+	*s = RichTextStyle{Bold: flags.Bold, Italic: flags.Italic, Strike: flags.Strike, Code: flags.Code}
+	return nil
+}
+
+// RichTextElement is a part of a rich_text block: a section, list, quote or
+// preformatted container, or a text, link, user or emoji leaf inside one
+type RichTextElement struct {
+	Type     string            `json:"type"`
+	Elements []RichTextElement `json:"elements,omitempty"`
+	Style    *RichTextStyle    `json:"style,omitempty"`
+	Text     string            `json:"text,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	UserID   string            `json:"user_id,omitempty"`
+	Name     string            `json:"name,omitempty"` // emoji name
+}
+
+// BlockElement is an entry of a block's elements: a *Text in context blocks,
+// an *Element in actions blocks and a RichTextElement in rich_text blocks
+type BlockElement interface {
+	blockElement()
+}
+
+func (*Text) blockElement()           {}
+func (*Element) blockElement()        {}
+func (RichTextElement) blockElement() {}
+
+// Block is a Block Kit layout block. Only the fields of its type are set.
+type Block struct {
+	Type           string         `json:"type"`
+	BlockID        string         `json:"block_id,omitempty"`
+	Text           *Text          `json:"text,omitempty"`
+	Fields         []*Text        `json:"fields,omitempty"`
+	Accessory      *Element       `json:"accessory,omitempty"`
+	Elements       []BlockElement `json:"elements,omitempty"`
+	Label          *Text          `json:"label,omitempty"`
+	Element        *Element       `json:"element,omitempty"`
+	Hint           *Text          `json:"hint,omitempty"`
+	Optional       bool           `json:"optional,omitempty"`
+	DispatchAction bool           `json:"dispatch_action,omitempty"`
+}
+
+// UnmarshalJSON decodes a block, picking the element type of each entry in
+// elements by its type, so blocks read back from Slack payloads stay typed
+func (b *Block) UnmarshalJSON(data []byte) error {
+	type plainBlock Block
+	var decoded struct {
+		plainBlock
+		Elements []json.RawMessage `json:"elements,omitempty"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*b = Block(decoded.plainBlock)
+	b.Elements = nil
+	for _, raw := range decoded.Elements {
+		var kind struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &kind); err != nil {
+			return err
+		}
+
+		var element BlockElement
+		switch {
+		case kind.Type == "mrkdwn" || kind.Type == "plain_text":
+			element = &Text{}
+		case strings.HasPrefix(kind.Type, "rich_text_"):
+			element = &RichTextElement{}
+		default:
+			element = &Element{}
+		}
+		if err := json.Unmarshal(raw, element); err != nil {
+			return fmt.Errorf("failed to decode %s element: %w", kind.Type, err)
+		}
+		b.Elements = append(b.Elements, element)
+	}
+	return nil
+}
+
+// Text objects
+
+// PlainText creates a plain_text object
+func PlainText(text string) *Text {
+	return &Text{Type: "plain_text", Text: text}
+}
+
+// MrkdwnText creates a mrkdwn text object
+func MrkdwnText(text string) *Text {
+	return &Text{Type: "mrkdwn", Text: text}
+}
+
+// Layout blocks
+
+// HeaderBlock creates a header block, which takes plain text only
+func HeaderBlock(text string) Block {
+	return Block{Type: "header", Text: PlainText(text)}
+}
+
+// SectionBlock creates a section with mrkdwn text
+func SectionBlock(text string) Block {
+	return Block{Type: "section", Text: MrkdwnText(text)}
+}
+
+// FieldsBlock creates a section laid out as two columns of mrkdwn fields, under optional text
+func FieldsBlock(text string, fields ...string) Block {
+	block := Block{Type: "section"}
+	if text != "" {
+		block.Text = MrkdwnText(text)
+	}
+	for _, field := range fields {
+		block.Fields = append(block.Fields, MrkdwnText(field))
+	}
+	return block
+}
+
+// FieldTableBlocks lays out label and value rows as a two column table, starting
+// a new section every MAX_SECTION_FIELDS/2 rows. The title heads the first section.
+func FieldTableBlocks(title string, rows [][2]string) []Block {
+	rowsPerSection := MAX_SECTION_FIELDS / 2
+	var blocks []Block
+	for start := 0; start < len(rows) || (start == 0 && title != ""); start += rowsPerSection {
+		var fields []string
+		for _, row := range rows[start:min(start+rowsPerSection, len(rows))] {
+			fields = append(fields, row[0], row[1])
+		}
+		block := FieldsBlock("", fields...)
+		if start == 0 && title != "" {
+			block.Text = MrkdwnText(title)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// ContextBlock creates a context block of mrkdwn texts
+func ContextBlock(texts ...string) Block {
+	block := Block{Type: "context"}
+	for _, text := range texts {
+		block.Elements = append(block.Elements, MrkdwnText(text))
+	}
+	return block
+}
+
+// DividerBlock creates a divider
+func DividerBlock() Block {
+	return Block{Type: "divider"}
+}
+
+// ActionsBlock creates an actions block of interactive elements
+func ActionsBlock(elements ...*Element) Block {
+	block := Block{Type: "actions"}
+	for _, element := range elements {
+		block.Elements = append(block.Elements, element)
+	}
+	return block
+}
+
+// InputBlock creates a modal input with a label
+func InputBlock(blockID, label string, element *Element) Block {
+	return Block{Type: "input", BlockID: blockID, Label: PlainText(label), Element: element}
+}
+
+// RichTextBlock creates a rich_text block from sections and lists
+func RichTextBlock(elements ...RichTextElement) Block {
+	block := Block{Type: "rich_text"}
+	for _, element := range elements {
+		block.Elements = append(block.Elements, element)
+	}
+	return block
+}
+
+// WithBlockID returns the block with its block_id set
+func (b Block) WithBlockID(blockID string) Block {
+	b.BlockID = blockID
+	return b
+}
+
+// WithAccessory returns the section with an element beside its text
+func (b Block) WithAccessory(element *Element) Block {
+	b.Accessory = element
+	return b
+}
+
+// WithHint returns the input with a hint below it
+func (b Block) WithHint(hint string) Block {
+	b.Hint = PlainText(hint)
+	return b
+}
+
+// AsOptional returns the input marked as optional
+func (b Block) AsOptional() Block {
+	b.Optional = true
+	return b
+}
+
+// Rich text
+
+// RichTextSection creates a paragraph of rich text leaves
+func RichTextSection(elements ...RichTextElement) RichTextElement {
+	return RichTextElement{Type: "rich_text_section", Elements: elements}
+}
+
+// RichTextList creates a bullet or ordered list with one section per item
+func RichTextList(style string, items ...RichTextElement) RichTextElement {
+	return RichTextElement{Type: "rich_text_list", Style: &RichTextStyle{List: style}, Elements: items}
+}
+
+// RichTextPlain creates a text leaf
+func RichTextPlain(text string) RichTextElement {
+	return RichTextElement{Type: "text", Text: text}
+}
+
+// RichTextBold creates a bold text leaf
+func RichTextBold(text string) RichTextElement {
+	return RichTextElement{Type: "text", Text: text, Style: &RichTextStyle{Bold: true}}
+}
+
+// RichTextLink creates a link leaf, showing the URL when text is empty
+func RichTextLink(url, text string) RichTextElement {
+	return RichTextElement{Type: "link", URL: url, Text: text}
+}
+
+// RichTextUser creates a user mention leaf
+func RichTextUser(userID string) RichTextElement {
+	return RichTextElement{Type: "user", UserID: userID}
+}
+
+// Interactive elements
+
+// NewButton creates a button
+func NewButton(actionID, text, value string) *Element {
+	return &Element{Type: "button", ActionID: actionID, Text: PlainText(text), Value: value}
+}
+
+// NewSelect creates a menu of the given select type with a placeholder
+func NewSelect(selectType, actionID, placeholder string, options ...Option) *Element {
+	element := &Element{Type: selectType, ActionID: actionID, Options: options}
+	if placeholder != "" {
+		element.Placeholder = PlainText(placeholder)
+	}
+	return element
+}
+
+// NewOverflow creates an overflow menu
+func NewOverflow(actionID string, options ...Option) *Element {
+	return &Element{Type: "overflow", ActionID: actionID, Options: options}
+}
+
+// NewPlainTextInput creates a text input with an optional placeholder
+func NewPlainTextInput(actionID, placeholder string) *Element {
+	element := &Element{Type: "plain_text_input", ActionID: actionID}
+	if placeholder != "" {
+		element.Placeholder = PlainText(placeholder)
+	}
+	return element
+}
+
+// NewPicker creates an element without options, e.g. a datepicker or timepicker
+func NewPicker(pickerType, actionID string) *Element {
+	return &Element{Type: pickerType, ActionID: actionID}
+}
+
+// NewOption creates an option whose text is plain text
+func NewOption(text, value string) Option {
+	return Option{Text: PlainText(text), Value: value}
+}
+
+// NewOptionGroup creates a labelled group of options
+func NewOptionGroup(label string, options ...Option) OptionGroup {
+	return OptionGroup{Label: PlainText(label), Options: options}
+}
+
+// Validation
+
+// textLength counts characters the way Slack's limits do
+func textLength(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// validateText checks a text object's type and length
+func validateText(name string, text *Text, maxChars int, plainOnly bool) error {
+	if text == nil {
+		return fmt.Errorf("%s is missing", name)
+	}
+	switch text.Type {
+	case "plain_text":
+	case "mrkdwn":
+		if plainOnly {
+			return fmt.Errorf("%s must be plain_text", name)
+		}
+	default:
+		return fmt.Errorf("%s has unknown text type '%s'", name, text.Type)
+	}
+	if text.Text == "" {
+		return fmt.Errorf("%s is empty", name)
+	}
+	if n := textLength(text.Text); n > maxChars {
+		return fmt.Errorf("%s has %d characters, the limit is %d", name, n, maxChars)
+	}
+	return nil
+}
+
+// validateOptions checks options against the option text and value limits
+func validateOptions(options []Option) error {
+	if len(options) > MAX_STATIC_SELECT_OPTIONS {
+		return fmt.Errorf("%d options, the limit is %d", len(options), MAX_STATIC_SELECT_OPTIONS)
+	}
+	for i, option := range options {
+		if err := validateText(fmt.Sprintf("option %d text", i), option.Text, MAX_OPTION_TEXT_CHARS, false); err != nil {
+			return err
+		}
+		if n := textLength(option.Value); n > MAX_OPTION_VALUE_CHARS {
+			return fmt.Errorf("option %d value has %d characters, the limit is %d", i, n, MAX_OPTION_VALUE_CHARS)
+		}
+	}
+	return nil
+}
+
+// Validate checks an interactive element against Slack's limits
+func (e *Element) Validate() error {
+	if e.Type == "" {
+		return fmt.Errorf("element has no type")
+	}
+	if n := textLength(e.ActionID); n > MAX_BLOCK_ID_CHARS {
+		return fmt.Errorf("%s action_id has %d characters, the limit is %d", e.Type, n, MAX_BLOCK_ID_CHARS)
+	}
+	if e.Type == "button" {
+		if err := validateText("button text", e.Text, MAX_BUTTON_TEXT_CHARS, true); err != nil {
+			return err
+		}
+		if n := textLength(e.Value); n > MAX_BUTTON_VALUE_CHARS {
+			return fmt.Errorf("button value has %d characters, the limit is %d", n, MAX_BUTTON_VALUE_CHARS)
+		}
+	}
+	if e.Placeholder != nil {
+		if err := validateText(e.Type+" placeholder", e.Placeholder, MAX_PLACEHOLDER_CHARS, true); err != nil {
+			return err
+		}
+	}
+	if err := validateOptions(e.Options); err != nil {
+		return fmt.Errorf("%s: %w", e.Type, err)
+	}
+	if len(e.OptionGroups) > MAX_OPTION_GROUPS {
+		return fmt.Errorf("%s has %d option groups, the limit is %d", e.Type, len(e.OptionGroups), MAX_OPTION_GROUPS)
+	}
+	for i, group := range e.OptionGroups {
+		if err := validateText(fmt.Sprintf("option group %d label", i), group.Label, MAX_OPTION_TEXT_CHARS, true); err != nil {
+			return fmt.Errorf("%s: %w", e.Type, err)
+		}
+		if err := validateOptions(group.Options); err != nil {
+			return fmt.Errorf("%s option group %d: %w", e.Type, i, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks a block against Slack's per-block limits
+func (b Block) Validate() error {
+	if n := textLength(b.BlockID); n > MAX_BLOCK_ID_CHARS {
+		return fmt.Errorf("block_id has %d characters, the limit is %d", n, MAX_BLOCK_ID_CHARS)
+	}
+
+	switch b.Type {
+	case "header":
+		return validateText("header text", b.Text, MAX_HEADER_TEXT_CHARS, true)
+
+	case "section":
+		if b.Text == nil && len(b.Fields) == 0 {
+			return fmt.Errorf("section has neither text nor fields")
+		}
+		if b.Text != nil {
+			if err := validateText("section text", b.Text, MAX_SECTION_TEXT_CHARS, false); err != nil {
+				return err
+			}
+		}
+		if len(b.Fields) > MAX_SECTION_FIELDS {
+			return fmt.Errorf("section has %d fields, the limit is %d", len(b.Fields), MAX_SECTION_FIELDS)
+		}
+		for i, field := range b.Fields {
+			if err := validateText(fmt.Sprintf("section field %d", i), field, MAX_FIELD_TEXT_CHARS, false); err != nil {
+				return err
+			}
+		}
+		if b.Accessory != nil {
+			return b.Accessory.Validate()
+		}
+		return nil
+
+	case "context":
+		if len(b.Elements) == 0 || len(b.Elements) > MAX_CONTEXT_ELEMENTS {
+			return fmt.Errorf("context has %d elements, it takes 1 to %d", len(b.Elements), MAX_CONTEXT_ELEMENTS)
+		}
+		for i, element := range b.Elements {
+			text, ok := element.(*Text)
+			if !ok {
+				return fmt.Errorf("context element %d is not a text object", i)
+			}
+			if err := validateText(fmt.Sprintf("context element %d", i), text, MAX_SECTION_TEXT_CHARS, false); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "actions":
+		if len(b.Elements) == 0 || len(b.Elements) > MAX_ACTIONS_ELEMENTS {
+			return fmt.Errorf("actions block has %d elements, it takes 1 to %d", len(b.Elements), MAX_ACTIONS_ELEMENTS)
+		}
+		for i, element := range b.Elements {
+			interactive, ok := element.(*Element)
+			if !ok {
+				return fmt.Errorf("actions element %d is not interactive", i)
+			}
+			if err := interactive.Validate(); err != nil {
+				return fmt.Errorf("actions element %d: %w", i, err)
+			}
+		}
+		return nil
+
+	case "input":
+		if err := validateText("input label", b.Label, MAX_INPUT_LABEL_CHARS, true); err != nil {
+			return err
+		}
+		if b.Hint != nil {
+			if err := validateText("input hint", b.Hint, MAX_INPUT_LABEL_CHARS, true); err != nil {
+				return err
+			}
+		}
+		if b.Element == nil {
+			return fmt.Errorf("input has no element")
+		}
+		return b.Element.Validate()
+
+	case "rich_text":
+		if len(b.Elements) == 0 {
+			return fmt.Errorf("rich_text block has no elements")
+		}
+		for i, element := range b.Elements {
+			switch element.(type) {
+			case RichTextElement, *RichTextElement:
+			default:
+				return fmt.Errorf("rich_text element %d is not rich text", i)
+			}
+		}
+		return nil
+
+	case "divider":
+		return nil
+
+	default:
+		return fmt.Errorf("unknown block type '%s'", b.Type)
+	}
+}
+
+// ValidateBlocks checks the number of blocks and each block
+func ValidateBlocks(blocks []Block, maxBlocks int) error {
+	if len(blocks) > maxBlocks {
+		return fmt.Errorf("%d blocks, the limit is %d", len(blocks), maxBlocks)
+	}
+	for i, block := range blocks {
+		if err := block.Validate(); err != nil {
+			return fmt.Errorf("block %d (%s): %w", i, block.Type, err)
+		}
+	}
+	return nil
+}
+
+// TextLength returns the characters of text a block shows, used to pack reports into messages
+func (b Block) TextLength() int {
+	length := 0
+	for _, text := range append([]*Text{b.Text, b.Label, b.Hint}, b.Fields...) {
+		if text != nil {
+			length += textLength(text.Text)
+		}
+	}
+	for _, element := range b.Elements {
+		if text, ok := element.(*Text); ok {
+			length += textLength(text.Text)
+		}
+	}
+	return length
+}
+
+// blocksTextLength sums the text length of blocks
+func blocksTextLength(blocks []Block) int {
+	length := 0
+	for _, block := range blocks {
+		length += block.TextLength()
+	}
+	return length
+}
+
+// Payloads
+
+// SlackMessage is the body of chat.postMessage, chat.postEphemeral and chat.update
+type SlackMessage struct {
+	Channel  string  `json:"channel"`
+	User     string  `json:"user,omitempty"` // recipient of chat.postEphemeral
+	TS       string  `json:"ts,omitempty"`   // message replaced by chat.update
+	ThreadTS string  `json:"thread_ts,omitempty"`
+	Text     string  `json:"text"`
+	Blocks   []Block `json:"blocks,omitempty"`
+}
+
+// Validate checks the message against Slack's message limits
+func (m SlackMessage) Validate() error {
+	return validateMessage(m.Text, m.Blocks)
+}
+
+// Validate checks the response against Slack's message limits
+func (r SlackCommandResponse) Validate() error {
+	return validateMessage(r.Text, r.Blocks)
+}
+
+func validateMessage(text string, blocks []Block) error {
+	if text == "" && len(blocks) == 0 {
+		return fmt.Errorf("message has neither text nor blocks")
+	}
+	if n := textLength(text); n > MAX_MESSAGE_TEXT_CHARS {
+		return fmt.Errorf("message text has %d characters, the limit is %d", n, MAX_MESSAGE_TEXT_CHARS)
+	}
+	return ValidateBlocks(blocks, MAX_SLACK_BLOCKS)
+}
+
+// SlackDeleteRequest is the body of chat.delete
+type SlackDeleteRequest struct {
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+// Validate checks the request names a message
+func (r SlackDeleteRequest) Validate() error {
+	if r.Channel == "" || r.TS == "" {
+		return fmt.Errorf("delete request needs a channel and a message timestamp")
+	}
+	return nil
+}
+
+// View is an App Home tab or modal
+type View struct {
+	Type            string  `json:"type"` // home or modal
+	CallbackID      string  `json:"callback_id,omitempty"`
+	Title           *Text   `json:"title,omitempty"`
+	Submit          *Text   `json:"submit,omitempty"`
+	Close           *Text   `json:"close,omitempty"`
+	PrivateMetadata string  `json:"private_metadata,omitempty"`
+	Blocks          []Block `json:"blocks"`
+}
+
+// NewModal creates a modal with a title, submit and close button
+func NewModal(callbackID, title, submit string, blocks ...Block) View {
+	return View{
+		Type:       "modal",
+		CallbackID: callbackID,
+		Title:      PlainText(title),
+		Submit:     PlainText(submit),
+		Close:      PlainText("Cancel"),
+		Blocks:     blocks,
+	}
+}
+
+// Validate checks the view against Slack's view limits
+func (v View) Validate() error {
+	maxBlocks := MAX_HOME_VIEW_BLOCKS
+	if v.Type == "modal" {
+		maxBlocks = MAX_MODAL_BLOCKS
+		if err := validateText("modal title", v.Title, MAX_VIEW_TITLE_CHARS, true); err != nil {
+			return err
+		}
+		for name, button := range map[string]*Text{"submit": v.Submit, "close": v.Close} {
+			if button == nil {
+				continue
+			}
+			if err := validateText("modal "+name+" button", button, MAX_VIEW_TITLE_CHARS, true); err != nil {
+				return err
+			}
+		}
+	}
+	return ValidateBlocks(v.Blocks, maxBlocks)
+}
+
+// ViewRequest is the body of views.open and views.publish
+type ViewRequest struct {
+	TriggerID string `json:"trigger_id,omitempty"` // views.open
+	UserID    string `json:"user_id,omitempty"`    // views.publish
+	View      View   `json:"view"`
+}
+
+// Validate checks the view of the request
+func (r ViewRequest) Validate() error {
+	return r.View.Validate()
+}
+
+// ViewSubmissionResponse answers a modal submission, e.g. with errors shown next to inputs
+type ViewSubmissionResponse struct {
+	ResponseAction string            `json:"response_action"`
+	Errors         map[string]string `json:"errors,omitempty"` // block ID to message
+}
+
+// OptionsResponse answers a block_suggestion request for an external select
+type OptionsResponse struct {
+	Options []Option `json:"options"`
+}
+
+// Validate checks the options of the response
+func (r OptionsResponse) Validate() error {
+	return validateOptions(r.Options)
+}
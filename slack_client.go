@@ -6,10 +6,115 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"sync"
 	"time"
 )
 
+// SlackAPIError is a Web API call Slack refused or could not serve
+type SlackAPIError struct {
+	Method     string
+	StatusCode int
+	Code       string        // Slack's error string, e.g. channel_not_found
+	RetryAfter time.Duration // set when Slack rate limited the call
+}
+
+func (e *SlackAPIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("slack API error: %s", e.Code)
+	}
+	return fmt.Sprintf("slack API %s returned status %d", e.Method, e.StatusCode)
+}
+
+// transientSlackErrors are error codes worth retrying, everything else Slack reports is permanent
+var transientSlackErrors = map[string]bool{
+	"ratelimited":         true,
+	"internal_error":      true,
+	"fatal_error":         true,
+	"service_unavailable": true,
+	"request_timeout":     true,
+}
+
+// Transient reports whether the same call may succeed later
+func (e *SlackAPIError) Transient() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500 || transientSlackErrors[e.Code]
+}
+
+// slackMethodTiers maps Web API methods to Slack's rate limit tiers, unlisted methods are treated as tier 3.
+// chat.postMessage has its own limit of about one message per second per channel.
+var slackMethodTiers = map[string]int{
+	"chat.update":                  3,
+	"chat.delete":                  3,
+	"chat.postEphemeral":           4,
+	"views.open":                   4,
+	"views.publish":                4,
+	"views.update":                 4,
+	"users.info":                   4,
+	"users.list":                   2,
+	"conversations.open":           3,
+	"files.getUploadURLExternal":   4,
+	"files.completeUploadExternal": 4,
+}
+
+// slackTierCallsPerMinute is the sustained rate Slack allows per tier
+var slackTierCallsPerMinute = map[int]int{1: 1, 2: 20, 3: 50, 4: 100}
+
+// slackRateLimiter spaces Web API calls to stay within Slack's limits
+// and holds back a method entirely while Slack asks us to wait
+type slackRateLimiter struct {
+	mu          sync.Mutex
+	next        map[string]time.Time
+	pausedUntil map[string]time.Time
+}
+
+var slackLimiter = &slackRateLimiter{next: make(map[string]time.Time), pausedUntil: make(map[string]time.Time)}
+
+// Wait blocks until a call to the method may be made
+func (l *slackRateLimiter) Wait(method, channel string) {
+	key, interval := method, time.Minute/time.Duration(slackTierCallsPerMinute[3])
+	if tier, ok := slackMethodTiers[method]; ok {
+		interval = time.Minute / time.Duration(slackTierCallsPerMinute[tier])
+	}
+	if method == "chat.postMessage" {
+		key, interval = method+":"+channel, time.Second
+	}
+
+	l.mu.Lock()
+	slot := time.Now()
+	if l.next[key].After(slot) {
+		slot = l.next[key]
+	}
+	if l.pausedUntil[method].After(slot) {
+		slot = l.pausedUntil[method]
+	}
+	l.next[key] = slot.Add(interval)
+	l.mu.Unlock()
+
+	time.Sleep(time.Until(slot))
+}
+
+// Pause holds back all calls to a method, as asked by a Retry-After header
+func (l *slackRateLimiter) Pause(method string, wait time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(wait); until.After(l.pausedUntil[method]) {
+		l.pausedUntil[method] = until
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as a date, defaulting to a second
+func parseRetryAfter(header string) time.Duration {
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if retryTime, err := http.ParseTime(header); err == nil && retryTime.After(time.Now()) {
+		return time.Until(retryTime)
+	}
+	return time.Second
+}
+
 // NewSlackAPIClient creates a new Slack API client
 func NewSlackAPIClient() *SlackAPIClient {
 	return &SlackAPIClient{
@@ -18,58 +123,210 @@ func NewSlackAPIClient() *SlackAPIClient {
 	}
 }
 
-func (s *SlackAPIClient) sendSlackAPIRequest(endpoint string, payload map[string]interface{}) error {
+// slackPayload is a request body that can check itself against Slack's limits
+type slackPayload interface {
+	Validate() error
+}
+
+func (s *SlackAPIClient) sendSlackAPIRequest(endpoint string, payload slackPayload) error {
 	_, err := s.sendSlackAPIRequestWithResponse(endpoint, payload)
 	return err
 }
 
-func (s *SlackAPIClient) sendSlackAPIRequestWithResponse(endpoint string, payload map[string]interface{}) (*SlackAPIResponse, error) {
-	if s.botToken == "" {
-		s.logger.Warn("SLACK_BOT_TOKEN not configured, cannot send direct API requests")
-		return nil, fmt.Errorf("slack bot token not configured")
+func (s *SlackAPIClient) sendSlackAPIRequestWithResponse(endpoint string, payload slackPayload) (*SlackAPIResponse, error) {
+	if err := payload.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", endpoint, err)
 	}
 
-	url := fmt.Sprintf("https://slack.com/api/%s", endpoint)
-
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("error marshaling payload: %w", err)
 	}
 
-	s.logger.Infof("Sending %s request to %s with payload size: %d bytes", endpoint, url, len(jsonData))
+	channel := ""
+	if message, ok := payload.(SlackMessage); ok {
+		channel = message.Channel
+	}
+	return s.postSlackAPI(endpoint, channel, "application/json", jsonData, nil)
+}
+
+// sendSlackFormRequest calls a Web API method taking form arguments, such as the file upload methods,
+// and decodes the response into result
+func (s *SlackAPIClient) sendSlackFormRequest(endpoint string, form url.Values, result interface{}) error {
+	_, err := s.postSlackAPI(endpoint, form.Get("channel_id"), "application/x-www-form-urlencoded", []byte(form.Encode()), result)
+	return err
+}
+
+// postSlackAPI posts a request body to a Web API method within the rate limits.
+// Result, when not nil, receives the method specific fields of the response
+// and the returned SlackAPIResponse is nil.
+func (s *SlackAPIClient) postSlackAPI(endpoint, channel, contentType string, body []byte, result interface{}) (*SlackAPIResponse, error) {
+	if s.botToken == "" {
+		s.logger.Warn("SLACK_BOT_TOKEN not configured, cannot send direct API requests")
+		return nil, fmt.Errorf("slack bot token not configured")
+	}
+
+	apiURL := fmt.Sprintf("https://slack.com/api/%s", endpoint)
+	slackLimiter.Wait(endpoint, channel)
+
+	s.logger.Infof("Sending %s request to %s with payload size: %d bytes", endpoint, apiURL, len(body))
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.botToken))
 
 	client := &http.Client{Timeout: 30 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
+		slackRequests.Inc(endpoint, "error")
 		return nil, fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	bodyBytes, readErr := io.ReadAll(resp.Body)
 	if readErr != nil {
+		slackRequests.Inc(endpoint, "error")
 		return nil, fmt.Errorf("error reading response body: %w", readErr)
 	}
 
 	s.logger.Infof("Slack API %s status: %d", endpoint, resp.StatusCode)
 
-	var slackResp SlackAPIResponse
-	if err := json.Unmarshal(bodyBytes, &slackResp); err != nil {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		slackRequests.Inc(endpoint, "ratelimited")
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		slackLimiter.Pause(endpoint, retryAfter)
+		s.logger.Warnf("Slack rate limited %s, holding it back for %v", endpoint, retryAfter)
+		return nil, &SlackAPIError{Method: endpoint, StatusCode: resp.StatusCode, Code: "ratelimited", RetryAfter: retryAfter}
+	}
+	if resp.StatusCode >= 500 {
+		slackRequests.Inc(endpoint, "error")
+		return nil, &SlackAPIError{Method: endpoint, StatusCode: resp.StatusCode}
+	}
+
+	// Decoded on its own first, the other fields differ between methods
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(bodyBytes, &status); err != nil {
+		slackRequests.Inc(endpoint, "error")
 		s.logger.Errorf("Error decoding Slack API response for %s: %v", endpoint, err)
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
-	if !slackResp.OK {
-		s.logger.Errorf("Slack API error for %s - Error: %s", endpoint, slackResp.Error)
-		return nil, fmt.Errorf("slack API error: %s", slackResp.Error)
+	if !status.OK {
+		slackRequests.Inc(endpoint, "error")
+		s.logger.Errorf("Slack API error for %s - Error: %s", endpoint, status.Error)
+		return nil, &SlackAPIError{Method: endpoint, StatusCode: resp.StatusCode, Code: status.Error}
+	}
+
+	slackRequests.Inc(endpoint, "ok")
+	if result == nil {
+		result = &SlackAPIResponse{}
+	}
+	if err := json.Unmarshal(bodyBytes, result); err != nil {
+		return nil, fmt.Errorf("error decoding %s response: %w", endpoint, err)
+	}
+	slackResp, _ := result.(*SlackAPIResponse)
+	return slackResp, nil
+}
+
+// SLACK_MAX_FILES_PER_MESSAGE is how many files Slack shares in one message
+const SLACK_MAX_FILES_PER_MESSAGE = 10
+
+// SlackFileUpload is a file to share in Slack
+type SlackFileUpload struct {
+	Filename string
+	Title    string
+	Content  []byte
+}
+
+// uploadSlackFile shares a file in a channel, optionally in a thread
+func (s *SlackAPIClient) uploadSlackFile(channelID, threadTS, filename, title, comment string, content []byte) error {
+	return s.uploadSlackFiles(channelID, threadTS, comment, []SlackFileUpload{{Filename: filename, Title: title, Content: content}})
+}
+
+// uploadSlackFiles shares files as one message with Slack's upload flow: files.getUploadURLExternal
+// reserves each file, its content is posted to the returned URL and files.completeUploadExternal shares them
+func (s *SlackAPIClient) uploadSlackFiles(channelID, threadTS, comment string, uploads []SlackFileUpload) error {
+	if len(uploads) == 0 || len(uploads) > SLACK_MAX_FILES_PER_MESSAGE {
+		return fmt.Errorf("can share 1 to %d files at once, got %d", SLACK_MAX_FILES_PER_MESSAGE, len(uploads))
+	}
+
+	files := make([]map[string]string, 0, len(uploads))
+	for _, file := range uploads {
+		fileID, err := s.uploadSlackFileContent(file.Filename, file.Content)
+		if err != nil {
+			return err
+		}
+		files = append(files, map[string]string{"id": fileID, "title": file.Title})
 	}
 
-	return &slackResp, nil
+	filesJSON, err := json.Marshal(files)
+	if err != nil {
+		return fmt.Errorf("failed to marshal uploaded files: %w", err)
+	}
+	form := url.Values{
+		"files":      {string(filesJSON)},
+		"channel_id": {channelID},
+	}
+	if threadTS != "" {
+		form.Set("thread_ts", threadTS)
+	}
+	if comment != "" {
+		form.Set("initial_comment", comment)
+	}
+	if err := s.sendSlackFormRequest("files.completeUploadExternal", form, nil); err != nil {
+		return fmt.Errorf("failed to share uploaded files: %w", err)
+	}
+	return nil
+}
+
+// uploadSlackFileContent reserves an upload and posts a file's content, returning the file ID
+func (s *SlackAPIClient) uploadSlackFileContent(filename string, content []byte) (string, error) {
+	var upload struct {
+		UploadURL string `json:"upload_url"`
+		FileID    string `json:"file_id"`
+	}
+	err := s.sendSlackFormRequest("files.getUploadURLExternal", url.Values{
+		"filename": {filename},
+		"length":   {strconv.Itoa(len(content))},
+	}, &upload)
+	if err != nil {
+		return "", fmt.Errorf("failed to reserve upload of %s: %w", filename, err)
+	}
+
+	request, err := http.NewRequest("POST", upload.UploadURL, bytes.NewReader(content))
+	if err != nil {
+		return "", fmt.Errorf("failed to create file upload request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/octet-stream")
+	retryConfig := webhookRetryConfig()
+	retryConfig.Target = "slack_upload"
+	response, err := DoHTTPWithRetry(&http.Client{Timeout: 60 * time.Second}, request, retryConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload content of %s: %w", filename, err)
+	}
+	CloseWithErrorLog(response.Body, "file upload response body")
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("upload of %s returned status %d", filename, response.StatusCode)
+	}
+	return upload.FileID, nil
+}
+
+// openDirectMessage returns the ID of the direct message channel with a user
+func (s *SlackAPIClient) openDirectMessage(userID string) (string, error) {
+	var opened struct {
+		Channel struct {
+			ID string `json:"id"`
+		} `json:"channel"`
+	}
+	if err := s.sendSlackFormRequest("conversations.open", url.Values{"users": {userID}}, &opened); err != nil {
+		return "", fmt.Errorf("failed to open direct message with %s: %w", userID, err)
+	}
+	return opened.Channel.ID, nil
 }
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Slack outbox message statuses
+const (
+	OUTBOX_STATUS_PENDING = "pending"
+	OUTBOX_STATUS_SENT    = "sent"
+	OUTBOX_STATUS_DEAD    = "dead"
+)
+
+// OutboxMessage is a chat.postMessage call waiting in or done with the outbox
+type OutboxMessage struct {
+	ID            int
+	Channel       string
+	ThreadTS      string
+	Payload       string
+	Status        string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+}
+
+// slackOutboxRetryConfig returns the backoff of outbox messages that failed transiently
+func slackOutboxRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries:  getEnvInt("SLACK_OUTBOX_MAX_RETRIES", 8),
+		InitialWait: time.Duration(getEnvInt("SLACK_OUTBOX_INITIAL_WAIT_MS", 2000)) * time.Millisecond,
+		MaxWait:     time.Duration(getEnvInt("SLACK_OUTBOX_MAX_WAIT_MS", 300000)) * time.Millisecond,
+		Multiplier:  getEnvFloat("SLACK_OUTBOX_RETRY_MULTIPLIER", 2.0),
+	}
+}
+
+// outboxBackoff is the wait before retry number attempt, counting from 1
+func outboxBackoff(config RetryConfig, attempt int) time.Duration {
+	wait := float64(config.InitialWait) * math.Pow(config.Multiplier, float64(attempt-1))
+	if wait > float64(config.MaxWait) {
+		return config.MaxWait
+	}
+	return time.Duration(wait)
+}
+
+// EnqueueSlackMessage stores a message for the outbox worker to send and wakes the worker
+func EnqueueSlackMessage(db *sql.DB, message SlackMessage) (int, error) {
+	if err := message.Validate(); err != nil {
+		return 0, fmt.Errorf("invalid message: %w", err)
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	var id int
+	err = db.QueryRow(`
+		INSERT INTO slack_outbox (channel, thread_ts, payload) VALUES ($1, $2, $3)
+		RETURNING id`, message.Channel, message.ThreadTS, string(payload)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to queue Slack message for %s: %w", message.Channel, err)
+	}
+
+	slackOutbox.Wake()
+	return id, nil
+}
+
+// SlackOutbox sends queued messages in order per channel and thread.
+// Only the oldest pending message of a channel or thread is ever sent, so a message
+// waiting for a retry holds back the ones after it until it is sent or dead-lettered.
+// Delivery is at least once: a crash between Slack's answer and the status update resends the message.
+type SlackOutbox struct {
+	wake       chan struct{}
+	running    bool
+	mu         sync.Mutex
+	processing sync.Mutex
+}
+
+// slackOutbox is the process wide outbox; its worker only runs in server mode
+var slackOutbox = &SlackOutbox{wake: make(chan struct{}, 1)}
+
+// Start runs the worker in the background, polling for due retries in between wake-ups
+func (o *SlackOutbox) Start() {
+	o.mu.Lock()
+	if o.running {
+		o.mu.Unlock()
+		return
+	}
+	o.running = true
+	o.mu.Unlock()
+
+	pollInterval := time.Duration(getEnvInt("SLACK_OUTBOX_POLL_INTERVAL_MS", 2000)) * time.Millisecond
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			o.Process()
+			select {
+			case <-o.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+	GetGlobalLogger().Infof("Slack outbox worker started, polling every %v", pollInterval)
+}
+
+// Wake tells the worker there is new work. Without a running worker,
+// as in CLI commands, the outbox is processed right away instead.
+func (o *SlackOutbox) Wake() {
+	o.mu.Lock()
+	running := o.running
+	o.mu.Unlock()
+
+	if !running {
+		o.Process()
+		return
+	}
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Process sends due messages until none are left
+func (o *SlackOutbox) Process() {
+	o.processing.Lock()
+	defer o.processing.Unlock()
+
+	logger := GetGlobalLogger()
+	db, err := GetDB()
+	if err != nil {
+		logger.Errorf("Failed to get database connection for the Slack outbox: %v", err)
+		return
+	}
+
+	client := NewSlackAPIClient()
+	for {
+		due, err := dueOutboxMessages(db, 50)
+		if err != nil {
+			logger.Errorf("Failed to load Slack outbox: %v", err)
+			return
+		}
+		if len(due) == 0 {
+			return
+		}
+		for _, message := range due {
+			deliverOutboxMessage(db, client, message)
+		}
+	}
+}
+
+// dueOutboxMessages returns the oldest pending message of every channel and thread whose turn has come
+func dueOutboxMessages(db *sql.DB, limit int) ([]OutboxMessage, error) {
+	rows, err := db.Query(`
+		SELECT id, channel, thread_ts, payload, attempts
+		FROM (
+			SELECT DISTINCT ON (channel, thread_ts) id, channel, thread_ts, payload, attempts, next_attempt_at
+			FROM slack_outbox
+			WHERE status = $1
+			ORDER BY channel, thread_ts, id
+		) heads
+		WHERE next_attempt_at <= NOW()
+		ORDER BY id
+		LIMIT $2`, OUTBOX_STATUS_PENDING, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Slack outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []OutboxMessage
+	for rows.Next() {
+		var message OutboxMessage
+		if err := rows.Scan(&message.ID, &message.Channel, &message.ThreadTS, &message.Payload, &message.Attempts); err != nil {
+			return nil, fmt.Errorf("failed to scan Slack outbox message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+// deliverOutboxMessage sends one message and records the outcome:
+// sent, retried later for transient failures, or dead-lettered
+func deliverOutboxMessage(db *sql.DB, client *SlackAPIClient, message OutboxMessage) {
+	logger := GetGlobalLogger()
+
+	var payload SlackMessage
+	if err := json.Unmarshal([]byte(message.Payload), &payload); err != nil {
+		logger.Errorf("Dead-lettering unreadable Slack outbox message %d: %v", message.ID, err)
+		if _, err := db.Exec(`UPDATE slack_outbox SET status = $1, last_error = $2 WHERE id = $3`,
+			OUTBOX_STATUS_DEAD, err.Error(), message.ID); err != nil {
+			logger.Errorf("Failed to dead-letter Slack outbox message %d: %v", message.ID, err)
+		}
+		return
+	}
+
+	response, err := client.sendSlackAPIRequestWithResponse("chat.postMessage", payload)
+	if err == nil {
+		if _, err := db.Exec(`UPDATE slack_outbox SET status = $1, attempts = attempts + 1, last_error = NULL, sent_at = NOW(), message_ts = $2 WHERE id = $3`,
+			OUTBOX_STATUS_SENT, response.Timestamp, message.ID); err != nil {
+			logger.Errorf("Failed to mark Slack outbox message %d sent: %v", message.ID, err)
+		}
+		return
+	}
+
+	var apiErr *SlackAPIError
+	isAPIError := errors.As(err, &apiErr)
+
+	// Rate limiting is not a failed attempt, the message just waits its turn
+	if isAPIError && apiErr.StatusCode == http.StatusTooManyRequests {
+		if _, err := db.Exec(`UPDATE slack_outbox SET next_attempt_at = NOW() + $1 * INTERVAL '1 millisecond' WHERE id = $2`,
+			apiErr.RetryAfter.Milliseconds(), message.ID); err != nil {
+			logger.Errorf("Failed to reschedule Slack outbox message %d: %v", message.ID, err)
+		}
+		return
+	}
+
+	// Errors outside the API, such as timeouts, are worth retrying; Slack's own errors only when transient
+	config := slackOutboxRetryConfig()
+	attempts := message.Attempts + 1
+	if (isAPIError && !apiErr.Transient()) || attempts > config.MaxRetries {
+		logger.Errorf("Dead-lettering Slack outbox message %d to %s after %d attempts: %v", message.ID, message.Channel, attempts, err)
+		if _, err := db.Exec(`UPDATE slack_outbox SET status = $1, attempts = $2, last_error = $3 WHERE id = $4`,
+			OUTBOX_STATUS_DEAD, attempts, err.Error(), message.ID); err != nil {
+			logger.Errorf("Failed to dead-letter Slack outbox message %d: %v", message.ID, err)
+		}
+		return
+	}
+
+	wait := outboxBackoff(config, attempts)
+	logger.Warnf("Slack outbox message %d to %s failed (attempt %d/%d), retrying in %v: %v",
+		message.ID, message.Channel, attempts, config.MaxRetries+1, wait, err)
+	if _, err := db.Exec(`UPDATE slack_outbox SET attempts = $1, last_error = $2, next_attempt_at = NOW() + $3 * INTERVAL '1 millisecond' WHERE id = $4`,
+		attempts, err.Error(), wait.Milliseconds(), message.ID); err != nil {
+		logger.Errorf("Failed to reschedule Slack outbox message %d: %v", message.ID, err)
+	}
+}
+
+// retractOutboxMessages takes back queued messages: pending ones are dropped and sent ones deleted from Slack
+func retractOutboxMessages(db *sql.DB, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// Holding the worker keeps a message from being sent between the two steps
+	slackOutbox.processing.Lock()
+	defer slackOutbox.processing.Unlock()
+
+	if _, err := db.Exec(`DELETE FROM slack_outbox WHERE id = ANY($1) AND status <> $2`, pq.Array(ids), OUTBOX_STATUS_SENT); err != nil {
+		return fmt.Errorf("failed to drop queued Slack messages: %w", err)
+	}
+
+	rows, err := db.Query(`SELECT channel, message_ts FROM slack_outbox WHERE id = ANY($1) AND message_ts <> ''`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to look up sent Slack messages: %w", err)
+	}
+	defer rows.Close()
+
+	var sent []SlackDeleteRequest
+	for rows.Next() {
+		var request SlackDeleteRequest
+		if err := rows.Scan(&request.Channel, &request.TS); err != nil {
+			return fmt.Errorf("failed to scan sent Slack message: %w", err)
+		}
+		sent = append(sent, request)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read sent Slack messages: %w", err)
+	}
+
+	client := NewSlackAPIClient()
+	for _, request := range sent {
+		if err := client.sendSlackAPIRequest("chat.delete", request); err != nil {
+			var apiErr *SlackAPIError
+			if errors.As(err, &apiErr) && apiErr.Code == "message_not_found" {
+				continue
+			}
+			return fmt.Errorf("failed to delete Slack message %s: %w", request.TS, err)
+		}
+	}
+	return nil
+}
+
+// GetStuckOutboxMessages returns dead-lettered messages and pending ones that failed or waited longer than olderThan
+func GetStuckOutboxMessages(db *sql.DB, olderThan time.Duration, limit int) ([]OutboxMessage, error) {
+	rows, err := db.Query(`
+		SELECT id, channel, thread_ts, status, attempts, COALESCE(last_error, ''), next_attempt_at, created_at
+		FROM slack_outbox
+		WHERE status = $1 OR (status = $2 AND (attempts > 0 OR created_at < NOW() - $3 * INTERVAL '1 second'))
+		ORDER BY id DESC
+		LIMIT $4`, OUTBOX_STATUS_DEAD, OUTBOX_STATUS_PENDING, int(olderThan.Seconds()), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stuck Slack messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []OutboxMessage
+	for rows.Next() {
+		var message OutboxMessage
+		if err := rows.Scan(&message.ID, &message.Channel, &message.ThreadTS, &message.Status, &message.Attempts,
+			&message.LastError, &message.NextAttemptAt, &message.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan Slack outbox message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+// cleanupSlackOutbox removes sent messages after a week and dead ones after a month.
+// Replies of recorded digests are kept, a re-run of the digest deletes them from Slack.
+func cleanupSlackOutbox(db *sql.DB) (int64, error) {
+	result, err := db.Exec(`
+		DELETE FROM slack_outbox
+		WHERE ((status = $1 AND sent_at < NOW() - INTERVAL '7 days')
+		   OR (status = $2 AND created_at < NOW() - INTERVAL '30 days'))
+		  AND NOT EXISTS (SELECT 1 FROM recurring_digests WHERE slack_outbox.id = ANY(recurring_digests.reply_outbox_ids))`,
+		OUTBOX_STATUS_SENT, OUTBOX_STATUS_DEAD)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up Slack outbox: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// isOYEAdmin reports whether a Slack user is listed in OYE_ADMIN_USER_IDS
+func isOYEAdmin(userID string) bool {
+	for _, adminID := range strings.Split(os.Getenv("OYE_ADMIN_USER_IDS"), ",") {
+		if strings.TrimSpace(adminID) == userID && userID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isOutboxCommand reports whether the /oye text asks for the outbox
+func isOutboxCommand(text string) bool {
+	return strings.EqualFold(strings.TrimSpace(text), "outbox")
+}
+
+// handleOutboxCommand lists stuck outbox messages to an admin
+func handleOutboxCommand(w http.ResponseWriter, req *SlackCommandRequest) {
+	logger := GetGlobalLogger()
+
+	if !isOYEAdmin(req.UserID) {
+		sendImmediateResponse(w, "Sorry, `/oye outbox` is for OYE admins only.", "ephemeral")
+		return
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		logger.Errorf("Failed to get database connection for the outbox command: %v", err)
+		sendImmediateResponse(w, "Sorry, the outbox is unavailable right now.", "ephemeral")
+		return
+	}
+
+	messages, err := GetStuckOutboxMessages(db, 5*time.Minute, 20)
+	if err != nil {
+		logger.Errorf("Failed to list stuck Slack messages: %v", err)
+		sendImmediateResponse(w, "Sorry, the outbox couldn't be loaded.", "ephemeral")
+		return
+	}
+	if len(messages) == 0 {
+		sendImmediateResponse(w, "📭 No stuck messages, the outbox is flowing.", "ephemeral")
+		return
+	}
+
+	lines := make([]string, 0, len(messages))
+	for _, message := range messages {
+		target := formatSubscriptionTarget(message.Channel)
+		if message.ThreadTS != "" {
+			target += " (thread " + message.ThreadTS + ")"
+		}
+		line := fmt.Sprintf("• *#%d* %s to %s, %d attempts, queued %s", message.ID, message.Status, target,
+			message.Attempts, message.CreatedAt.Format("Jan 2 15:04"))
+		if message.Status == OUTBOX_STATUS_PENDING {
+			line += ", next try " + message.NextAttemptAt.Format("15:04:05")
+		}
+		if message.LastError != "" {
+			line += fmt.Sprintf("\n    `%s`", truncateUTF8(message.LastError, 200))
+		}
+		lines = append(lines, line)
+	}
+	sendImmediateResponse(w, "*📮 Stuck Slack messages*\n"+strings.Join(lines, "\n"), "ephemeral")
+}
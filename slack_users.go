@@ -31,6 +31,7 @@ type SlackAPIUser struct {
 	Profile SlackUserProfile `json:"profile"`
 	IsBot   bool             `json:"is_bot"`
 	Deleted bool             `json:"deleted"`
+	TZ      string           `json:"tz"`
 }
 
 // GetAllSlackUsers retrieves all users from the Slack workspace
@@ -93,6 +94,7 @@ func GetAllSlackUsers() ([]SlackUser, error) {
 			Email:       member.Profile.Email,
 			IsBot:       member.IsBot,
 			Deleted:     member.Deleted,
+			TZ:          member.TZ,
 		}
 		users = append(users, user)
 	}
@@ -125,8 +127,8 @@ func SyncSlackUsersToDatabase() error {
 
 	// Prepare upsert statement (PostgreSQL syntax)
 	stmt, err := tx.Prepare(`
-		INSERT INTO slack_users (slack_user_id, real_name, display_name, email, is_bot, deleted, last_sync)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO slack_users (slack_user_id, real_name, display_name, email, is_bot, deleted, tz, last_sync)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (slack_user_id) 
 		DO UPDATE SET 
 			real_name = EXCLUDED.real_name,
@@ -134,6 +136,7 @@ func SyncSlackUsersToDatabase() error {
 			email = EXCLUDED.email,
 			is_bot = EXCLUDED.is_bot,
 			deleted = EXCLUDED.deleted,
+			tz = EXCLUDED.tz,
 			last_sync = EXCLUDED.last_sync
 	`)
 	if err != nil {
@@ -152,6 +155,7 @@ func SyncSlackUsersToDatabase() error {
 			user.Email,
 			user.IsBot,
 			user.Deleted,
+			user.TZ,
 			syncTime,
 		)
 		if err != nil {
@@ -185,7 +189,7 @@ func GetSlackUsersFromDatabase() ([]SlackUser, error) {
 	}
 
 	query := `
-		SELECT slack_user_id, real_name, display_name, email, is_bot, deleted
+		SELECT slack_user_id, real_name, display_name, email, is_bot, deleted, tz
 		FROM slack_users 
 		WHERE deleted = false AND is_bot = false
 		ORDER BY real_name
@@ -209,6 +213,7 @@ func GetSlackUsersFromDatabase() ([]SlackUser, error) {
 			&email,
 			&user.IsBot,
 			&user.Deleted,
+			&user.TZ,
 		)
 		if err != nil {
 			logger.Errorf("Failed to scan user row: %v", err)
@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -98,61 +97,18 @@ func getFilteredTasksWithTimeout(startTime time.Time, endTime time.Time, project
 	endDateStr := endTime.Format("2006-01-02")
 	logger.Infof("Searching for tasks between dates: %s and %s", startDateStr, endDateStr)
 
-	// Simplified query that should work with the actual database structure
-	var query string
-	var args []interface{}
-
 	// Filter out empty project names and check if we have valid project names
 	validProjectNames := make([]string, 0)
 	for _, projectName := range projectNames {
 		projectName = strings.TrimSpace(projectName)
 		if projectName != "" {
-			validProjectNames = append(validProjectNames, strings.ToLower(projectName))
+			validProjectNames = append(validProjectNames, projectName)
 		}
 	}
 
-	if len(validProjectNames) > 0 {
-
-		// Build dynamic placeholders for IN clause
-		placeholders := make([]string, len(validProjectNames))
-		for i := range validProjectNames {
-			placeholders[i] = fmt.Sprintf("$%d", i+3) // Start from $3 since $1,$2 are dates
-		}
-		inClause := strings.Join(placeholders, ",")
-
-		// When filtering by project, join with projects table
-		query = fmt.Sprintf(`
-		SELECT 
-			t.task_id,
-			t.parent_id,
-			t.name,
-			COALESCE(SUM(CASE 
-				WHEN te.date >= $1 AND te.date <= $2
-				THEN te.duration 
-				ELSE 0 
-			END), 0) as current_period_duration,
-			COALESCE(SUM(te.duration), 0) as total_duration
-		FROM tasks t
-		LEFT JOIN time_entries te ON t.task_id = te.task_id
-		LEFT JOIN projects p ON t.project_id = p.id
-		WHERE LOWER(p.name) IN (%s)
-		GROUP BY t.task_id, t.parent_id, t.name
-		HAVING COALESCE(SUM(CASE 
-			WHEN te.date >= $%d AND te.date <= $%d
-			THEN te.duration 
-			ELSE 0 
-		END), 0) > 0
-		ORDER BY t.name;`, inClause, len(validProjectNames)+3, len(validProjectNames)+4)
-
-		// Build args array with individual project names
-		args = []interface{}{startDateStr, endDateStr}
-		for _, projectName := range validProjectNames {
-			args = append(args, projectName)
-		}
-		args = append(args, startDateStr, endDateStr)
-	} else {
-		// When not filtering by project, get all tasks with time entries in the period
-		query = `
+	// Get all tasks with time entries in the period. tasks.project_id is not
+	// populated by the sync, so project filtering happens on the task hierarchy below.
+	query := `
 		SELECT 
 			t.task_id,
 			t.parent_id,
@@ -172,8 +128,7 @@ func getFilteredTasksWithTimeout(startTime time.Time, endTime time.Time, project
 			ELSE 0 
 		END), 0) > 0
 		ORDER BY t.name;`
-		args = []interface{}{startDateStr, endDateStr, startDateStr, endDateStr}
-	}
+	args := []interface{}{startDateStr, endDateStr, startDateStr, endDateStr}
 
 	logger.Infof("Query: %s", query)
 	logger.Infof("Args: %v", args)
@@ -228,6 +183,8 @@ func getFilteredTasksWithTimeout(startTime time.Time, endTime time.Time, project
 		// Format durations using existing formatDuration function (takes seconds)
 		task.CurrentTime = formatDuration(currentDuration)
 		task.TotalDuration = formatDuration(totalDuration)
+		task.CurrentSeconds = currentDuration
+		task.TotalSeconds = totalDuration
 
 		// Parse estimation from task name and calculate usage based on total time spent
 		estimationInfo := ParseTaskEstimationWithUsage(task.Name, task.TotalDuration, "0h 0m")
@@ -251,10 +208,55 @@ func getFilteredTasksWithTimeout(startTime time.Time, endTime time.Time, project
 		allTasks = append(allTasks, task)
 	}
 
+	if len(validProjectNames) > 0 {
+		allTasks = filterTasksByProjectNames(allTasks, validProjectNames, nil)
+	}
+
 	logger.Infof("Query returned %d total tasks, filtered to %d tasks", taskCount, len(allTasks))
 	return allTasks
 }
 
+// filterTasksByProjectNames keeps tasks whose project is in include (any project when empty)
+// and not in exclude. Projects are resolved through the task hierarchy, names compare case-insensitively.
+func filterTasksByProjectNames(tasks []TaskInfo, include, exclude []string) []TaskInfo {
+	if len(include) == 0 && len(exclude) == 0 {
+		return tasks
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		return []TaskInfo{}
+	}
+
+	allTasks, err := getAllTasks(db)
+	if err != nil {
+		return []TaskInfo{}
+	}
+
+	toSet := func(names []string) map[string]bool {
+		set := make(map[string]bool, len(names))
+		for _, name := range names {
+			set[strings.ToLower(name)] = true
+		}
+		return set
+	}
+	included, excluded := toSet(include), toSet(exclude)
+
+	var filtered []TaskInfo
+	for _, task := range tasks {
+		projectName := strings.ToLower(getProjectNameForTask(task.TaskID, allTasks))
+		if len(included) > 0 && !included[projectName] {
+			continue
+		}
+		if excluded[projectName] {
+			continue
+		}
+		filtered = append(filtered, task)
+	}
+
+	return filtered
+}
+
 // addCommentsToTasksCtx is the single implementation that enriches tasks with comments using the provided context
 func addCommentsToTasksCtx(ctx context.Context, tasks []TaskInfo, startTime time.Time, endTime time.Time) []TaskInfo {
 	logger := GetGlobalLogger()
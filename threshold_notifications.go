@@ -35,8 +35,39 @@ func checkThresholdNotifications(db *sql.DB, updatedTaskIDs []int) error {
 
 	logger.Infof("Detected %d threshold crossings", len(alerts))
 
-	// Send notifications to users
-	return sendThresholdNotifications(db, alerts)
+	// Publish to outbound webhooks alongside the Slack notifications
+	dispatchAlertWebhooks(alerts)
+
+	// Escalations and missing estimates have their own recipients, everything else goes to users by project
+	var thresholdAlerts, escalationAlerts, unestimatedAlerts []ThresholdAlert
+	for _, alert := range alerts {
+		switch {
+		case alert.Escalation != nil:
+			escalationAlerts = append(escalationAlerts, alert)
+		case alert.HoursStep > 0:
+			unestimatedAlerts = append(unestimatedAlerts, alert)
+		default:
+			thresholdAlerts = append(thresholdAlerts, alert)
+		}
+	}
+
+	if len(thresholdAlerts) > 0 {
+		if err := sendThresholdNotifications(db, thresholdAlerts); err != nil {
+			return err
+		}
+	}
+
+	if len(escalationAlerts) > 0 {
+		if err := sendEscalationNotifications(db, escalationAlerts); err != nil {
+			return err
+		}
+	}
+
+	if len(unestimatedAlerts) > 0 {
+		return sendUnestimatedTaskNotifications(db, unestimatedAlerts)
+	}
+
+	return nil
 }
 
 // detectThresholdCrossings finds tasks that have crossed threshold levels
@@ -115,7 +146,27 @@ func detectThresholdCrossings(db *sql.DB, taskIDs []int) ([]ThresholdAlert, erro
 		// Parse estimation from task name using total time to calculate usage
 		estimation := ParseTaskEstimationWithUsage(name, totalTime, "0h 0m")
 		if estimation.ErrorMessage != "" {
-			logger.Debugf("Skipping task %d (%s): %s", taskID, name, estimation.ErrorMessage)
+			logger.Debugf("Skipping threshold check for task %d (%s): %s", taskID, name, estimation.ErrorMessage)
+
+			// Tasks without a valid estimate get a separate "needs an estimate" alert once they grow large
+			hoursStep, shouldNotify, err := checkAndRecordUnestimatedStep(db, taskID, totalDuration)
+			if err != nil {
+				logger.Errorf("Failed to check unestimated task %d: %v", taskID, err)
+				continue
+			}
+
+			if shouldNotify {
+				alerts = append(alerts, ThresholdAlert{
+					TaskID:         taskID,
+					ParentID:       parentID,
+					Name:           name,
+					EstimationInfo: estimation,
+					CurrentTime:    currentTime,
+					TotalDuration:  totalTime,
+					JustCrossed:    true,
+					HoursStep:      hoursStep,
+				})
+			}
 			continue
 		}
 
@@ -142,6 +193,32 @@ func detectThresholdCrossings(db *sql.DB, taskIDs []int) ([]ThresholdAlert, erro
 			}
 			alerts = append(alerts, alert)
 		}
+
+		// Past 100% the regular levels are exhausted, so check the escalation policy
+		if percentage < THRESHOLD_OVER {
+			continue
+		}
+
+		tier, shouldEscalate, err := checkAndRecordEscalation(db, taskID, percentage)
+		if err != nil {
+			logger.Errorf("Failed to check escalation for task %d: %v", taskID, err)
+			continue
+		}
+
+		if shouldEscalate {
+			alerts = append(alerts, ThresholdAlert{
+				TaskID:           taskID,
+				ParentID:         parentID,
+				Name:             name,
+				EstimationInfo:   estimation,
+				CurrentTime:      currentTime,
+				TotalDuration:    totalTime,
+				Percentage:       percentage,
+				ThresholdCrossed: tier.Threshold,
+				JustCrossed:      true,
+				Escalation:       tier,
+			})
+		}
 	}
 
 	return alerts, nil
@@ -242,18 +319,8 @@ func sendThresholdNotifications(db *sql.DB, alerts []ThresholdAlert) error {
 			continue
 		}
 
-		// Convert ThresholdAlert to TaskInfo for existing messaging functions
-		taskInfos := convertAlertsToTaskInfos(userAlerts)
-
-		// Group by project using existing function
-		projectGroups := groupTasksByProject(taskInfos)
-
-		// Send using existing messaging function
 		logger.Infof("Sending threshold notifications to user %s for %d tasks", user.ID, len(userAlerts))
-		sendTasksGroupedByProjectToUser(user.ID, projectGroups)
-
-		// Small delay between users to avoid rate limiting
-		time.Sleep(250 * time.Millisecond)
+		sendTaskAlertsToUser(db, user.ID, userAlerts)
 	}
 
 	return nil
@@ -307,6 +374,13 @@ func convertAlertsToTaskInfos(alerts []ThresholdAlert) []TaskInfo {
 	var taskInfos []TaskInfo
 
 	for _, alert := range alerts {
+		comment := fmt.Sprintf("🚨 THRESHOLD ALERT: %d%% reached!", alert.ThresholdCrossed)
+		if alert.Escalation != nil {
+			comment = fmt.Sprintf("%s ESCALATION: %d%% reached (currently %.1f%%)!", EMOJI_CRITICAL, alert.ThresholdCrossed, alert.Percentage)
+		} else if alert.HoursStep > 0 {
+			comment = fmt.Sprintf("%s This needs an estimate: over %dh logged without one (%s)", EMOJI_MEMO, alert.HoursStep, alert.EstimationInfo.ErrorMessage)
+		}
+
 		taskInfo := TaskInfo{
 			TaskID:         alert.TaskID,
 			ParentID:       alert.ParentID,
@@ -315,7 +389,7 @@ func convertAlertsToTaskInfos(alerts []ThresholdAlert) []TaskInfo {
 			CurrentTime:    alert.CurrentTime,
 			TotalDuration:  alert.TotalDuration,
 			DaysWorked:     1, // Not relevant for threshold notifications
-			Comments:       []string{fmt.Sprintf("🚨 THRESHOLD ALERT: %d%% reached!", alert.ThresholdCrossed)},
+			Comments:       []string{comment},
 		}
 
 		taskInfos = append(taskInfos, taskInfo)
@@ -28,6 +28,8 @@ type TaskInfo struct {
 	EstimationInfo EstimationInfo
 	CurrentTime    string
 	TotalDuration  string
+	CurrentSeconds int // CurrentTime in seconds
+	TotalSeconds   int // TotalDuration in seconds
 	DaysWorked     int
 	Comments       []string
 }
@@ -43,74 +45,14 @@ type ThresholdAlert struct {
 	Percentage       float64
 	ThresholdCrossed int
 	JustCrossed      bool
+	Escalation       *EscalationTier // set when the alert is an escalation above 100%
+	HoursStep        int             // set when the alert is for a task without a valid estimate
 }
 
-// Slack message structures (consolidated)
-type SlackMessage struct {
-	Text   string  `json:"text"`
-	Blocks []Block `json:"blocks"`
-}
-
-type Block struct {
-	Type      string        `json:"type"`
-	Text      *Text         `json:"text,omitempty"`
-	Fields    []Field       `json:"fields,omitempty"`
-	Elements  []interface{} `json:"elements,omitempty"`
-	Accessory *Accessory    `json:"accessory,omitempty"`
-	BlockID   string        `json:"block_id,omitempty"`
-	Label     *Text         `json:"label,omitempty"`
-	Element   interface{}   `json:"element,omitempty"`
-}
-
-type Text struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
-}
-
-type Field struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
-}
-
-type Element struct {
-	Type                 string                `json:"type"`
-	Text                 string                `json:"text,omitempty"`
-	ActionID             string                `json:"action_id,omitempty"`
-	Style                string                `json:"style,omitempty"`
-	Value                string                `json:"value,omitempty"`
-	Placeholder          *Text                 `json:"placeholder,omitempty"`
-	InitialValue         string                `json:"initial_value,omitempty"`
-	DispatchActionConfig *DispatchActionConfig `json:"dispatch_action_config,omitempty"`
-}
-
-type DispatchActionConfig struct {
-	TriggerActionsOn []string `json:"trigger_actions_on"`
-}
-
-type ButtonElement struct {
-	Type     string `json:"type"`
-	Text     *Text  `json:"text,omitempty"`
-	ActionID string `json:"action_id,omitempty"`
-	Style    string `json:"style,omitempty"`
-	Value    string `json:"value,omitempty"`
-}
-
-type InputElement struct {
-	Type                 string                `json:"type"`
-	ActionID             string                `json:"action_id"`
-	Placeholder          *Text                 `json:"placeholder,omitempty"`
-	InitialValue         string                `json:"initial_value,omitempty"`
-	DispatchActionConfig *DispatchActionConfig `json:"dispatch_action_config,omitempty"`
-}
-
-type Accessory struct {
-	Type           string                   `json:"type"`
-	Text           *Text                    `json:"text,omitempty"`
-	ActionID       string                   `json:"action_id,omitempty"`
-	Options        []map[string]interface{} `json:"options,omitempty"`
-	InitialOptions []map[string]interface{} `json:"initial_options,omitempty"`
-	Placeholder    *Text                    `json:"placeholder,omitempty"`
-	InitialValue   string                   `json:"initial_value,omitempty"`
+// Escalation tier above the regular threshold levels
+type EscalationTier struct {
+	Threshold  int
+	Recipients []string // "assignees", "owner", "channel" or "channel:<id>"
 }
 
 // Slack API structures
@@ -139,12 +81,16 @@ type SlackCommandRequest struct {
 	ResponseURL string `json:"response_url"`
 	ProjectName string `json:"project_name,omitempty"`
 	TriggerID   string `json:"trigger_id"`
+	ThreadTS    string `json:"thread_ts,omitempty"` // set when answering in an existing thread, e.g. a bot mention
+	Digest      string `json:"digest,omitempty"`    // set for recurring reports, see SlackNotifier.Digest
 }
 
+// SlackCommandResponse is a slash command reply, either returned directly or posted to a response_url
 type SlackCommandResponse struct {
-	ResponseType string  `json:"response_type"`
-	Text         string  `json:"text"`
-	Blocks       []Block `json:"blocks,omitempty"`
+	ResponseType    string  `json:"response_type,omitempty"`
+	ReplaceOriginal bool    `json:"replace_original,omitempty"`
+	Text            string  `json:"text"`
+	Blocks          []Block `json:"blocks,omitempty"`
 }
 
 // Conversation context for Slack interactions
@@ -200,6 +146,7 @@ type RetryConfig struct {
 	InitialWait time.Duration
 	MaxWait     time.Duration
 	Multiplier  float64
+	Target      string // names the remote service in request metrics, e.g. "timecamp"
 }
 
 // Slack user information
@@ -211,4 +158,5 @@ type SlackUser struct {
 	Email       string `json:"email"`
 	IsBot       bool   `json:"is_bot"`
 	Deleted     bool   `json:"deleted"`
+	TZ          string `json:"tz"` // IANA timezone from the Slack profile
 }
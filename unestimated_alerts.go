@@ -0,0 +1,119 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Default number of hours a task may accumulate without a valid estimate before alerting
+const defaultUnestimatedAlertHours = 20
+
+// getUnestimatedAlertHours returns the configured hour step for unestimated task alerts
+// Tasks are alerted once when they pass the step, and again at every further multiple of it
+func getUnestimatedAlertHours() int {
+	hours := getEnvInt("UNESTIMATED_ALERT_HOURS", defaultUnestimatedAlertHours)
+	if hours <= 0 {
+		return 0
+	}
+	return hours
+}
+
+// checkAndRecordUnestimatedStep atomically checks and records the hour step a task without
+// a valid estimate has reached. Returns the step and whether a new alert should be sent.
+func checkAndRecordUnestimatedStep(db *sql.DB, taskID int, totalSeconds int) (int, bool, error) {
+	stepHours := getUnestimatedAlertHours()
+	if stepHours == 0 {
+		return 0, false, nil // Unestimated alerts disabled
+	}
+
+	totalHours := float64(totalSeconds) / 3600
+	hoursStep := int(totalHours) / stepHours * stepHours
+	if hoursStep == 0 {
+		return 0, false, nil // Below the first step
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be no-op if committed
+
+	var existingStep sql.NullInt64
+	checkQuery := `
+		SELECT hours_step 
+		FROM unestimated_task_alerts 
+		WHERE task_id = $1 AND hours_step >= $2 
+		ORDER BY hours_step DESC 
+		LIMIT 1 
+		FOR UPDATE`
+
+	err = tx.QueryRow(checkQuery, taskID, hoursStep).Scan(&existingStep)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, false, fmt.Errorf("failed to check existing unestimated task alerts: %w", err)
+	}
+
+	if existingStep.Valid {
+		tx.Commit()
+		return int(existingStep.Int64), false, nil // Already alerted for this step
+	}
+
+	insertQuery := `
+		INSERT INTO unestimated_task_alerts (task_id, hours_step, total_hours)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (task_id, hours_step) DO NOTHING
+	`
+	if _, err = tx.Exec(insertQuery, taskID, hoursStep, totalHours); err != nil {
+		return 0, false, fmt.Errorf("failed to record unestimated task alert: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return hoursStep, true, nil
+}
+
+// sendUnestimatedTaskNotifications asks the assignees of each task's project to add an estimate
+func sendUnestimatedTaskNotifications(db *sql.DB, alerts []ThresholdAlert) error {
+	logger := GetGlobalLogger()
+
+	allTasks, err := getAllTasks(db)
+	if err != nil {
+		return fmt.Errorf("failed to load task hierarchy: %w", err)
+	}
+
+	// Collect alerts per assignee so everyone gets a single message
+	alertsByUser := make(map[string][]ThresholdAlert)
+	for _, alert := range alerts {
+		project, err := GetProjectForTask(db, allTasks, alert.TaskID)
+		if err != nil {
+			logger.Errorf("Failed to resolve project for task %d: %v", alert.TaskID, err)
+			continue
+		}
+		if project == nil {
+			logger.Warnf("Task %d (%s) has no project, cannot alert assignees about missing estimate", alert.TaskID, alert.Name)
+			continue
+		}
+
+		assignees, err := GetProjectAssignees(db, project.ID)
+		if err != nil {
+			logger.Errorf("Failed to get assignees for project %s: %v", project.Name, err)
+			continue
+		}
+		if len(assignees) == 0 {
+			logger.Warnf("Project %s has no assignees, skipping missing estimate alert for task %d", project.Name, alert.TaskID)
+			continue
+		}
+
+		for _, assignee := range assignees {
+			alertsByUser[assignee] = append(alertsByUser[assignee], alert)
+		}
+	}
+
+	for userID, userAlerts := range alertsByUser {
+		logger.Infof("Sending missing estimate alerts to user %s for %d tasks", userID, len(userAlerts))
+		sendTaskAlertsToUser(db, userID, userAlerts)
+	}
+
+	return nil
+}
@@ -66,3 +66,114 @@ func UnassignUserFromProject(db *sql.DB, slackUserID string, projectID int) erro
 
 	return nil
 }
+
+// GetProjectAssignees returns the Slack user IDs assigned to a project
+func GetProjectAssignees(db *sql.DB, projectID int) ([]string, error) {
+	query := `
+		SELECT upa.slack_user_id
+		FROM user_project_assignments upa
+		INNER JOIN slack_users su ON su.slack_user_id = upa.slack_user_id
+		WHERE upa.project_id = $1 AND su.deleted = false AND su.is_bot = false
+		ORDER BY upa.slack_user_id
+	`
+
+	rows, err := db.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
+
+// GetProjectOwner returns the Slack user ID of the project owner, or "" if none is set
+func GetProjectOwner(db *sql.DB, projectID int) (string, error) {
+	query := `
+		SELECT slack_user_id
+		FROM user_project_assignments
+		WHERE project_id = $1 AND role = $2
+		ORDER BY updated_at DESC
+		LIMIT 1
+	`
+
+	var userID string
+	err := db.QueryRow(query, projectID, PROJECT_ROLE_OWNER).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// GetProjectOwners returns a map of project ID to owner Slack user ID
+func GetProjectOwners(db *sql.DB) (map[int]string, error) {
+	query := `SELECT project_id, slack_user_id FROM user_project_assignments WHERE role = $1`
+
+	rows, err := db.Query(query, PROJECT_ROLE_OWNER)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	owners := make(map[int]string)
+	for rows.Next() {
+		var projectID int
+		var userID string
+		if err := rows.Scan(&projectID, &userID); err != nil {
+			return nil, err
+		}
+		owners[projectID] = userID
+	}
+
+	return owners, nil
+}
+
+// canChangeProjectOwner reports whether a user may pick a project's owner:
+// its current owner can hand it over, OYE admins can always pick one
+func canChangeProjectOwner(userID, currentOwner string) bool {
+	return isOYEAdmin(userID) || (currentOwner != "" && currentOwner == userID)
+}
+
+// SetProjectOwner makes a user the owner of a project, assigning them if needed
+// Any previous owner stays assigned to the project as a regular member
+func SetProjectOwner(db *sql.DB, projectID int, slackUserID string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	demoteQuery := `
+		UPDATE user_project_assignments
+		SET role = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE project_id = $2 AND role = $3 AND slack_user_id != $4
+	`
+	if _, err := tx.Exec(demoteQuery, PROJECT_ROLE_MEMBER, projectID, PROJECT_ROLE_OWNER, slackUserID); err != nil {
+		return fmt.Errorf("failed to demote previous owner: %w", err)
+	}
+
+	ownerQuery := `
+		INSERT INTO user_project_assignments (slack_user_id, project_id, role, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (slack_user_id, project_id) DO UPDATE SET
+			role = EXCLUDED.role,
+			updated_at = CURRENT_TIMESTAMP
+	`
+	if _, err := tx.Exec(ownerQuery, slackUserID, projectID, PROJECT_ROLE_OWNER); err != nil {
+		return fmt.Errorf("failed to set project owner: %w", err)
+	}
+
+	return tx.Commit()
+}
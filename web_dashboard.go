@@ -0,0 +1,492 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Task table columns the project page can be sorted by
+const (
+	WEB_SORT_PERCENT = "percent"
+	WEB_SORT_NAME    = "name"
+	WEB_SORT_PERIOD  = "period"
+	WEB_SORT_TOTAL   = "total"
+)
+
+// WEB_CHART_MAX_DAYS caps the time-per-day chart of the task page
+const WEB_CHART_MAX_DAYS = 366
+
+// webPage is what every dashboard template is rendered with
+type webPage struct {
+	Title   string
+	Session *dashboardSession
+	Data    interface{}
+}
+
+// webPeriod is the period a page shows, with the values of its date inputs
+type webPeriod struct {
+	From, To time.Time
+}
+
+func (p webPeriod) FromValue() string { return p.From.Format("2006-01-02") }
+func (p webPeriod) ToValue() string   { return p.To.Format("2006-01-02") }
+
+// webStatus is a budget status shown as an emoji with a label
+type webStatus struct {
+	Emoji, Label string
+}
+
+// webUsageStatus describes a usage percentage with the same thresholds as the Slack reports
+func webUsageStatus(percentage *float64) webStatus {
+	if percentage == nil {
+		return webStatus{EMOJI_NO_TIME, "No estimate"}
+	}
+	midPoint, highPoint := getThresholdValues()
+	label := "On track"
+	switch {
+	case *percentage >= THRESHOLD_OVER:
+		label = "Over budget"
+	case *percentage > highPoint:
+		label = "Critical"
+	case *percentage > midPoint:
+		label = "High usage"
+	}
+	return webStatus{GetTaskStatus(*percentage).Emoji, label}
+}
+
+// registerDashboardRoutes adds the web dashboard to a mux when it is configured
+func registerDashboardRoutes(mux *http.ServeMux) {
+	logger := GetGlobalLogger()
+	if !dashboardEnabled() {
+		logger.Info("DASHBOARD_SESSION_SECRET not configured, the web dashboard is disabled")
+		return
+	}
+	if dashboardBaseURL() == "" {
+		logger.Warn("DASHBOARD_BASE_URL not configured, sign-in links and Sign in with Slack won't work")
+	}
+
+	mux.HandleFunc("GET /dashboard/login", handleDashboardLogin)
+	mux.HandleFunc("POST /dashboard/logout", handleDashboardLogout)
+	mux.HandleFunc("GET /dashboard/auth/magic", handleDashboardMagicLink)
+	mux.HandleFunc("GET /dashboard/auth/slack", handleDashboardSlackSignIn)
+	mux.HandleFunc("GET /dashboard/auth/callback", handleDashboardSlackCallback)
+	if fakeSlackSignInEnabled() {
+		registerFakeOIDCRoutes(mux)
+	}
+
+	mux.Handle("GET /dashboard", requireDashboardSession(handleWebProjects))
+	mux.Handle("GET /dashboard/projects/{id}", requireDashboardSession(handleWebProject))
+	mux.Handle("GET /dashboard/tasks/{id}", requireDashboardSession(handleWebTask))
+	mux.Handle("GET /dashboard/alerts", requireDashboardSession(handleWebAlerts))
+}
+
+// renderWebPage renders a dashboard template, pages are small so they are buffered to report errors properly
+func renderWebPage(w http.ResponseWriter, status int, name string, page webPage) {
+	var body strings.Builder
+	if err := webTemplates[name].ExecuteTemplate(&body, "layout", page); err != nil {
+		GetGlobalLogger().Errorf("Failed to render dashboard page %s: %v", name, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; img-src 'self' data:; form-action 'self'; frame-ancestors 'none'; base-uri 'none'")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Referrer-Policy", "same-origin")
+	w.WriteHeader(status)
+	w.Write([]byte(body.String()))
+}
+
+// renderWebError shows a message in place of a page
+func renderWebError(w http.ResponseWriter, status int, session *dashboardSession, message string) {
+	renderWebPage(w, status, "error", webPage{Title: http.StatusText(status), Session: session, Data: message})
+}
+
+// renderDashboardLogin shows the sign-in page, with an error when a sign-in failed
+func renderDashboardLogin(w http.ResponseWriter, r *http.Request, status int, message string) {
+	renderWebPage(w, status, "login", webPage{Title: "Sign in", Data: struct {
+		Error       string
+		SlackSignIn bool
+		Next        string
+	}{message, slackSignInEnabled(), safeDashboardPath(r.URL.Query().Get("next"))}})
+}
+
+// webProjectRow is a project on the project list
+type webProjectRow struct {
+	ID           int
+	Name         string
+	WeekSeconds  int
+	MonthSeconds int
+	OverBudget   int
+	WorstTask    string
+	WorstPercent *float64
+	Status       webStatus
+}
+
+// handleWebProjects lists the projects with their budget status this month
+func handleWebProjects(w http.ResponseWriter, r *http.Request, session *dashboardSession) {
+	db, err := GetDB()
+	if err != nil {
+		renderWebError(w, http.StatusServiceUnavailable, session, "The database is unavailable, please try again later.")
+		return
+	}
+	projects, err := GetAllProjects(db)
+	if err != nil {
+		GetGlobalLogger().Errorf("Dashboard failed to list projects: %v", err)
+		renderWebError(w, http.StatusInternalServerError, session, "The projects couldn't be loaded.")
+		return
+	}
+	dashboards, err := loadProjectDashboards(db, projects, time.Now())
+	if err != nil {
+		GetGlobalLogger().Errorf("Dashboard failed to load project stats: %v", err)
+		renderWebError(w, http.StatusInternalServerError, session, "The projects couldn't be loaded.")
+		return
+	}
+
+	rows := make([]webProjectRow, len(projects))
+	for i, project := range projects {
+		stats := dashboards[i]
+		rows[i] = webProjectRow{
+			ID:           project.ID,
+			Name:         project.Name,
+			WeekSeconds:  stats.WeekSeconds,
+			MonthSeconds: stats.MonthSeconds,
+			OverBudget:   stats.OverBudget,
+		}
+		if len(stats.TopTasks) > 0 {
+			worst := stats.TopTasks[0].Percentage
+			rows[i].WorstTask, rows[i].WorstPercent = stats.TopTasks[0].Name, &worst
+		}
+		rows[i].Status = webUsageStatus(rows[i].WorstPercent)
+	}
+	renderWebPage(w, http.StatusOK, "projects", webPage{Title: "Projects", Session: session, Data: rows})
+}
+
+// webTaskTable is a project's task table with its sort order
+type webTaskTable struct {
+	Project Project
+	Period  webPeriod
+	Tasks   []webTaskRow
+	Sort    string
+	Desc    bool
+}
+
+// webTaskRow is a task in a project's task table
+type webTaskRow struct {
+	apiTask
+	Status webStatus
+}
+
+// SortLink returns the link sorting the table by a column, toggling the order of the current column
+func (t webTaskTable) SortLink(column string) string {
+	query := url.Values{"from": {t.Period.FromValue()}, "to": {t.Period.ToValue()}, "sort": {column}}
+	if column == t.Sort && t.Desc || column != t.Sort && column == WEB_SORT_NAME {
+		query.Set("order", "asc")
+	} else {
+		query.Set("order", "desc")
+	}
+	return fmt.Sprintf("/dashboard/projects/%d?%s", t.Project.ID, query.Encode())
+}
+
+// SortMark returns the arrow shown next to the column the table is sorted by
+func (t webTaskTable) SortMark(column string) string {
+	if column != t.Sort {
+		return ""
+	}
+	if t.Desc {
+		return " ▼"
+	}
+	return " ▲"
+}
+
+// sortWebTasks orders tasks by a column, tasks without an estimate go last when sorting by percent
+func sortWebTasks(tasks []webTaskRow, column string, desc bool) {
+	less := func(a, b webTaskRow) bool {
+		switch column {
+		case WEB_SORT_NAME:
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		case WEB_SORT_PERIOD:
+			return *a.PeriodSeconds < *b.PeriodSeconds
+		case WEB_SORT_TOTAL:
+			return a.TotalSeconds < b.TotalSeconds
+		default:
+			return *a.UsagePercent < *b.UsagePercent
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if column == WEB_SORT_PERCENT && (a.UsagePercent == nil || b.UsagePercent == nil) {
+			return a.UsagePercent != nil && b.UsagePercent == nil
+		}
+		if desc {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+}
+
+// handleWebProject shows a project's tasks with time tracked in a period
+func handleWebProject(w http.ResponseWriter, r *http.Request, session *dashboardSession) {
+	projectID, err := pathID(r, "id")
+	if err != nil {
+		renderWebError(w, http.StatusNotFound, session, "There is no such project.")
+		return
+	}
+	from, to, err := parseAPIPeriod(r)
+	if err != nil {
+		renderWebError(w, http.StatusBadRequest, session, "The period is invalid: "+err.Error()+".")
+		return
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		renderWebError(w, http.StatusServiceUnavailable, session, "The database is unavailable, please try again later.")
+		return
+	}
+	project, err := GetProjectByID(db, projectID)
+	if err != nil {
+		GetGlobalLogger().Errorf("Dashboard failed to load project %d: %v", projectID, err)
+		renderWebError(w, http.StatusInternalServerError, session, "The project couldn't be loaded.")
+		return
+	}
+	if project == nil {
+		renderWebError(w, http.StatusNotFound, session, "There is no such project.")
+		return
+	}
+
+	table := webTaskTable{Project: *project, Period: webPeriod{from, to}, Sort: WEB_SORT_PERCENT, Desc: true}
+	switch column := r.URL.Query().Get("sort"); column {
+	case WEB_SORT_NAME, WEB_SORT_PERIOD, WEB_SORT_TOTAL, WEB_SORT_PERCENT:
+		table.Sort = column
+		table.Desc = r.URL.Query().Get("order") != "asc"
+	}
+
+	for _, task := range getFilteredTasksWithTimeout(from, to, []string{project.Name}, "") {
+		row := webTaskRow{apiTask: newAPITask(task.TaskID, task.ParentID, task.Name, project.Name, task.TotalSeconds)}
+		periodSeconds := task.CurrentSeconds
+		row.PeriodSeconds = &periodSeconds
+		row.Status = webUsageStatus(row.UsagePercent)
+		table.Tasks = append(table.Tasks, row)
+	}
+	sortWebTasks(table.Tasks, table.Sort, table.Desc)
+
+	renderWebPage(w, http.StatusOK, "project", webPage{Title: project.Name, Session: session, Data: table})
+}
+
+// webChartBar is a day of the time-per-day chart, in SVG user units
+type webChartBar struct {
+	X, Y, Width, Height float64
+	Label               string // the day, shown on some bars only
+	Title               string // the tooltip
+}
+
+// webChart is the time-per-day chart of a task page
+type webChart struct {
+	Width, Height float64
+	Left, Bottom  float64 // where the plot area ends on the left and at the bottom
+	Bars          []webChartBar
+	Gridlines     []webChartGridline
+}
+
+// webChartGridline is a horizontal line marking a number of hours
+type webChartGridline struct {
+	Y     float64
+	Label string
+}
+
+// buildWebChart lays out one bar per day of a period from the seconds tracked per day (YYYY-MM-DD)
+func buildWebChart(from, to time.Time, secondsByDay map[string]int) webChart {
+	chart := webChart{Width: 720, Height: 220, Left: 40, Bottom: 190}
+	const top = 10.0
+
+	days := webPeriodDays(from, to)
+	maxSeconds := 0
+	for _, seconds := range secondsByDay {
+		maxSeconds = max(maxSeconds, seconds)
+	}
+	maxHours := math.Max(1, math.Ceil(float64(maxSeconds)/3600))
+	plotHeight := chart.Bottom - top
+	for _, hours := range []float64{0, maxHours / 2, maxHours} {
+		chart.Gridlines = append(chart.Gridlines, webChartGridline{
+			Y:     roundChart(chart.Bottom - hours/maxHours*plotHeight),
+			Label: fmt.Sprintf("%gh", math.Round(hours*10)/10),
+		})
+	}
+
+	slot := (chart.Width - chart.Left) / float64(days)
+	labelEvery := int(math.Ceil(float64(days) / 10))
+	for i := 0; i < days; i++ {
+		day := from.AddDate(0, 0, i).Format("2006-01-02")
+		seconds := secondsByDay[day]
+		height := float64(seconds) / 3600 / maxHours * plotHeight
+		bar := webChartBar{
+			X:      roundChart(chart.Left + float64(i)*slot + slot*0.1),
+			Y:      roundChart(chart.Bottom - height),
+			Width:  roundChart(slot * 0.8),
+			Height: roundChart(height),
+			Title:  fmt.Sprintf("%s: %s", day, formatDuration(seconds)),
+		}
+		if i%labelEvery == 0 {
+			bar.Label = day[5:]
+		}
+		chart.Bars = append(chart.Bars, bar)
+	}
+	return chart
+}
+
+// webPeriodDays counts the days of a period, both ends included; rounded as days around a DST change aren't 24 hours
+func webPeriodDays(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours()/24)) + 1
+}
+
+// roundChart keeps SVG coordinates short
+func roundChart(value float64) float64 {
+	return math.Round(value*10) / 10
+}
+
+// getTaskSecondsByDay sums the time tracked on a task per day of a period
+func getTaskSecondsByDay(db *sql.DB, taskID int, from, to time.Time) (map[string]int, error) {
+	rows, err := db.Query(`
+		SELECT date, COALESCE(SUM(duration), 0)
+		FROM time_entries
+		WHERE task_id = $1 AND date >= $2 AND date <= $3
+		GROUP BY date`,
+		taskID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time per day: %w", err)
+	}
+	defer rows.Close()
+
+	secondsByDay := make(map[string]int)
+	for rows.Next() {
+		var day string
+		var seconds int
+		if err := rows.Scan(&day, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan time per day: %w", err)
+		}
+		secondsByDay[day] = seconds
+	}
+	return secondsByDay, rows.Err()
+}
+
+// handleWebTask shows a task's estimate, usage, time per day and history
+func handleWebTask(w http.ResponseWriter, r *http.Request, session *dashboardSession) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		renderWebError(w, http.StatusNotFound, session, "There is no such task.")
+		return
+	}
+	from, to, err := parseAPIPeriod(r)
+	if err != nil {
+		renderWebError(w, http.StatusBadRequest, session, "The period is invalid: "+err.Error()+".")
+		return
+	}
+	// The chart shows the last 30 days unless asked otherwise
+	if r.URL.Query().Get("from") == "" {
+		from = to.AddDate(0, 0, -29)
+	}
+	if webPeriodDays(from, to) > WEB_CHART_MAX_DAYS {
+		renderWebError(w, http.StatusBadRequest, session, fmt.Sprintf("The chart shows at most %d days.", WEB_CHART_MAX_DAYS))
+		return
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		renderWebError(w, http.StatusServiceUnavailable, session, "The database is unavailable, please try again later.")
+		return
+	}
+	task, err := getAPITaskDetail(db, taskID)
+	if err != nil {
+		GetGlobalLogger().Errorf("Dashboard failed to load task %d: %v", taskID, err)
+		renderWebError(w, http.StatusInternalServerError, session, "The task couldn't be loaded.")
+		return
+	}
+	if task == nil {
+		renderWebError(w, http.StatusNotFound, session, "There is no such task.")
+		return
+	}
+	secondsByDay, err := getTaskSecondsByDay(db, taskID, from, to)
+	if err != nil {
+		GetGlobalLogger().Errorf("Dashboard failed to load time per day of task %d: %v", taskID, err)
+		renderWebError(w, http.StatusInternalServerError, session, "The task's time couldn't be loaded.")
+		return
+	}
+
+	var project *Project
+	if task.Project != "" {
+		if project, err = GetProjectByName(db, task.Project); err != nil {
+			GetGlobalLogger().Warnf("Dashboard failed to look up project %s: %v", task.Project, err)
+		}
+	}
+	periodSeconds := 0
+	for _, seconds := range secondsByDay {
+		periodSeconds += seconds
+	}
+
+	renderWebPage(w, http.StatusOK, "task", webPage{Title: task.Name, Session: session, Data: struct {
+		Task          *apiTaskDetail
+		Project       *Project
+		Status        webStatus
+		Period        webPeriod
+		PeriodSeconds int
+		Chart         webChart
+	}{task, project, webUsageStatus(task.UsagePercent), webPeriod{from, to}, periodSeconds, buildWebChart(from, to, secondsByDay)}})
+}
+
+// handleWebAlerts shows the alert log, newest first
+func handleWebAlerts(w http.ResponseWriter, r *http.Request, session *dashboardSession) {
+	page, err := parseAPIPagination(r)
+	if err != nil {
+		renderWebError(w, http.StatusBadRequest, session, "The page is invalid: "+err.Error()+".")
+		return
+	}
+	alertType := r.URL.Query().Get("type")
+	switch alertType {
+	case "", API_ALERT_THRESHOLD, API_ALERT_ESCALATION, API_ALERT_UNESTIMATED:
+	default:
+		alertType = ""
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		renderWebError(w, http.StatusServiceUnavailable, session, "The database is unavailable, please try again later.")
+		return
+	}
+	alerts, total, err := getAPIAlerts(db, alertType, time.Time{}, page)
+	if err != nil {
+		GetGlobalLogger().Errorf("Dashboard failed to list alerts: %v", err)
+		renderWebError(w, http.StatusInternalServerError, session, "The alerts couldn't be loaded.")
+		return
+	}
+	page.Total = total
+
+	pageLink := func(offset int) string {
+		query := url.Values{"offset": {fmt.Sprint(offset)}, "limit": {fmt.Sprint(page.Limit)}}
+		if alertType != "" {
+			query.Set("type", alertType)
+		}
+		return "/dashboard/alerts?" + query.Encode()
+	}
+	var previous, next string
+	if page.Offset > 0 {
+		previous = pageLink(max(0, page.Offset-page.Limit))
+	}
+	if page.Offset+page.Limit < total {
+		next = pageLink(page.Offset + page.Limit)
+	}
+
+	renderWebPage(w, http.StatusOK, "alerts", webPage{Title: "Alerts", Session: session, Data: struct {
+		Alerts     []apiAlert
+		Type       string
+		Types      []string
+		Pagination apiPagination
+		Previous   string
+		Next       string
+	}{alerts, alertType, []string{API_ALERT_THRESHOLD, API_ALERT_ESCALATION, API_ALERT_UNESTIMATED}, page, previous, next}})
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	DASHBOARD_SESSION_COOKIE = "oye_session"
+	DASHBOARD_STATE_COOKIE   = "oye_oauth_state"
+	DASHBOARD_MAGIC_LINK_TTL = 15 * time.Minute
+)
+
+// Slack's "Sign in with Slack" (OpenID Connect) endpoints, each can be
+// overridden through the environment, or replaced by the fake provider in development
+var (
+	defaultSlackOIDCAuthorizeURL = "https://slack.com/openid/connect/authorize"
+	defaultSlackOIDCTokenURL     = "https://slack.com/api/openid.connect.token"
+	defaultSlackOIDCUserInfoURL  = "https://slack.com/api/openid.connect.userInfo"
+)
+
+// dashboardSession is the signed in user, carried in a signed cookie
+type dashboardSession struct {
+	UserID  string `json:"u"`
+	Name    string `json:"n"`
+	Expires int64  `json:"e"`
+}
+
+// dashboardEnabled reports whether the dashboard is configured, it needs a secret to sign sessions with
+func dashboardEnabled() bool {
+	return os.Getenv("DASHBOARD_SESSION_SECRET") != ""
+}
+
+// slackSignInEnabled reports whether "Sign in with Slack" is configured, otherwise only magic links work
+func slackSignInEnabled() bool {
+	return fakeSlackSignInEnabled() || os.Getenv("SLACK_CLIENT_ID") != "" && os.Getenv("SLACK_CLIENT_SECRET") != ""
+}
+
+// dashboardBaseURL is the public address of the server, e.g. https://oye.example.com
+func dashboardBaseURL() string {
+	return strings.TrimSuffix(os.Getenv("DASHBOARD_BASE_URL"), "/")
+}
+
+// signDashboardValue signs a payload for one purpose (session, magic link), so that
+// a value signed for one purpose is never accepted for another
+func signDashboardValue(purpose string, payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", purpose, err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return encoded + "." + dashboardSignature(purpose, encoded), nil
+}
+
+// verifyDashboardValue checks a signed value and decodes its payload
+func verifyDashboardValue(purpose, value string, payload interface{}) error {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(dashboardSignature(purpose, encoded))) {
+		return fmt.Errorf("invalid %s signature", purpose)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid %s encoding: %w", purpose, err)
+	}
+	if err := json.Unmarshal(data, payload); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", purpose, err)
+	}
+	return nil
+}
+
+func dashboardSignature(purpose, encoded string) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("DASHBOARD_SESSION_SECRET")))
+	mac.Write([]byte(purpose + ":" + encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newMagicLink returns a signed sign-in link for a Slack user, valid for DASHBOARD_MAGIC_LINK_TTL
+func newMagicLink(userID, name string) (string, error) {
+	token, err := signDashboardValue("magic", dashboardSession{
+		UserID:  userID,
+		Name:    name,
+		Expires: time.Now().Add(DASHBOARD_MAGIC_LINK_TTL).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return dashboardBaseURL() + "/dashboard/auth/magic?token=" + url.QueryEscape(token), nil
+}
+
+// isDashboardUser reports whether a Slack user may sign in: a synced, active member of the workspace
+func isDashboardUser(db *sql.DB, userID string) (bool, error) {
+	var allowed bool
+	err := db.QueryRow(`SELECT NOT COALESCE(deleted, FALSE) AND NOT COALESCE(is_bot, FALSE) FROM slack_users WHERE slack_user_id = $1`,
+		userID).Scan(&allowed)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up Slack user %s: %w", userID, err)
+	}
+	return allowed, nil
+}
+
+// startDashboardSession signs a user in and sends them on to next
+func startDashboardSession(w http.ResponseWriter, r *http.Request, userID, name, next string) {
+	db, err := GetDB()
+	if err != nil {
+		renderDashboardLogin(w, r, http.StatusServiceUnavailable, "The database is unavailable, please try again later.")
+		return
+	}
+	allowed, err := isDashboardUser(db, userID)
+	if err != nil {
+		GetGlobalLogger().Errorf("Dashboard sign-in check failed: %v", err)
+		renderDashboardLogin(w, r, http.StatusInternalServerError, "Signing in failed, please try again.")
+		return
+	}
+	if !allowed {
+		GetGlobalLogger().Warnf("Refused dashboard sign-in of unknown Slack user %s", userID)
+		renderDashboardLogin(w, r, http.StatusForbidden, "Your Slack account has no access to this dashboard.")
+		return
+	}
+
+	hours := getEnvInt("DASHBOARD_SESSION_HOURS", 12)
+	expires := time.Now().Add(time.Duration(hours) * time.Hour)
+	value, err := signDashboardValue("session", dashboardSession{UserID: userID, Name: name, Expires: expires.Unix()})
+	if err != nil {
+		renderDashboardLogin(w, r, http.StatusInternalServerError, "Signing in failed, please try again.")
+		return
+	}
+	http.SetCookie(w, dashboardCookie(DASHBOARD_SESSION_COOKIE, value, expires))
+	GetGlobalLogger().Infof("Slack user %s signed in to the dashboard", userID)
+	http.Redirect(w, r, safeDashboardPath(next), http.StatusSeeOther)
+}
+
+// dashboardCookie creates an HTTP only cookie scoped to the dashboard, secure when served over https
+func dashboardCookie(name, value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/dashboard",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(dashboardBaseURL(), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// currentDashboardSession returns the signed in user, nil when there is none or the session expired
+func currentDashboardSession(r *http.Request) *dashboardSession {
+	cookie, err := r.Cookie(DASHBOARD_SESSION_COOKIE)
+	if err != nil {
+		return nil
+	}
+	var session dashboardSession
+	if err := verifyDashboardValue("session", cookie.Value, &session); err != nil {
+		return nil
+	}
+	if time.Now().Unix() > session.Expires {
+		return nil
+	}
+	return &session
+}
+
+// safeDashboardPath only lets redirects go to dashboard pages, never to other hosts
+func safeDashboardPath(next string) string {
+	if !strings.HasPrefix(next, "/dashboard") || strings.HasPrefix(next, "//") || strings.Contains(next, "\\") {
+		return "/dashboard"
+	}
+	return next
+}
+
+// requireDashboardSession sends visitors who are not signed in to the login page
+func requireDashboardSession(next func(http.ResponseWriter, *http.Request, *dashboardSession)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := currentDashboardSession(r)
+		if session == nil {
+			http.Redirect(w, r, "/dashboard/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			return
+		}
+		next(w, r, session)
+	})
+}
+
+// handleDashboardLogin shows the sign-in options
+func handleDashboardLogin(w http.ResponseWriter, r *http.Request) {
+	if currentDashboardSession(r) != nil {
+		http.Redirect(w, r, safeDashboardPath(r.URL.Query().Get("next")), http.StatusSeeOther)
+		return
+	}
+	renderDashboardLogin(w, r, http.StatusOK, "")
+}
+
+// handleDashboardLogout ends the session
+func handleDashboardLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, dashboardCookie(DASHBOARD_SESSION_COOKIE, "", time.Unix(0, 0)))
+	http.Redirect(w, r, "/dashboard/login", http.StatusSeeOther)
+}
+
+// handleDashboardMagicLink signs in with a link sent by the bot
+func handleDashboardMagicLink(w http.ResponseWriter, r *http.Request) {
+	var link dashboardSession
+	if err := verifyDashboardValue("magic", r.URL.Query().Get("token"), &link); err != nil {
+		renderDashboardLogin(w, r, http.StatusUnauthorized, "This sign-in link is invalid. Ask for a new one with /oye dashboard in Slack.")
+		return
+	}
+	if time.Now().Unix() > link.Expires {
+		renderDashboardLogin(w, r, http.StatusUnauthorized, "This sign-in link has expired. Ask for a new one with /oye dashboard in Slack.")
+		return
+	}
+	startDashboardSession(w, r, link.UserID, link.Name, "/dashboard")
+}
+
+// handleDashboardSlackSignIn sends the browser to Slack to sign in
+func handleDashboardSlackSignIn(w http.ResponseWriter, r *http.Request) {
+	if !slackSignInEnabled() {
+		renderDashboardLogin(w, r, http.StatusNotFound, "Sign in with Slack is not configured.")
+		return
+	}
+
+	// The state is kept in a cookie and must come back unchanged, together with where to go afterwards
+	state := newWebhookID(16)
+	next := safeDashboardPath(r.URL.Query().Get("next"))
+	http.SetCookie(w, dashboardCookie(DASHBOARD_STATE_COOKIE, state+":"+next, time.Now().Add(10*time.Minute)))
+
+	query := url.Values{
+		"response_type": {"code"},
+		"scope":         {"openid profile"},
+		"client_id":     {os.Getenv("SLACK_CLIENT_ID")},
+		"redirect_uri":  {dashboardBaseURL() + "/dashboard/auth/callback"},
+		"state":         {state},
+	}
+	if teamID := os.Getenv("SLACK_TEAM_ID"); teamID != "" {
+		query.Set("team", teamID)
+	}
+	authorizeURL := slackOIDCEndpoint("SLACK_OIDC_AUTHORIZE_URL", defaultSlackOIDCAuthorizeURL, "/authorize")
+	http.Redirect(w, r, authorizeURL+"?"+query.Encode(), http.StatusFound)
+}
+
+// handleDashboardSlackCallback completes "Sign in with Slack": the code is exchanged for
+// an access token, which tells who signed in through the userInfo endpoint
+func handleDashboardSlackCallback(w http.ResponseWriter, r *http.Request) {
+	logger := GetGlobalLogger()
+
+	cookie, err := r.Cookie(DASHBOARD_STATE_COOKIE)
+	http.SetCookie(w, dashboardCookie(DASHBOARD_STATE_COOKIE, "", time.Unix(0, 0)))
+	if err != nil {
+		renderDashboardLogin(w, r, http.StatusBadRequest, "Your sign-in took too long, please try again.")
+		return
+	}
+	state, next, _ := strings.Cut(cookie.Value, ":")
+	if subtle.ConstantTimeCompare([]byte(state), []byte(r.URL.Query().Get("state"))) != 1 {
+		renderDashboardLogin(w, r, http.StatusBadRequest, "Your sign-in could not be verified, please try again.")
+		return
+	}
+	if errorCode := r.URL.Query().Get("error"); errorCode != "" {
+		renderDashboardLogin(w, r, http.StatusUnauthorized, "Slack did not sign you in: "+errorCode)
+		return
+	}
+
+	identity, err := fetchSlackIdentity(r.URL.Query().Get("code"))
+	if err != nil {
+		logger.Errorf("Sign in with Slack failed: %v", err)
+		renderDashboardLogin(w, r, http.StatusBadGateway, "Slack could not confirm who you are, please try again.")
+		return
+	}
+	if teamID := os.Getenv("SLACK_TEAM_ID"); teamID != "" && identity.TeamID != teamID {
+		logger.Warnf("Refused dashboard sign-in of %s from workspace %s", identity.UserID, identity.TeamID)
+		renderDashboardLogin(w, r, http.StatusForbidden, "Please sign in with your account in our Slack workspace.")
+		return
+	}
+	startDashboardSession(w, r, identity.UserID, identity.Name, next)
+}
+
+// slackIdentity is who signed in, as told by Slack's userInfo endpoint
+type slackIdentity struct {
+	OK     bool   `json:"ok"`
+	Error  string `json:"error"`
+	UserID string `json:"https://slack.com/user_id"`
+	TeamID string `json:"https://slack.com/team_id"`
+	Name   string `json:"name"`
+}
+
+// fetchSlackIdentity exchanges an authorization code for the identity of the user who signed in
+func fetchSlackIdentity(code string) (*slackIdentity, error) {
+	if code == "" {
+		return nil, fmt.Errorf("no authorization code")
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	response, err := client.PostForm(slackOIDCEndpoint("SLACK_OIDC_TOKEN_URL", defaultSlackOIDCTokenURL, "/token"), url.Values{
+		"client_id":     {os.Getenv("SLACK_CLIENT_ID")},
+		"client_secret": {os.Getenv("SLACK_CLIENT_SECRET")},
+		"code":          {code},
+		"redirect_uri":  {dashboardBaseURL() + "/dashboard/auth/callback"},
+		"grant_type":    {"authorization_code"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer CloseWithErrorLog(response.Body, "Slack token response body")
+	var token struct {
+		OK          bool   `json:"ok"`
+		Error       string `json:"error"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if !token.OK || token.AccessToken == "" {
+		return nil, fmt.Errorf("token request refused: %s", token.Error)
+	}
+
+	request, err := http.NewRequest("GET", slackOIDCEndpoint("SLACK_OIDC_USERINFO_URL", defaultSlackOIDCUserInfoURL, "/userinfo"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create userInfo request: %w", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	userInfo, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("userInfo request failed: %w", err)
+	}
+	defer CloseWithErrorLog(userInfo.Body, "Slack userInfo response body")
+	var identity slackIdentity
+	if err := json.NewDecoder(userInfo.Body).Decode(&identity); err != nil {
+		return nil, fmt.Errorf("failed to decode userInfo response: %w", err)
+	}
+	if !identity.OK || identity.UserID == "" {
+		return nil, fmt.Errorf("userInfo request refused: %s", identity.Error)
+	}
+	return &identity, nil
+}
+
+// isDashboardCommand checks for "/oye dashboard"
+func isDashboardCommand(text string) bool {
+	return strings.EqualFold(strings.TrimSpace(text), "dashboard")
+}
+
+// handleDashboardCommand sends the user a magic sign-in link by direct message
+func handleDashboardCommand(w http.ResponseWriter, req *SlackCommandRequest) {
+	if !dashboardEnabled() || dashboardBaseURL() == "" {
+		sendImmediateResponse(w, "The web dashboard is not set up on this server.", "ephemeral")
+		return
+	}
+
+	link, err := newMagicLink(req.UserID, req.UserName)
+	if err != nil {
+		GetGlobalLogger().Errorf("Failed to create dashboard link for %s: %v", req.UserID, err)
+		sendImmediateResponse(w, "Sorry, the sign-in link couldn't be created.", "ephemeral")
+		return
+	}
+
+	button := NewButton("dashboard_sign_in", "Open dashboard", "sign_in")
+	button.URL = link
+	button.Style = "primary"
+	blocks := []Block{
+		SectionBlock(fmt.Sprintf("%s Here's your sign-in link for the OYE dashboard. It works for %d minutes.", EMOJI_CHART, int(DASHBOARD_MAGIC_LINK_TTL.Minutes()))),
+		ActionsBlock(button),
+	}
+	if err := sendSlackMessage(req.UserID, blocks, ""); err != nil {
+		GetGlobalLogger().Errorf("Failed to send dashboard link to %s: %v", req.UserID, err)
+		sendImmediateResponse(w, "Sorry, the sign-in link couldn't be sent.", "ephemeral")
+		return
+	}
+	sendImmediateResponse(w, "📬 I've sent you a sign-in link by direct message.", "ephemeral")
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// A fake "Sign in with Slack" provider for development, enabled with DASHBOARD_FAKE_SLACK_SIGN_IN=true.
+// It serves the authorize, token and userInfo endpoints under /dashboard/dev/oidc, lets you pick
+// the user and workspace that sign in, and goes through the same state cookie, workspace check
+// and session as the real provider. Never enable it in production: anyone can sign in as anyone.
+
+const FAKE_OIDC_PATH = "/dashboard/dev/oidc"
+
+// fakeOIDCIdentity is who the fake provider signs in, carried in its codes and access tokens
+type fakeOIDCIdentity struct {
+	UserID  string `json:"u"`
+	TeamID  string `json:"t"`
+	Name    string `json:"n"`
+	Expires int64  `json:"e"`
+}
+
+// fakeSlackSignInEnabled reports whether the fake provider replaces Slack's
+func fakeSlackSignInEnabled() bool {
+	return os.Getenv("DASHBOARD_FAKE_SLACK_SIGN_IN") == "true"
+}
+
+// slackOIDCEndpoint returns a Sign in with Slack endpoint: the one set in envVar,
+// the fake provider's when it's enabled, or Slack's
+func slackOIDCEndpoint(envVar, defaultURL, fakePath string) string {
+	if value := os.Getenv(envVar); value != "" {
+		return value
+	}
+	if fakeSlackSignInEnabled() {
+		return dashboardBaseURL() + FAKE_OIDC_PATH + fakePath
+	}
+	return defaultURL
+}
+
+// registerFakeOIDCRoutes adds the fake provider's endpoints to the dashboard
+func registerFakeOIDCRoutes(mux *http.ServeMux) {
+	GetGlobalLogger().Warn("DASHBOARD_FAKE_SLACK_SIGN_IN is on: anyone can sign in to the dashboard as any user, never use it in production")
+	mux.HandleFunc("GET "+FAKE_OIDC_PATH+"/authorize", handleFakeOIDCAuthorizeForm)
+	mux.HandleFunc("POST "+FAKE_OIDC_PATH+"/authorize", handleFakeOIDCAuthorize)
+	mux.HandleFunc("POST "+FAKE_OIDC_PATH+"/token", handleFakeOIDCToken)
+	mux.HandleFunc("GET "+FAKE_OIDC_PATH+"/userinfo", handleFakeOIDCUserInfo)
+}
+
+// handleFakeOIDCAuthorizeForm asks who to sign in as, in place of Slack's consent screen
+func handleFakeOIDCAuthorizeForm(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	renderWebPage(w, http.StatusOK, "fake_oidc", webPage{Title: "Fake Sign in with Slack", Data: struct {
+		State, RedirectURI, UserID, TeamID string
+	}{query.Get("state"), query.Get("redirect_uri"), os.Getenv("DASHBOARD_FAKE_SLACK_USER_ID"), query.Get("team")}})
+}
+
+// handleFakeOIDCAuthorize sends the browser back to the callback with a code, or with
+// access_denied, and the state it was given
+func handleFakeOIDCAuthorize(w http.ResponseWriter, r *http.Request) {
+	redirectURI := r.PostFormValue("redirect_uri")
+	if redirectURI != dashboardBaseURL()+"/dashboard/auth/callback" {
+		http.Error(w, "redirect_uri does not match DASHBOARD_BASE_URL", http.StatusBadRequest)
+		return
+	}
+
+	callback := url.Values{"state": {r.PostFormValue("state")}}
+	if r.PostFormValue("deny") != "" {
+		callback.Set("error", "access_denied")
+		http.Redirect(w, r, redirectURI+"?"+callback.Encode(), http.StatusSeeOther)
+		return
+	}
+
+	identity := fakeOIDCIdentity{
+		UserID:  strings.TrimSpace(r.PostFormValue("user_id")),
+		TeamID:  strings.TrimSpace(r.PostFormValue("team_id")),
+		Name:    strings.TrimSpace(r.PostFormValue("name")),
+		Expires: time.Now().Add(time.Minute).Unix(),
+	}
+	code, err := signDashboardValue("fake-oidc-code", identity)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	callback.Set("code", code)
+	http.Redirect(w, r, redirectURI+"?"+callback.Encode(), http.StatusSeeOther)
+}
+
+// handleFakeOIDCToken exchanges a code for an access token, answering like openid.connect.token
+func handleFakeOIDCToken(w http.ResponseWriter, r *http.Request) {
+	var identity fakeOIDCIdentity
+	if err := verifyDashboardValue("fake-oidc-code", r.PostFormValue("code"), &identity); err != nil ||
+		time.Now().Unix() > identity.Expires {
+		writeFakeOIDCResponse(w, map[string]interface{}{"ok": false, "error": "invalid_code"})
+		return
+	}
+
+	identity.Expires = time.Now().Add(time.Hour).Unix()
+	token, err := signDashboardValue("fake-oidc-token", identity)
+	if err != nil {
+		writeFakeOIDCResponse(w, map[string]interface{}{"ok": false, "error": "internal_error"})
+		return
+	}
+	writeFakeOIDCResponse(w, map[string]interface{}{"ok": true, "access_token": token, "token_type": "Bearer"})
+}
+
+// handleFakeOIDCUserInfo tells who an access token belongs to, answering like openid.connect.userInfo
+func handleFakeOIDCUserInfo(w http.ResponseWriter, r *http.Request) {
+	var identity fakeOIDCIdentity
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if err := verifyDashboardValue("fake-oidc-token", token, &identity); err != nil ||
+		time.Now().Unix() > identity.Expires {
+		writeFakeOIDCResponse(w, slackIdentity{Error: "invalid_auth"})
+		return
+	}
+	writeFakeOIDCResponse(w, slackIdentity{OK: true, UserID: identity.UserID, TeamID: identity.TeamID, Name: identity.Name})
+}
+
+func writeFakeOIDCResponse(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		GetGlobalLogger().Errorf("Failed to write fake OIDC response: %v", err)
+	}
+}
+
+const webFakeOIDCTemplate = `<div class="card">
+  <h1>Fake Sign in with Slack</h1>
+  <p class="muted">DASHBOARD_FAKE_SLACK_SIGN_IN is on. Pick who Slack would say signed in.</p>
+  <form method="post" action="` + FAKE_OIDC_PATH + `/authorize">
+    <input type="hidden" name="state" value="{{.Data.State}}">
+    <input type="hidden" name="redirect_uri" value="{{.Data.RedirectURI}}">
+    <p><label>Slack user ID <input name="user_id" value="{{.Data.UserID}}" required></label></p>
+    <p><label>Workspace ID <input name="team_id" value="{{.Data.TeamID}}"></label></p>
+    <p><label>Name <input name="name" value="Dev User"></label></p>
+    <p><button class="button" type="submit">Sign in</button> <button type="submit" name="deny" value="1">Deny</button></p>
+  </form>
+</div>`
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+)
+
+// webTemplateFuncs are the helpers available to the dashboard templates
+var webTemplateFuncs = template.FuncMap{
+	"duration": formatDuration,
+	"durationOf": func(seconds *int) string {
+		if seconds == nil {
+			return "—"
+		}
+		return formatDuration(*seconds)
+	},
+	"percent": func(percentage *float64) string {
+		if percentage == nil {
+			return "—"
+		}
+		return fmt.Sprintf("%.1f%%", *percentage)
+	},
+	"datetime": func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+	"deref": func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	},
+}
+
+// webTemplates are the dashboard pages, each rendered inside webLayoutTemplate
+var webTemplates = map[string]*template.Template{
+	"login":     newWebTemplate(webLoginTemplate),
+	"error":     newWebTemplate(webErrorTemplate),
+	"projects":  newWebTemplate(webProjectsTemplate),
+	"project":   newWebTemplate(webProjectTemplate),
+	"task":      newWebTemplate(webTaskTemplate),
+	"alerts":    newWebTemplate(webAlertsTemplate),
+	"fake_oidc": newWebTemplate(webFakeOIDCTemplate),
+}
+
+func newWebTemplate(content string) *template.Template {
+	layout := template.Must(template.New("layout").Funcs(webTemplateFuncs).Parse(webLayoutTemplate))
+	return template.Must(layout.New("content").Parse(content))
+}
+
+// webLayoutTemplate is the page frame. Styles are inline, the dashboard loads nothing from elsewhere.
+const webLayoutTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} · OYE</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Roboto, Helvetica, Arial, sans-serif; margin: 0; color: #1d1c1d; background: #f8f8f8; }
+  header { background: #3f0e40; color: #fff; padding: 0.75rem 1.5rem; display: flex; align-items: center; gap: 1.5rem; }
+  header a { color: #fff; text-decoration: none; font-weight: 600; }
+  header .user { margin-left: auto; display: flex; align-items: center; gap: 0.75rem; }
+  header button { background: none; border: 1px solid #fff; color: #fff; border-radius: 4px; padding: 0.2rem 0.6rem; cursor: pointer; }
+  main { max-width: 1100px; margin: 1.5rem auto; padding: 0 1.5rem; }
+  table { width: 100%; border-collapse: collapse; background: #fff; }
+  th, td { text-align: left; padding: 0.5rem 0.75rem; border-bottom: 1px solid #e8e8e8; }
+  th a { color: inherit; }
+  td.number, th.number { text-align: right; white-space: nowrap; }
+  a { color: #1264a3; }
+  .muted { color: #616061; font-size: 0.9rem; }
+  .error { background: #fde8e8; border: 1px solid #e01e5a; padding: 0.75rem 1rem; border-radius: 4px; }
+  .card { background: #fff; border: 1px solid #e8e8e8; border-radius: 6px; padding: 1rem 1.25rem; margin-bottom: 1.25rem; }
+  .stats { display: flex; flex-wrap: wrap; gap: 2rem; }
+  .stats div strong { display: block; font-size: 1.4rem; }
+  form.period { margin-bottom: 1rem; display: flex; gap: 0.5rem; align-items: center; }
+  .button { display: inline-block; background: #4a154b; color: #fff; padding: 0.6rem 1.2rem; border-radius: 4px; text-decoration: none; font-weight: 600; }
+  .pager { margin-top: 1rem; display: flex; gap: 1rem; }
+  svg text { font-size: 10px; fill: #616061; }
+  svg rect.bar { fill: #2eb67d; }
+  svg line { stroke: #e8e8e8; }
+</style>
+</head>
+<body>
+<header>
+  <a href="/dashboard">📊 OYE</a>
+  {{if .Session}}
+  <a href="/dashboard">Projects</a>
+  <a href="/dashboard/alerts">Alerts</a>
+  <div class="user">
+    <span>{{.Session.Name}}</span>
+    <form method="post" action="/dashboard/logout"><button type="submit">Sign out</button></form>
+  </div>
+  {{end}}
+</header>
+<main>
+{{template "content" .}}
+</main>
+</body>
+</html>`
+
+const webLoginTemplate = `<div class="card">
+  <h1>Sign in to OYE</h1>
+  {{with .Data.Error}}<p class="error">{{.}}</p>{{end}}
+  {{if .Data.SlackSignIn}}
+  <p><a class="button" href="/dashboard/auth/slack?next={{.Data.Next}}">Sign in with Slack</a></p>
+  <p class="muted">Or type <code>/oye dashboard</code> in Slack and the bot sends you a sign-in link.</p>
+  {{else}}
+  <p>Type <code>/oye dashboard</code> in Slack and the bot sends you a sign-in link.</p>
+  {{end}}
+</div>`
+
+const webErrorTemplate = `<div class="card">
+  <h1>{{.Title}}</h1>
+  <p class="error">{{.Data}}</p>
+  <p><a href="/dashboard">Back to the projects</a></p>
+</div>`
+
+const webProjectsTemplate = `<h1>Projects</h1>
+<p class="muted">Budget status of the tasks with time tracked this month, judged by the task furthest into its estimate.</p>
+<table>
+  <thead>
+    <tr><th>Status</th><th>Project</th><th class="number">This week</th><th class="number">This month</th><th class="number">Over budget</th><th>Furthest task</th></tr>
+  </thead>
+  <tbody>
+  {{range .Data}}
+    <tr>
+      <td title="{{.Status.Label}}">{{.Status.Emoji}} {{.Status.Label}}</td>
+      <td><a href="/dashboard/projects/{{.ID}}">{{.Name}}</a></td>
+      <td class="number">{{duration .WeekSeconds}}</td>
+      <td class="number">{{duration .MonthSeconds}}</td>
+      <td class="number">{{.OverBudget}}</td>
+      <td>{{if .WorstPercent}}{{.WorstTask}} <span class="muted">({{percent .WorstPercent}})</span>{{else}}<span class="muted">—</span>{{end}}</td>
+    </tr>
+  {{else}}
+    <tr><td colspan="6" class="muted">No projects yet.</td></tr>
+  {{end}}
+  </tbody>
+</table>`
+
+const webProjectTemplate = `{{with .Data}}
+<h1>{{.Project.Name}}</h1>
+<form class="period" method="get" action="/dashboard/projects/{{.Project.ID}}">
+  <label>From <input type="date" name="from" value="{{.Period.FromValue}}"></label>
+  <label>to <input type="date" name="to" value="{{.Period.ToValue}}"></label>
+  <input type="hidden" name="sort" value="{{.Sort}}">
+  <input type="hidden" name="order" value="{{if .Desc}}desc{{else}}asc{{end}}">
+  <button type="submit">Show</button>
+</form>
+<table>
+  <thead>
+    <tr>
+      <th>Status</th>
+      <th><a href="{{.SortLink "name"}}">Task{{.SortMark "name"}}</a></th>
+      <th>Estimate</th>
+      <th class="number"><a href="{{.SortLink "period"}}">In period{{.SortMark "period"}}</a></th>
+      <th class="number"><a href="{{.SortLink "total"}}">Total{{.SortMark "total"}}</a></th>
+      <th class="number"><a href="{{.SortLink "percent"}}">Usage{{.SortMark "percent"}}</a></th>
+    </tr>
+  </thead>
+  <tbody>
+  {{$period := .Period}}
+  {{range .Tasks}}
+    <tr>
+      <td title="{{.Status.Label}}">{{.Status.Emoji}}</td>
+      <td><a href="/dashboard/tasks/{{.ID}}">{{.Name}}</a></td>
+      <td>{{with .Estimate}}{{.OptimisticHours}}–{{.PessimisticHours}}h{{else}}<span class="muted">none</span>{{end}}</td>
+      <td class="number">{{durationOf .PeriodSeconds}}</td>
+      <td class="number">{{duration .TotalSeconds}}</td>
+      <td class="number">{{percent .UsagePercent}}</td>
+    </tr>
+  {{else}}
+    <tr><td colspan="6" class="muted">No time tracked on this project from {{$period.FromValue}} to {{$period.ToValue}}.</td></tr>
+  {{end}}
+  </tbody>
+</table>
+{{end}}`
+
+const webTaskTemplate = `{{with .Data}}
+<h1>{{.Task.Name}}</h1>
+<p class="muted">{{if .Project}}<a href="/dashboard/projects/{{.Project.ID}}">{{.Project.Name}}</a> · {{end}}TimeCamp task {{.Task.ID}}{{if .Task.Archived}} · archived{{end}}</p>
+<div class="card stats">
+  <div><span class="muted">Status</span><strong>{{.Status.Emoji}} {{.Status.Label}}</strong></div>
+  <div><span class="muted">Estimate</span><strong>{{with .Task.Estimate}}{{.OptimisticHours}}–{{.PessimisticHours}}h{{else}}—{{end}}</strong></div>
+  <div><span class="muted">Total time</span><strong>{{duration .Task.TotalSeconds}}</strong></div>
+  <div><span class="muted">Usage</span><strong>{{percent .Task.UsagePercent}}</strong></div>
+  <div><span class="muted">In period</span><strong>{{duration .PeriodSeconds}}</strong></div>
+</div>
+{{with .Task.EstimateError}}<p class="muted">No usable estimate: {{.}}</p>{{end}}
+
+<div class="card">
+  <form class="period" method="get" action="/dashboard/tasks/{{.Task.ID}}">
+    <label>From <input type="date" name="from" value="{{.Period.FromValue}}"></label>
+    <label>to <input type="date" name="to" value="{{.Period.ToValue}}"></label>
+    <button type="submit">Show</button>
+  </form>
+  {{with .Chart}}
+  <svg viewBox="0 0 {{.Width}} {{.Height}}" width="100%" role="img" aria-label="Time tracked per day">
+    {{$chart := .}}
+    {{range .Gridlines}}
+    <line x1="{{$chart.Left}}" y1="{{.Y}}" x2="{{$chart.Width}}" y2="{{.Y}}"></line>
+    <text x="{{$chart.Left}}" y="{{.Y}}" dx="-4" dy="3" text-anchor="end">{{.Label}}</text>
+    {{end}}
+    {{range $bar := .Bars}}
+    <rect class="bar" x="{{$bar.X}}" y="{{$bar.Y}}" width="{{$bar.Width}}" height="{{$bar.Height}}"><title>{{$bar.Title}}</title></rect>
+    {{with $bar.Label}}<text x="{{$bar.X}}" y="{{$chart.Bottom}}" dy="14">{{.}}</text>{{end}}
+    {{end}}
+  </svg>
+  {{end}}
+</div>
+
+<h2>History</h2>
+<table>
+  <thead><tr><th>When</th><th>Change</th><th>Before</th><th>After</th></tr></thead>
+  <tbody>
+  {{range .Task.History}}
+    <tr><td>{{datetime .ChangedAt}}</td><td>{{.ChangeType}}</td><td>{{deref .PreviousValue}}</td><td>{{deref .CurrentValue}}</td></tr>
+  {{else}}
+    <tr><td colspan="4" class="muted">No recorded changes.</td></tr>
+  {{end}}
+  </tbody>
+</table>
+{{end}}`
+
+const webAlertsTemplate = `{{with .Data}}
+<h1>Alerts</h1>
+<p>
+  <a href="/dashboard/alerts">All</a>
+  {{range .Types}} · <a href="/dashboard/alerts?type={{.}}">{{.}}</a>{{end}}
+  {{with .Type}}<span class="muted">— showing {{.}} alerts</span>{{end}}
+</p>
+<table>
+  <thead><tr><th>Sent</th><th>Type</th><th>Task</th><th class="number">Threshold</th><th class="number">Value</th></tr></thead>
+  <tbody>
+  {{range .Alerts}}
+    <tr>
+      <td>{{datetime .NotifiedAt}}</td>
+      <td>{{.Type}}</td>
+      <td><a href="/dashboard/tasks/{{.TaskID}}">{{.TaskName}}</a></td>
+      {{if eq .Type "unestimated"}}
+      <td class="number">{{.Threshold}}h</td><td class="number">{{printf "%.1f" .Value}}h</td>
+      {{else}}
+      <td class="number">{{.Threshold}}%</td><td class="number">{{printf "%.1f" .Value}}%</td>
+      {{end}}
+    </tr>
+  {{else}}
+    <tr><td colspan="5" class="muted">No alerts sent yet.</td></tr>
+  {{end}}
+  </tbody>
+</table>
+<div class="pager">
+  {{with .Previous}}<a href="{{.}}">← Newer</a>{{end}}
+  <span class="muted">{{.Pagination.Total}} alerts</span>
+  {{with .Next}}<a href="{{.}}">Older →</a>{{end}}
+</div>
+{{end}}`
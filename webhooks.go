@@ -0,0 +1,439 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook event types
+const (
+	WEBHOOK_EVENT_ALL                 = "*"
+	WEBHOOK_EVENT_THRESHOLD_CROSSED   = "threshold.crossed"
+	WEBHOOK_EVENT_THRESHOLD_ESCALATED = "threshold.escalated"
+	WEBHOOK_EVENT_TASK_NEEDS_ESTIMATE = "task.needs_estimate"
+	WEBHOOK_EVENT_SYNC_FAILED         = "sync.failed"
+	WEBHOOK_EVENT_DAILY_SUMMARY       = "daily_summary.sent"
+)
+
+var webhookEventTypes = []string{
+	WEBHOOK_EVENT_THRESHOLD_CROSSED,
+	WEBHOOK_EVENT_THRESHOLD_ESCALATED,
+	WEBHOOK_EVENT_TASK_NEEDS_ESTIMATE,
+	WEBHOOK_EVENT_SYNC_FAILED,
+	WEBHOOK_EVENT_DAILY_SUMMARY,
+}
+
+// WebhookSubscription is an outbound webhook target
+type WebhookSubscription struct {
+	ID         int
+	URL        string
+	EventTypes []string
+	Secret     string
+	Active     bool
+	CreatedAt  time.Time
+}
+
+// WebhookEvent is the JSON envelope posted to subscribers
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDelivery is a single logged delivery attempt
+type WebhookDelivery struct {
+	ID             int
+	SubscriptionID int
+	EventID        string
+	EventType      string
+	StatusCode     int
+	Success        bool
+	Error          string
+	DurationMS     int
+	DeliveredAt    time.Time
+}
+
+// webhookAlertData is the payload of threshold related events
+type webhookAlertData struct {
+	TaskID      int     `json:"task_id"`
+	TaskName    string  `json:"task_name"`
+	Project     string  `json:"project,omitempty"`
+	Threshold   int     `json:"threshold,omitempty"`
+	Percentage  float64 `json:"percentage,omitempty"`
+	HoursLogged int     `json:"hours_logged,omitempty"`
+	TotalTime   string  `json:"total_time"`
+	Estimation  string  `json:"estimation,omitempty"`
+}
+
+// subscribesTo reports whether the subscription wants the given event type
+func (s WebhookSubscription) subscribesTo(eventType string) bool {
+	for _, subscribed := range s.EventTypes {
+		if subscribed == WEBHOOK_EVENT_ALL || subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookRetryConfig returns the retry configuration for webhook deliveries
+func webhookRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries:  getEnvInt("WEBHOOK_MAX_RETRIES", 3),
+		InitialWait: time.Duration(getEnvInt("WEBHOOK_INITIAL_WAIT_MS", 1000)) * time.Millisecond,
+		MaxWait:     time.Duration(getEnvInt("WEBHOOK_MAX_WAIT_MS", 30000)) * time.Millisecond,
+		Multiplier:  getEnvFloat("WEBHOOK_RETRY_MULTIPLIER", 2.0),
+		Target:      "webhook",
+	}
+}
+
+// newWebhookID returns a random hex identifier
+func newWebhookID(byteLength int) string {
+	buf := make([]byte, byteLength)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// DispatchWebhookEvent delivers an event to all matching subscriptions in the background
+func DispatchWebhookEvent(eventType string, data interface{}) {
+	go PublishWebhookEvent(eventType, data)
+}
+
+// PublishWebhookEvent delivers an event to all matching subscriptions and waits for completion
+func PublishWebhookEvent(eventType string, data interface{}) {
+	logger := GetGlobalLogger()
+
+	event := WebhookEvent{
+		ID:        newWebhookID(16),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+
+	db, err := GetDB()
+	if err != nil {
+		logger.Errorf("Failed to get database connection for webhook dispatch: %v", err)
+		return
+	}
+
+	subscriptions, err := GetWebhookSubscriptions(db, true)
+	if err != nil {
+		logger.Errorf("Failed to load webhook subscriptions: %v", err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.subscribesTo(eventType) {
+			continue
+		}
+		if err := deliverWebhook(db, subscription, event); err != nil {
+			logger.Warnf("Webhook delivery of %s to subscription %d failed: %v", eventType, subscription.ID, err)
+		}
+	}
+}
+
+// deliverWebhook posts a signed event to a subscription and records the outcome
+func deliverWebhook(db *sql.DB, subscription WebhookSubscription, event WebhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	request, err := http.NewRequest("POST", subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		recordWebhookDelivery(db, subscription.ID, event, body, 0, err, 0)
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "observe-yor-estimates-webhooks")
+	request.Header.Set("X-OYE-Event", event.Type)
+	request.Header.Set("X-OYE-Delivery", event.ID)
+	request.Header.Set("X-OYE-Request-Timestamp", timestamp)
+	request.Header.Set("X-OYE-Signature", computeRequestSignature(subscription.Secret, timestamp, body))
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	started := time.Now()
+	response, err := DoHTTPWithRetry(client, request, webhookRetryConfig())
+	duration := time.Since(started)
+	if err != nil {
+		recordWebhookDelivery(db, subscription.ID, event, body, 0, err, duration)
+		return err
+	}
+	defer CloseWithErrorLog(response.Body, "webhook response body")
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		err = fmt.Errorf("subscriber returned status %d", response.StatusCode)
+	}
+	recordWebhookDelivery(db, subscription.ID, event, body, response.StatusCode, err, duration)
+	return err
+}
+
+// recordWebhookDelivery writes a delivery log entry
+func recordWebhookDelivery(db *sql.DB, subscriptionID int, event WebhookEvent, payload []byte, statusCode int, deliveryErr error, duration time.Duration) {
+	var errorText sql.NullString
+	if deliveryErr != nil {
+		errorText = sql.NullString{String: deliveryErr.Error(), Valid: true}
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status_code, success, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := db.Exec(query, subscriptionID, event.ID, event.Type, string(payload), statusCode,
+		deliveryErr == nil, errorText, duration.Milliseconds())
+	if err != nil {
+		GetGlobalLogger().Errorf("Failed to record webhook delivery for subscription %d: %v", subscriptionID, err)
+	}
+}
+
+// GetWebhookSubscriptions returns webhook subscriptions, optionally only active ones
+func GetWebhookSubscriptions(db *sql.DB, activeOnly bool) ([]WebhookSubscription, error) {
+	query := `SELECT id, url, event_types, secret, active, created_at FROM webhook_subscriptions`
+	if activeOnly {
+		query += ` WHERE active = true`
+	}
+	query += ` ORDER BY id`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []WebhookSubscription
+	for rows.Next() {
+		var subscription WebhookSubscription
+		var eventTypes string
+		if err := rows.Scan(&subscription.ID, &subscription.URL, &eventTypes, &subscription.Secret,
+			&subscription.Active, &subscription.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subscription.EventTypes = strings.Split(eventTypes, ",")
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// CreateWebhookSubscription stores a new subscription, generating a secret if none is given
+func CreateWebhookSubscription(db *sql.DB, url string, eventTypes []string, secret string) (*WebhookSubscription, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("webhook URL must start with http:// or https://")
+	}
+
+	for _, eventType := range eventTypes {
+		if eventType != WEBHOOK_EVENT_ALL && !containsString(webhookEventTypes, eventType) {
+			return nil, fmt.Errorf("unknown event type '%s' (available: %s, or * for all)", eventType, strings.Join(webhookEventTypes, ", "))
+		}
+	}
+
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("at least one event type is required")
+	}
+
+	if secret == "" {
+		secret = newWebhookID(32)
+	}
+
+	subscription := WebhookSubscription{URL: url, EventTypes: eventTypes, Secret: secret, Active: true}
+	query := `
+		INSERT INTO webhook_subscriptions (url, event_types, secret)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	err := db.QueryRow(query, url, strings.Join(eventTypes, ","), secret).Scan(&subscription.ID, &subscription.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return &subscription, nil
+}
+
+// DeleteWebhookSubscription removes a subscription and its delivery log
+func DeleteWebhookSubscription(db *sql.DB, id int) error {
+	result, err := db.Exec(`DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook subscription %d not found", id)
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries returns the most recent deliveries, optionally only failed ones
+func GetWebhookDeliveries(db *sql.DB, failedOnly bool, limit int) ([]WebhookDelivery, error) {
+	query := `
+		SELECT id, subscription_id, event_id, event_type, COALESCE(status_code, 0), success,
+		       COALESCE(error, ''), COALESCE(duration_ms, 0), delivered_at
+		FROM webhook_deliveries`
+	if failedOnly {
+		query += ` WHERE success = false`
+	}
+	query += ` ORDER BY delivered_at DESC LIMIT $1`
+
+	rows, err := db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		var delivery WebhookDelivery
+		if err := rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType,
+			&delivery.StatusCode, &delivery.Success, &delivery.Error, &delivery.DurationMS, &delivery.DeliveredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// dispatchAlertWebhooks publishes webhook events for detected threshold alerts
+func dispatchAlertWebhooks(alerts []ThresholdAlert) {
+	db, err := GetDB()
+	if err != nil {
+		return
+	}
+
+	allTasks, err := getAllTasks(db)
+	if err != nil {
+		allTasks = map[int]Task{}
+	}
+
+	for _, alert := range alerts {
+		data := webhookAlertData{
+			TaskID:     alert.TaskID,
+			TaskName:   alert.Name,
+			Project:    getProjectNameForTask(alert.TaskID, allTasks),
+			Percentage: alert.Percentage,
+			TotalTime:  alert.TotalDuration,
+			Estimation: alert.EstimationInfo.Text,
+		}
+
+		eventType := WEBHOOK_EVENT_THRESHOLD_CROSSED
+		switch {
+		case alert.Escalation != nil:
+			eventType = WEBHOOK_EVENT_THRESHOLD_ESCALATED
+			data.Threshold = alert.ThresholdCrossed
+		case alert.HoursStep > 0:
+			eventType = WEBHOOK_EVENT_TASK_NEEDS_ESTIMATE
+			data.HoursLogged = alert.HoursStep
+		default:
+			data.Threshold = alert.ThresholdCrossed
+		}
+
+		DispatchWebhookEvent(eventType, data)
+	}
+}
+
+// containsString reports whether a slice contains the given string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// handleWebhookCliCommand manages webhook subscriptions from the command line
+func handleWebhookCliCommand(args []string, logger *Logger) error {
+	db, err := GetDB()
+	if err != nil {
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+
+	subcommand := "list"
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+
+	switch subcommand {
+	case "list":
+		subscriptions, err := GetWebhookSubscriptions(db, false)
+		if err != nil {
+			return err
+		}
+		if len(subscriptions) == 0 {
+			fmt.Println("No webhook subscriptions")
+			return nil
+		}
+		for _, subscription := range subscriptions {
+			status := "active"
+			if !subscription.Active {
+				status = "inactive"
+			}
+			fmt.Printf("%d\t%s\t%s\t%s\n", subscription.ID, subscription.URL, strings.Join(subscription.EventTypes, ","), status)
+		}
+	case "add":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: webhooks add <url> <event,types|*> [secret]")
+		}
+		secret := ""
+		if len(args) > 3 {
+			secret = args[3]
+		}
+		subscription, err := CreateWebhookSubscription(db, args[1], strings.Split(args[2], ","), secret)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created webhook subscription %d\nSigning secret: %s\n", subscription.ID, subscription.Secret)
+	case "remove":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: webhooks remove <id>")
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid subscription id: %s", args[1])
+		}
+		if err := DeleteWebhookSubscription(db, id); err != nil {
+			return err
+		}
+		logger.Infof("Removed webhook subscription %d", id)
+	case "deliveries":
+		failedOnly := len(args) > 1 && args[1] == "--failed"
+		deliveries, err := GetWebhookDeliveries(db, failedOnly, 50)
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			fmt.Println("No webhook deliveries")
+			return nil
+		}
+		for _, delivery := range deliveries {
+			outcome := "ok"
+			if !delivery.Success {
+				outcome = "FAILED: " + delivery.Error
+			}
+			fmt.Printf("%s\tsub=%d\t%s\t%s\tstatus=%d\t%dms\t%s\n", delivery.DeliveredAt.Format("2006-01-02 15:04:05"),
+				delivery.SubscriptionID, delivery.EventType, delivery.EventID, delivery.StatusCode, delivery.DurationMS, outcome)
+		}
+	default:
+		return fmt.Errorf("unknown webhooks command '%s' (use list, add, remove or deliveries)", subcommand)
+	}
+
+	return nil
+}